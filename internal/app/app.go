@@ -1,6 +1,8 @@
 package app
 
 import (
+	"net/http"
+
 	"github.com/vlaship/book-catalog-go/internal/app/controller"
 	"github.com/vlaship/book-catalog-go/internal/app/facade"
 	"github.com/vlaship/book-catalog-go/internal/app/repository"
@@ -10,11 +12,16 @@ import (
 	"github.com/vlaship/book-catalog-go/internal/config"
 	"github.com/vlaship/book-catalog-go/internal/database"
 	"github.com/vlaship/book-catalog-go/internal/email"
+	"github.com/vlaship/book-catalog-go/internal/health"
 	"github.com/vlaship/book-catalog-go/internal/httphandling"
 	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/metrics"
+	"github.com/vlaship/book-catalog-go/internal/password"
+	"github.com/vlaship/book-catalog-go/internal/ratelimit"
 	"github.com/vlaship/book-catalog-go/internal/router"
 	"github.com/vlaship/book-catalog-go/internal/snowflake"
 	"github.com/vlaship/book-catalog-go/internal/template"
+	"github.com/vlaship/book-catalog-go/internal/totp"
 	"github.com/vlaship/book-catalog-go/internal/validation"
 
 	"github.com/go-chi/chi/v5"
@@ -22,8 +29,15 @@ import (
 
 // App struct holds the dependencies for the application.
 type App struct {
-	DB     database.ConnPool
-	Router *chi.Mux
+	DB         database.ConnPool
+	Cache      cache.Cache
+	Limiter    ratelimit.Limiter
+	Migrations *database.MigrationChecker
+	Health     *health.Checker
+	Router     *chi.Mux
+	Metrics    http.Handler
+	Trash      *service.TrashService
+	Outbox     *service.OutboxService
 }
 
 // NewApp creates a new instance of the App with provided configurations.
@@ -37,7 +51,10 @@ func NewApp(cfg *config.Config, log logger.Logger) (*App, error) {
 
 	// init authenticator
 	log.Trc().Msg("init authenticator")
-	authenticator := authentication.New(cfg)
+	authenticator, err := authentication.New(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	// init template
 	log.Trc().Msg("init templates")
@@ -58,9 +75,42 @@ func NewApp(cfg *config.Config, log logger.Logger) (*App, error) {
 	log.Trc().Msg("init email sender")
 	sender := email.New(cfg)
 
+	// init metrics
+	log.Trc().Msg("init metrics")
+	recorder := metrics.New()
+	if err = recorder.Register(metrics.NewPoolCollector(pool)); err != nil {
+		return nil, err
+	}
+
 	// init cache
 	log.Trc().Msg("init cache")
-	caches := cache.New()
+	caches, err := cache.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	caches = cache.WithMetrics(caches, recorder)
+
+	// init rate limiter
+	log.Trc().Msg("init rate limiter")
+	limiter, err := ratelimit.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// init health checks
+	log.Trc().Msg("init health checks")
+	migrations, err := database.NewMigrationChecker(cfg)
+	if err != nil {
+		return nil, err
+	}
+	checks := []health.Check{
+		{Name: "database", Check: pool.Ping},
+		{Name: "migrations", Check: migrations.Check},
+	}
+	if cfg.Health.CheckSMTP {
+		checks = append(checks, health.Check{Name: "smtp", Check: sender.Ping})
+	}
+	checker := health.New(cfg.Health.Timeout, log, checks...)
 
 	// init ID generator
 	log.Trc().Msg("init ID generator")
@@ -69,9 +119,23 @@ func NewApp(cfg *config.Config, log logger.Logger) (*App, error) {
 		return nil, err
 	}
 
+	// init totp cipher
+	log.Trc().Msg("init totp cipher")
+	cipher, err := totp.NewCipher(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// init password policy
+	log.Trc().Msg("init password policy")
+	policy, err := password.NewPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	// init services
 	log.Trc().Msg("init services")
-	services := service.Wire(cfg, repos, authenticator, templates, sender, caches, idGen, log)
+	services := service.Wire(cfg, repos, authenticator, templates, sender, caches, recorder, idGen, cipher, policy, log)
 
 	// init facades
 	log.Trc().Msg("init facades")
@@ -83,16 +147,23 @@ func NewApp(cfg *config.Config, log logger.Logger) (*App, error) {
 
 	// init controllers
 	log.Trc().Msg("init controllers")
-	controllers := controller.Wire(facades, validator, httpErrorHandler, log)
+	controllers := controller.Wire(cfg, facades, validator, httpErrorHandler, log)
 
 	// init router
 	log.Trc().Msg("init router")
-	webRouter := router.Setup(controllers, log, repos.UserRepository, authenticator, httpErrorHandler)
+	webRouter := router.Setup(cfg, controllers, log, repos.UserRepository, authenticator, httpErrorHandler, limiter, recorder, checker)
 
 	// create new App instance.
 	app := &App{
-		DB:     pool,
-		Router: webRouter,
+		DB:         pool,
+		Cache:      caches,
+		Limiter:    limiter,
+		Migrations: migrations,
+		Health:     checker,
+		Router:     webRouter,
+		Metrics:    recorder.Handler(),
+		Trash:      services.TrashService,
+		Outbox:     services.OutboxService,
 	}
 
 	return app, nil
@@ -9,9 +9,15 @@ import (
 )
 
 func GetUser(ctx context.Context) *model.User {
-	return ctx.Value(types.UserContextKey).(*model.User)
+	user, _ := ctx.Value(types.UserContextKey).(*model.User)
+	return user
 }
 
 func GetRequestID(ctx context.Context) any {
 	return ctx.Value(middleware.RequestIDKey)
 }
+
+func GetClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(types.ClientIPContextKey).(string)
+	return ip
+}
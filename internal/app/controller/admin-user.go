@@ -0,0 +1,276 @@
+package controller
+
+import (
+	"context"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/request"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/response"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"github.com/vlaship/book-catalog-go/internal/apperr"
+	"github.com/vlaship/book-catalog-go/internal/httphandling"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+	mw "github.com/vlaship/book-catalog-go/internal/router/middleware"
+	"github.com/vlaship/book-catalog-go/internal/validation"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const adminUserPath = "/v1/admin/users"
+
+// UserAdmin is an interface for managing the accounts of users
+//
+//go:generate mockgen -destination=../../../test/mock/controller/mock-user-admin.go -package=mock . UserAdmin
+type UserAdmin interface {
+	GetUsers(ctx context.Context, req *request.UserFilter) (*response.Page[response.AdminUser], error)
+	GetUser(ctx context.Context, userID types.UserID) (*response.AdminUser, error)
+	UpdateUser(ctx context.Context, userID types.UserID, req *request.UserPatch) (*response.AdminUser, error)
+	DeleteUser(ctx context.Context, userID types.UserID) error
+	ForcePasswordReset(ctx context.Context, userID types.UserID) error
+	ResendActivation(ctx context.Context, userID types.UserID) error
+}
+
+// AdminUserController is a controller for managing the accounts of users
+type AdminUserController struct {
+	users   UserAdmin
+	valid   validation.Validator
+	handler httphandling.HTTPErrorHandler
+	admin   func(next http.Handler) http.Handler
+	log     logger.Logger
+}
+
+// NewAdminUserController creates new admin user controller
+func NewAdminUserController(
+	users UserAdmin,
+	valid validation.Validator,
+	handler httphandling.HTTPErrorHandler,
+	log logger.Logger,
+) *AdminUserController {
+	return &AdminUserController{
+		users:   users,
+		valid:   valid,
+		handler: handler,
+		admin:   mw.NewRoleMiddleware(handler).RequireRole(model.UserRoleAdmin),
+		log:     log.New("AdminUserController"),
+	}
+}
+
+// RegisterRoutes registers routes
+func (ctrl *AdminUserController) RegisterRoutes(router chi.Router) {
+	ctrl.log.Trc().Msg("RegisterRoutes")
+
+	router.Route(adminUserPath, func(r chi.Router) {
+		r.Use(ctrl.admin)
+
+		r.Get("/", ctrl.handler.HandlerError(ctrl.GetUsers))
+		r.Get("/{userID}", ctrl.handler.HandlerError(ctrl.GetUser))
+		r.Patch("/{userID}", ctrl.handler.HandlerError(ctrl.UpdateUser))
+		r.Delete("/{userID}", ctrl.handler.HandlerError(ctrl.DeleteUser))
+		r.Post("/{userID}/password/reset", ctrl.handler.HandlerError(ctrl.ForcePasswordReset))
+		r.Post("/{userID}/activation/resend", ctrl.handler.HandlerError(ctrl.ResendActivation))
+	})
+}
+
+// GetUsers gets page of users, newest first
+// @Summary Get users
+// @Tags Admin
+// @Security BearerAuth
+// @Produce      json
+// @Param status query string false "User status" Enums(active, not_activated, suspended, locked)
+// @Param email query string false "Part of the email"
+// @Param limit query int false "Page size" minimum(1) maximum(100) default(20)
+// @Param cursor query string false "Cursor of the next page"
+// @Success 200 {object} response.Page[response.AdminUser]
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /v1/admin/users [get]
+func (ctrl *AdminUserController) GetUsers(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("GetUsers")
+
+	q := r.URL.Query()
+	limit, err := getQueryParam(q, "limit", strconv.Atoi)
+	if err != nil {
+		return err
+	}
+
+	req := &request.UserFilter{
+		Limit:  defaultLimit,
+		Cursor: q.Get("cursor"),
+		Status: q.Get("status"),
+		Email:  q.Get("email"),
+	}
+	if limit != nil {
+		req.Limit = *limit
+	}
+	if err = ctrl.valid.Struct(req); err != nil {
+		return apperr.ErrValidationRequest.WithFunc(apperr.WithDetail(err.Error()))
+	}
+
+	res, err := ctrl.users.GetUsers(r.Context(), req)
+	if err != nil {
+		return addTitle(err, "Problem getting users")
+	}
+
+	return encode(w, res)
+}
+
+// GetUser gets user
+// @Summary Get user
+// @Tags Admin
+// @Security BearerAuth
+// @Produce      json
+// @Param userID path int true "User ID"
+// @Success 200 {object} response.AdminUser
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 404 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /v1/admin/users/{userID} [get]
+func (ctrl *AdminUserController) GetUser(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("GetUser")
+
+	userID, err := getUserID(r)
+	if err != nil {
+		return err
+	}
+
+	res, err := ctrl.users.GetUser(r.Context(), userID)
+	if err != nil {
+		return addTitle(err, "Problem getting user")
+	}
+
+	return encode(w, res)
+}
+
+// UpdateUser changes status, role or plan of user
+// @Summary Update user
+// @Description Users that are no longer active are signed out. Admins cannot change their own status or role.
+// @Description Setting the status lifts the temporary lock of failed signins.
+// @Tags Admin
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param userID path int true "User ID"
+// @Param user body request.UserPatch true "Changes"
+// @Success 200 {object} response.AdminUser
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 404 {object} response.ProblemDetail
+// @Failure 409 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /v1/admin/users/{userID} [patch]
+func (ctrl *AdminUserController) UpdateUser(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("UpdateUser")
+
+	userID, err := getUserID(r)
+	if err != nil {
+		return err
+	}
+
+	req, err := decode(w, r, &request.UserPatch{}, ctrl.valid)
+	if err != nil {
+		return err
+	}
+
+	res, err := ctrl.users.UpdateUser(r.Context(), userID, req)
+	if err != nil {
+		return addTitle(err, "Problem updating user")
+	}
+
+	return encode(w, res)
+}
+
+// DeleteUser deletes user
+// @Summary Delete user
+// @Description The user is signed out. Admins cannot delete their own account.
+// @Tags Admin
+// @Security BearerAuth
+// @Param userID path int true "User ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 404 {object} response.ProblemDetail
+// @Failure 409 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /v1/admin/users/{userID} [delete]
+func (ctrl *AdminUserController) DeleteUser(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("DeleteUser")
+
+	userID, err := getUserID(r)
+	if err != nil {
+		return err
+	}
+
+	if err = ctrl.users.DeleteUser(r.Context(), userID); err != nil {
+		return addTitle(err, "Problem deleting user")
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// ForcePasswordReset invalidates the password of user, signs the user out and mails reset password otp
+// @Summary Force password reset
+// @Tags Admin
+// @Security BearerAuth
+// @Param userID path int true "User ID"
+// @Success 200 "OK"
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 404 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /v1/admin/users/{userID}/password/reset [post]
+func (ctrl *AdminUserController) ForcePasswordReset(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("ForcePasswordReset")
+
+	userID, err := getUserID(r)
+	if err != nil {
+		return err
+	}
+
+	if err = ctrl.users.ForcePasswordReset(r.Context(), userID); err != nil {
+		return addTitle(err, "Problem resetting password")
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+// ResendActivation mails new activation otp to user that is not activated
+// @Summary Resend activation mail
+// @Tags Admin
+// @Security BearerAuth
+// @Param userID path int true "User ID"
+// @Success 200 "OK"
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 404 {object} response.ProblemDetail
+// @Failure 409 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /v1/admin/users/{userID}/activation/resend [post]
+func (ctrl *AdminUserController) ResendActivation(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("ResendActivation")
+
+	userID, err := getUserID(r)
+	if err != nil {
+		return err
+	}
+
+	if err = ctrl.users.ResendActivation(r.Context(), userID); err != nil {
+		return addTitle(err, "Problem resending activation")
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"context"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/request"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/response"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"github.com/vlaship/book-catalog-go/internal/apperr"
+	"github.com/vlaship/book-catalog-go/internal/httphandling"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+	mw "github.com/vlaship/book-catalog-go/internal/router/middleware"
+	"github.com/vlaship/book-catalog-go/internal/validation"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const auditPath = "/v1/audit"
+
+// AuditReader is an interface for audit log reader
+//
+//go:generate mockgen -destination=../../../test/mock/controller/mock-audit-reader.go -package=mock . AuditReader
+type AuditReader interface {
+	GetAuditRecords(ctx context.Context, req *request.AuditFilter) (*response.Page[response.AuditRecord], error)
+}
+
+// AuditController is a controller for audit log
+type AuditController struct {
+	reader  AuditReader
+	valid   validation.Validator
+	handler httphandling.HTTPErrorHandler
+	admin   func(next http.Handler) http.Handler
+	log     logger.Logger
+}
+
+// NewAuditController creates new audit log controller
+func NewAuditController(
+	reader AuditReader,
+	valid validation.Validator,
+	handler httphandling.HTTPErrorHandler,
+	log logger.Logger,
+) *AuditController {
+	return &AuditController{
+		reader:  reader,
+		valid:   valid,
+		handler: handler,
+		admin:   mw.NewRoleMiddleware(handler).RequireRole(model.UserRoleAdmin),
+		log:     log.New("AuditController"),
+	}
+}
+
+// RegisterRoutes registers routes
+func (ctrl *AuditController) RegisterRoutes(router chi.Router) {
+	ctrl.log.Trc().Msg("RegisterRoutes")
+
+	router.With(ctrl.admin).Get(auditPath, ctrl.handler.HandlerError(ctrl.GetAuditRecords))
+}
+
+// GetAuditRecords gets page of audit records, newest first
+// @Summary Get audit log
+// @Tags Audit
+// @Security BearerAuth
+// @Produce      json
+// @Param entity query string false "Entity type" Enums(book, author)
+// @Param id query int false "Entity ID"
+// @Param limit query int false "Page size" minimum(1) maximum(100) default(20)
+// @Param cursor query string false "Cursor of the next page"
+// @Success 200 {object} response.Page[response.AuditRecord]
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /v1/audit [get]
+func (ctrl *AuditController) GetAuditRecords(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("GetAuditRecords")
+
+	q := r.URL.Query()
+	limit, err := getQueryParam(q, "limit", strconv.Atoi)
+	if err != nil {
+		return err
+	}
+	entityID, err := getQueryParam(q, "id", types.NewID)
+	if err != nil {
+		return err
+	}
+
+	req := &request.AuditFilter{
+		Limit:    defaultLimit,
+		Cursor:   q.Get("cursor"),
+		Entity:   q.Get("entity"),
+		EntityID: entityID,
+	}
+	if limit != nil {
+		req.Limit = *limit
+	}
+	if err = ctrl.valid.Struct(req); err != nil {
+		return apperr.ErrValidationRequest.WithFunc(apperr.WithDetail(err.Error()))
+	}
+
+	res, err := ctrl.reader.GetAuditRecords(r.Context(), req)
+	if err != nil {
+		return addTitle(err, "Problem getting audit log")
+	}
+
+	return encode(w, res)
+}
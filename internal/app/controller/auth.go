@@ -20,7 +20,10 @@ const (
 //go:generate mockgen -destination=../../../test/mock/controller/mock-auth.go -package=mock github.com/vlaship/book-catalog-go/internal/app/controller Auth
 type Auth interface {
 	Signin(ctx context.Context, req *request.Signin) (*response.Signin, error)
+	SigninTwoFactor(ctx context.Context, req *request.SigninTwoFactor) (*response.Signin, error)
 	Signup(ctx context.Context, req *request.Signup) error
+	Refresh(ctx context.Context, req *request.RefreshToken) (*response.Signin, error)
+	Signout(ctx context.Context, req *request.RefreshToken) error
 }
 
 // PasswordResetHandler interface
@@ -74,7 +77,10 @@ func (ctrl *AuthController) RegisterRoutes(router chi.Router) {
 
 	router.Route(authPath, func(r chi.Router) {
 		r.Post("/signin", ctrl.eh.HandlerError(ctrl.Signin))
+		r.Post("/signin/2fa", ctrl.eh.HandlerError(ctrl.SigninTwoFactor))
 		r.Post("/signup", ctrl.eh.HandlerError(ctrl.Signup))
+		r.Post("/token/refresh", ctrl.eh.HandlerError(ctrl.Refresh))
+		r.Post("/signout", ctrl.eh.HandlerError(ctrl.Signout))
 		r.Post("/activation/activate", ctrl.eh.HandlerError(ctrl.Activate))
 		r.Post("/activation/resend", ctrl.eh.HandlerError(ctrl.Resend))
 		r.Post("/password/reset", ctrl.eh.HandlerError(ctrl.Reset))
@@ -84,6 +90,8 @@ func (ctrl *AuthController) RegisterRoutes(router chi.Router) {
 
 // Signin
 // @Summary Signin
+// @Description Users with two-factor authentication get a challenge token instead of the tokens.
+// @Description Failed signins delay the next attempt of the username and of the IP, too many lock the user for a while.
 // @Tags Authentication
 // @Accept  json
 // @Produce  json
@@ -92,6 +100,7 @@ func (ctrl *AuthController) RegisterRoutes(router chi.Router) {
 // @Failure 400 {object} response.ProblemDetail
 // @Failure 401 {object} response.ProblemDetail
 // @Failure 403 {object} response.ProblemDetail
+// @Failure 429 {object} response.ProblemDetail
 // @Failure 500 {object} response.ProblemDetail
 // @Router /v1/auth/signin [post]
 func (ctrl *AuthController) Signin(w http.ResponseWriter, r *http.Request) error {
@@ -110,8 +119,93 @@ func (ctrl *AuthController) Signin(w http.ResponseWriter, r *http.Request) error
 	return encode(w, res)
 }
 
+// SigninTwoFactor
+// @Summary Signin with two-factor authentication code
+// @Description Exchanges the challenge token of signin and a code of the authenticator app or a recovery code for the tokens.
+// @Tags Authentication
+// @Accept  json
+// @Produce  json
+// @Param signin body request.SigninTwoFactor true "Challenge and code"
+// @Success 200 {object} response.Signin
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 429 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /v1/auth/signin/2fa [post]
+func (ctrl *AuthController) SigninTwoFactor(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("SigninTwoFactor")
+
+	req, err := decode(w, r, &request.SigninTwoFactor{}, ctrl.valid)
+	if err != nil {
+		return err
+	}
+
+	res, err := ctrl.auth.SigninTwoFactor(r.Context(), req)
+	if err != nil {
+		return addTitle(err, "Problem signing in")
+	}
+
+	return encode(w, res)
+}
+
+// Refresh
+// @Summary Refresh Tokens
+// @Tags Authentication
+// @Accept  json
+// @Produce  json
+// @Param refresh body request.RefreshToken true "Refresh token"
+// @Success 200 {object} response.Signin
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /v1/auth/token/refresh [post]
+func (ctrl *AuthController) Refresh(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("Refresh")
+
+	req, err := decode(w, r, &request.RefreshToken{}, ctrl.valid)
+	if err != nil {
+		return err
+	}
+
+	res, err := ctrl.auth.Refresh(r.Context(), req)
+	if err != nil {
+		return addTitle(err, "Problem refreshing token")
+	}
+
+	return encode(w, res)
+}
+
+// Signout
+// @Summary Signout
+// @Tags Authentication
+// @Accept  json
+// @Param signout body request.RefreshToken true "Refresh token"
+// @Success 200 "OK"
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /v1/auth/signout [post]
+func (ctrl *AuthController) Signout(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("Signout")
+
+	req, err := decode(w, r, &request.RefreshToken{}, ctrl.valid)
+	if err != nil {
+		return err
+	}
+
+	if err = ctrl.auth.Signout(r.Context(), req); err != nil {
+		return addTitle(err, "Problem signing out")
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
 // Signup
 // @Summary Signup
+// @Description The password must meet the password policy, the failed rules are listed in the violations.
 // @Tags Authentication
 // @Accept  json
 // @Produce  json
@@ -127,6 +221,9 @@ func (ctrl *AuthController) Signup(w http.ResponseWriter, r *http.Request) error
 	if err != nil {
 		return err
 	}
+	if req.Language == "" {
+		req.Language = preferredLanguage(r)
+	}
 
 	if err = ctrl.auth.Signup(r.Context(), req); err != nil {
 		return addTitle(err, "Problem signing up")
@@ -212,6 +309,7 @@ func (ctrl *AuthController) Reset(w http.ResponseWriter, r *http.Request) error
 
 // Replace
 // @Summary Replace Password
+// @Description The password must meet the password policy, the failed rules are listed in the violations.
 // @Tags Authentication
 // @Success 200 "OK"
 // @Failure 400 {object} response.ProblemDetail
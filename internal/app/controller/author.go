@@ -4,9 +4,11 @@ import (
 	"context"
 	"github.com/vlaship/book-catalog-go/internal/app/dto/request"
 	"github.com/vlaship/book-catalog-go/internal/app/dto/response"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
 	"github.com/vlaship/book-catalog-go/internal/app/types"
 	"github.com/vlaship/book-catalog-go/internal/httphandling"
 	"github.com/vlaship/book-catalog-go/internal/logger"
+	mw "github.com/vlaship/book-catalog-go/internal/router/middleware"
 	"github.com/vlaship/book-catalog-go/internal/validation"
 	"net/http"
 
@@ -21,6 +23,7 @@ const authorPath = "/v1/author"
 type AuthorReader interface {
 	GetAuthors(ctx context.Context) ([]response.ListAuthor, error)
 	GetAuthor(ctx context.Context, authorID types.ID) (*response.Author, error)
+	GetAuthorBooks(ctx context.Context, authorID types.ID, req *request.BookFilter) (*response.AuthorBooks, error)
 }
 
 // AuthorWriter is an interface for author writer
@@ -28,8 +31,8 @@ type AuthorReader interface {
 //go:generate mockgen -destination=../../../test/mock/controller/mock-author-writer.go -package=mock . AuthorWriter
 type AuthorWriter interface {
 	CreateAuthor(ctx context.Context, req *request.CreateAuthor) (*response.CreateAuthor, error)
-	UpdateAuthor(ctx context.Context, authorID types.ID, author *request.UpdateAuthor) error
-	DeleteAuthor(ctx context.Context, authorID types.ID) error
+	UpdateAuthor(ctx context.Context, authorID types.ID, author *request.UpdateAuthor, version int64) error
+	DeleteAuthor(ctx context.Context, authorID types.ID, version int64) error
 }
 
 // AuthorController is a controller for author
@@ -38,6 +41,7 @@ type AuthorController struct {
 	writer  AuthorWriter
 	valid   validation.Validator
 	handler httphandling.HTTPErrorHandler
+	editor  func(next http.Handler) http.Handler
 	log     logger.Logger
 }
 
@@ -54,6 +58,7 @@ func NewAuthorController(
 		writer:  writer,
 		valid:   valid,
 		handler: handler,
+		editor:  mw.NewRoleMiddleware(handler).RequireRole(model.UserRoleEditor),
 		log:     log.New("AuthorController"),
 	}
 }
@@ -64,12 +69,13 @@ func (ctrl *AuthorController) RegisterRoutes(router chi.Router) {
 
 	router.Route(authorPath, func(r chi.Router) {
 		r.Get("/", ctrl.handler.HandlerError(ctrl.GetAuthors))
-		r.Post("/", ctrl.handler.HandlerError(ctrl.CreateAuthor))
+		r.With(ctrl.editor).Post("/", ctrl.handler.HandlerError(ctrl.CreateAuthor))
 
 		r.Route("/{authorID}", func(r chi.Router) {
 			r.Get("/", ctrl.handler.HandlerError(ctrl.GetAuthor))
-			r.Put("/", ctrl.handler.HandlerError(ctrl.UpdateAuthor))
-			r.Delete("/", ctrl.handler.HandlerError(ctrl.DeleteAuthor))
+			r.With(ctrl.editor).Put("/", ctrl.handler.HandlerError(ctrl.UpdateAuthor))
+			r.With(ctrl.editor).Delete("/", ctrl.handler.HandlerError(ctrl.DeleteAuthor))
+			r.Get("/books", ctrl.handler.HandlerError(ctrl.GetAuthorBooks))
 		})
 	})
 }
@@ -121,6 +127,47 @@ func (ctrl *AuthorController) GetAuthor(w http.ResponseWriter, r *http.Request)
 		return addTitle(err, "Problem getting author")
 	}
 
+	setETag(w, res.Version)
+
+	return encode(w, res)
+}
+
+// GetAuthorBooks gets author with page of their books
+// @Summary Get author with books
+// @Tags Author
+// @Security BearerAuth
+// @Produce      json
+// @Param authorID path int true "Author ID"
+// @Param limit query int false "Page size" minimum(1) maximum(100) default(20)
+// @Param cursor query string false "Cursor of the next page"
+// @Param min_price query number false "Minimum price"
+// @Param max_price query number false "Maximum price"
+// @Param title query string false "Title substring"
+// @Param sort query string false "Sort order" Enums(id, -id, title, -title, price, -price) default(id)
+// @Success 200 {object} response.AuthorBooks
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 404 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /v1/author/{authorID}/books [get]
+func (ctrl *AuthorController) GetAuthorBooks(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("GetAuthorBooks")
+
+	authorID, err := getAuthorID(r)
+	if err != nil {
+		return err
+	}
+	req, err := getBookFilter(r, ctrl.valid)
+	if err != nil {
+		return err
+	}
+
+	res, err := ctrl.reader.GetAuthorBooks(r.Context(), authorID, req)
+	if err != nil {
+		return addTitle(err, "Problem getting author books")
+	}
+
 	return encode(w, res)
 }
 
@@ -161,12 +208,14 @@ func (ctrl *AuthorController) CreateAuthor(w http.ResponseWriter, r *http.Reques
 // @Security BearerAuth
 // @Accept      json
 // @Param authorID path int true "Author ID"
+// @Param If-Match header string false "ETag of the author"
 // @Param author body request.UpdateAuthor true "Author"
 // @Success 200 "OK"
 // @Failure 400 {object} response.ProblemDetail
 // @Failure 401 {object} response.ProblemDetail
 // @Failure 403 {object} response.ProblemDetail
 // @Failure 404 {object} response.ProblemDetail
+// @Failure 412 {object} response.ProblemDetail
 // @Failure 500 {object} response.ProblemDetail
 // @Router /v1/author/{authorID} [put]
 func (ctrl *AuthorController) UpdateAuthor(w http.ResponseWriter, r *http.Request) error {
@@ -176,12 +225,16 @@ func (ctrl *AuthorController) UpdateAuthor(w http.ResponseWriter, r *http.Reques
 	if err != nil {
 		return err
 	}
+	version, err := getIfMatch(r)
+	if err != nil {
+		return err
+	}
 	req, err := decode(w, r, &request.UpdateAuthor{}, ctrl.valid)
 	if err != nil {
 		return err
 	}
 
-	err = ctrl.writer.UpdateAuthor(r.Context(), authorID, req)
+	err = ctrl.writer.UpdateAuthor(r.Context(), authorID, req, version)
 	if err != nil {
 		return addTitle(err, "Problem updating author")
 	}
@@ -196,11 +249,13 @@ func (ctrl *AuthorController) UpdateAuthor(w http.ResponseWriter, r *http.Reques
 // @Tags Author
 // @Security BearerAuth
 // @Param authorID path int true "Author ID"
+// @Param If-Match header string false "ETag of the author"
 // @Success 200 "OK"
 // @Failure 400 {object} response.ProblemDetail
 // @Failure 401 {object} response.ProblemDetail
 // @Failure 403 {object} response.ProblemDetail
 // @Failure 404 {object} response.ProblemDetail
+// @Failure 412 {object} response.ProblemDetail
 // @Failure 500 {object} response.ProblemDetail
 // @Router /v1/author/{authorID} [delete]
 func (ctrl *AuthorController) DeleteAuthor(w http.ResponseWriter, r *http.Request) error {
@@ -211,7 +266,12 @@ func (ctrl *AuthorController) DeleteAuthor(w http.ResponseWriter, r *http.Reques
 		return err
 	}
 
-	err = ctrl.writer.DeleteAuthor(r.Context(), authorID)
+	version, err := getIfMatch(r)
+	if err != nil {
+		return err
+	}
+
+	err = ctrl.writer.DeleteAuthor(r.Context(), authorID, version)
 	if err != nil {
 		return addTitle(err, "Problem deleting author")
 	}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"github.com/vlaship/book-catalog-go/internal/app/dto/request"
 	"github.com/vlaship/book-catalog-go/internal/app/dto/response"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
 	"github.com/vlaship/book-catalog-go/internal/app/types"
 	"github.com/vlaship/book-catalog-go/internal/httphandling"
 	"github.com/vlaship/book-catalog-go/internal/logger"
+	mw "github.com/vlaship/book-catalog-go/internal/router/middleware"
 	"github.com/vlaship/book-catalog-go/internal/validation"
 	"net/http"
 
@@ -19,8 +21,9 @@ const bookPath = "/v1/book"
 //
 //go:generate mockgen -destination=../../../test/mock/controller/mock-book-reader.go -package=mock . BookReader
 type BookReader interface {
-	GetBook(ctx context.Context, bookID types.ID) (*response.Book, error)
-	GetBooks(ctx context.Context) ([]response.ListBook, error)
+	GetBook(ctx context.Context, bookID types.ID, req *request.BookExpand) (*response.Book, error)
+	GetBookByISBN(ctx context.Context, isbn string, req *request.BookExpand) (*response.Book, error)
+	GetBooks(ctx context.Context, req *request.BookFilter) (*response.Page[response.ListBook], error)
 }
 
 // BookWriter is an interface for book writer
@@ -28,8 +31,8 @@ type BookReader interface {
 //go:generate mockgen -destination=../../../test/mock/controller/mock-book-writer.go -package=mock . BookWriter
 type BookWriter interface {
 	CreateBook(ctx context.Context, req *request.CreateBook) (*response.CreateBook, error)
-	UpdateBook(ctx context.Context, bookID types.ID, req *request.UpdateBook) error
-	DeleteBook(ctx context.Context, bookID types.ID) error
+	UpdateBook(ctx context.Context, bookID types.ID, req *request.UpdateBook, version int64) error
+	DeleteBook(ctx context.Context, bookID types.ID, version int64) error
 }
 
 // BookController is a controller for book
@@ -38,6 +41,7 @@ type BookController struct {
 	writer  BookWriter
 	valid   validation.Validator
 	handler httphandling.HTTPErrorHandler
+	editor  func(next http.Handler) http.Handler
 	log     logger.Logger
 }
 
@@ -54,6 +58,7 @@ func NewBookController(
 		writer:  writer,
 		valid:   valid,
 		handler: handler,
+		editor:  mw.NewRoleMiddleware(handler).RequireRole(model.UserRoleEditor),
 		log:     log.New("BookController"),
 	}
 }
@@ -64,22 +69,32 @@ func (ctrl *BookController) RegisterRoutes(router chi.Router) {
 
 	router.Route(bookPath, func(r chi.Router) {
 		r.Get("/", ctrl.handler.HandlerError(ctrl.GetBooks))
-		r.Post("/", ctrl.handler.HandlerError(ctrl.CreateBook))
+		r.With(ctrl.editor).Post("/", ctrl.handler.HandlerError(ctrl.CreateBook))
+		r.Get("/isbn/{isbn}", ctrl.handler.HandlerError(ctrl.GetBookByISBN))
 
 		r.Route("/{bookID}", func(r chi.Router) {
 			r.Get("/", ctrl.handler.HandlerError(ctrl.GetBook))
-			r.Put("/", ctrl.handler.HandlerError(ctrl.UpdateBook))
-			r.Delete("/", ctrl.handler.HandlerError(ctrl.DeleteBook))
+			r.With(ctrl.editor).Put("/", ctrl.handler.HandlerError(ctrl.UpdateBook))
+			r.With(ctrl.editor).Delete("/", ctrl.handler.HandlerError(ctrl.DeleteBook))
 		})
 	})
 }
 
-// GetBooks gets books
+// GetBooks gets page of books
 // @Summary Get books
 // @Tags Books
 // @Security BearerAuth
 // @Produce      json
-// @Success 200 {array} response.ListBook
+// @Param limit query int false "Page size" minimum(1) maximum(100) default(20)
+// @Param cursor query string false "Cursor of the next page"
+// @Param author_id query int false "Author ID"
+// @Param min_price query number false "Minimum price"
+// @Param max_price query number false "Maximum price"
+// @Param title query string false "Title substring"
+// @Param sort query string false "Sort order" Enums(id, -id, title, -title, price, -price) default(id)
+// @Param expand query string false "Relations to embed" Enums(author)
+// @Success 200 {object} response.Page[response.ListBook]
+// @Failure 400 {object} response.ProblemDetail
 // @Failure 401 {object} response.ProblemDetail
 // @Failure 403 {object} response.ProblemDetail
 // @Failure 500 {object} response.ProblemDetail
@@ -87,7 +102,12 @@ func (ctrl *BookController) RegisterRoutes(router chi.Router) {
 func (ctrl *BookController) GetBooks(w http.ResponseWriter, r *http.Request) error {
 	ctrl.log.Trc().Ctx(r.Context()).Msg("GetBooks")
 
-	res, err := ctrl.reader.GetBooks(r.Context())
+	req, err := getBookFilter(r, ctrl.valid)
+	if err != nil {
+		return err
+	}
+
+	res, err := ctrl.reader.GetBooks(r.Context(), req)
 	if err != nil {
 		return addTitle(err, "Problem getting books")
 	}
@@ -101,6 +121,7 @@ func (ctrl *BookController) GetBooks(w http.ResponseWriter, r *http.Request) err
 // @Security BearerAuth
 // @Produce      json
 // @Param bookID path int true "Book ID"
+// @Param expand query string false "Relations to embed" Enums(author)
 // @Success 200 {object} response.Book
 // @Failure 400 {object} response.ProblemDetail
 // @Failure 401 {object} response.ProblemDetail
@@ -116,15 +137,59 @@ func (ctrl *BookController) GetBook(w http.ResponseWriter, r *http.Request) erro
 		return err
 	}
 
-	res, err := ctrl.reader.GetBook(r.Context(), bookID)
+	req, err := getBookExpand(r, ctrl.valid)
+	if err != nil {
+		return err
+	}
+
+	res, err := ctrl.reader.GetBook(r.Context(), bookID, req)
 	if err != nil {
 		return addTitle(err, "Problem getting book")
 	}
 
+	setETag(w, res.Version)
+
 	return encode(w, res)
 
 }
 
+// GetBookByISBN gets book by ISBN-10 or ISBN-13, hyphens are allowed
+// @Summary Get book by ISBN
+// @Tags Books
+// @Security BearerAuth
+// @Produce      json
+// @Param isbn path string true "ISBN"
+// @Param expand query string false "Relations to embed" Enums(author)
+// @Success 200 {object} response.Book
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 404 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /v1/book/isbn/{isbn} [get]
+func (ctrl *BookController) GetBookByISBN(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("GetBookByISBN")
+
+	isbn, err := getISBN(r)
+	if err != nil {
+		return err
+	}
+
+	req, err := getBookExpand(r, ctrl.valid)
+	if err != nil {
+		return err
+	}
+
+	res, err := ctrl.reader.GetBookByISBN(r.Context(), isbn, req)
+	if err != nil {
+		return addTitle(err, "Problem getting book")
+	}
+
+	setETag(w, res.Version)
+
+	return encode(w, res)
+}
+
 // CreateBook creates a new book
 // @Summary Create a new book
 // @Tags Books
@@ -161,12 +226,14 @@ func (ctrl *BookController) CreateBook(w http.ResponseWriter, r *http.Request) e
 // @Security BearerAuth
 // @Accept  json
 // @Param bookID path int true "Book ID"
+// @Param If-Match header string false "ETag of the book"
 // @Param book body request.UpdateBook true "Book"
 // @Success 200 "OK"
 // @Failure 400 {object} response.ProblemDetail
 // @Failure 401 {object} response.ProblemDetail
 // @Failure 403 {object} response.ProblemDetail
 // @Failure 404 {object} response.ProblemDetail
+// @Failure 412 {object} response.ProblemDetail
 // @Failure 500 {object} response.ProblemDetail
 // @Router /v1/book/{bookID} [put]
 func (ctrl *BookController) UpdateBook(w http.ResponseWriter, r *http.Request) error {
@@ -177,12 +244,17 @@ func (ctrl *BookController) UpdateBook(w http.ResponseWriter, r *http.Request) e
 		return err
 	}
 
+	version, err := getIfMatch(r)
+	if err != nil {
+		return err
+	}
+
 	req, err := decode(w, r, &request.UpdateBook{}, ctrl.valid)
 	if err != nil {
 		return err
 	}
 
-	err = ctrl.writer.UpdateBook(r.Context(), bookID, req)
+	err = ctrl.writer.UpdateBook(r.Context(), bookID, req, version)
 	if err != nil {
 		return addTitle(err, "Problem updating book")
 	}
@@ -197,11 +269,13 @@ func (ctrl *BookController) UpdateBook(w http.ResponseWriter, r *http.Request) e
 // @Tags Books
 // @Security BearerAuth
 // @Param bookID path int true "Book ID"
+// @Param If-Match header string false "ETag of the book"
 // @Success 200 "OK"
 // @Failure 400 {object} response.ProblemDetail
 // @Failure 401 {object} response.ProblemDetail
 // @Failure 403 {object} response.ProblemDetail
 // @Failure 404 {object} response.ProblemDetail
+// @Failure 412 {object} response.ProblemDetail
 // @Failure 500 {object} response.ProblemDetail
 // @Router /v1/book/{bookID} [delete]
 func (ctrl *BookController) DeleteBook(w http.ResponseWriter, r *http.Request) error {
@@ -212,7 +286,12 @@ func (ctrl *BookController) DeleteBook(w http.ResponseWriter, r *http.Request) e
 		return err
 	}
 
-	err = ctrl.writer.DeleteBook(r.Context(), bookID)
+	version, err := getIfMatch(r)
+	if err != nil {
+		return err
+	}
+
+	err = ctrl.writer.DeleteBook(r.Context(), bookID, version)
 	if err != nil {
 		return addTitle(err, "Problem deleting book")
 	}
@@ -1,8 +1,14 @@
 package controller
 
 type Controllers struct {
-	AuthController   *AuthController
-	UserController   *UserController
-	BookController   *BookController
-	AuthorController *AuthorController
+	AuthController      *AuthController
+	UserController      *UserController
+	BookController      *BookController
+	AuthorController    *AuthorController
+	SearchController    *SearchController
+	AuditController     *AuditController
+	TrashController     *TrashController
+	ImportController    *ImportController
+	ExportController    *ExportController
+	AdminUserController *AdminUserController
 }
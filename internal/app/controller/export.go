@@ -0,0 +1,350 @@
+package controller
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/request"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/response"
+	"github.com/vlaship/book-catalog-go/internal/apperr"
+	"github.com/vlaship/book-catalog-go/internal/config"
+	"github.com/vlaship/book-catalog-go/internal/httphandling"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/validation"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const exportPath = "/v1/export"
+
+// Exporter is an interface for catalog export
+//
+//go:generate mockgen -destination=../../../test/mock/controller/mock-exporter.go -package=mock . Exporter
+type Exporter interface {
+	ExportBooks(ctx context.Context, req *request.BookFilter, fn func(book *response.ExportBook) error) error
+}
+
+// ExportController is a controller for catalog export
+type ExportController struct {
+	exporter Exporter
+	valid    validation.Validator
+	handler  httphandling.HTTPErrorHandler
+	onix     onixSettings
+	log      logger.Logger
+}
+
+// NewExportController creates new export controller
+func NewExportController(
+	cfg *config.Config,
+	exporter Exporter,
+	valid validation.Validator,
+	handler httphandling.HTTPErrorHandler,
+	log logger.Logger,
+) *ExportController {
+	return &ExportController{
+		exporter: exporter,
+		valid:    valid,
+		handler:  handler,
+		onix: onixSettings{
+			currency: cfg.Export.Currency,
+			sender:   cfg.Export.SenderName,
+			domain:   cfg.Domain,
+		},
+		log: log.New("ExportController"),
+	}
+}
+
+// RegisterRoutes registers routes
+func (ctrl *ExportController) RegisterRoutes(router chi.Router) {
+	ctrl.log.Trc().Msg("RegisterRoutes")
+
+	router.Get(exportPath, ctrl.handler.HandlerError(ctrl.Export))
+}
+
+// Export streams books with their authors, the filters are the ones of the book listing,
+// the limit is ignored and the cursor resumes the export after the given book
+// @Summary Export catalog
+// @Tags Export
+// @Security BearerAuth
+// @Produce text/csv,application/x-ndjson,application/xml
+// @Param format query string false "Export format" Enums(csv, ndjson, onix) default(csv)
+// @Param cursor query string false "Cursor to resume the export after"
+// @Param author_id query int false "Author ID"
+// @Param min_price query number false "Minimum price"
+// @Param max_price query number false "Maximum price"
+// @Param title query string false "Title substring"
+// @Param sort query string false "Sort order" Enums(id, -id, title, -title, price, -price) default(id)
+// @Success 200 {file} file
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /v1/export [get]
+func (ctrl *ExportController) Export(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("Export")
+
+	format := &request.ExportFormat{Format: r.URL.Query().Get("format")}
+	if format.Format == "" {
+		format.Format = request.ExportFormatCSV
+	}
+	if err := ctrl.valid.Struct(format); err != nil {
+		return apperr.ErrValidationRequest.WithFunc(apperr.WithDetail(err.Error()))
+	}
+
+	req, err := getBookFilter(r, ctrl.valid)
+	if err != nil {
+		return err
+	}
+
+	// a large catalog takes longer than the server write timeout
+	if err = http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		ctrl.log.Wrn().Err(err).Ctx(r.Context()).Msg("failed to clear write deadline")
+	}
+
+	out := &sentWriter{w: w}
+	buf := bufio.NewWriter(out)
+	ew, contentType, ext := ctrl.newExportWriter(format.Format, buf)
+
+	w.Header().Set(headerContentType, contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "catalog."+ext))
+
+	err = ctrl.exporter.ExportBooks(r.Context(), req, ew.write)
+	if err == nil {
+		err = ew.close()
+	}
+	if err == nil {
+		err = buf.Flush()
+	}
+	if err == nil {
+		return nil
+	}
+
+	if !out.sent {
+		// nothing is sent yet, the error can be reported as usual
+		w.Header().Del("Content-Disposition")
+		return addTitle(err, "Problem exporting catalog")
+	}
+
+	// the response is partially sent, abort it so that the client does not take it as complete
+	ctrl.log.Err(err).Ctx(r.Context()).Msg("export aborted")
+	panic(http.ErrAbortHandler)
+}
+
+// newExportWriter returns the writer of the format with its content type and file extension
+func (ctrl *ExportController) newExportWriter(format string, w io.Writer) (exportWriter, string, string) {
+	switch format {
+	case request.ExportFormatNDJSON:
+		return &ndjsonExportWriter{enc: json.NewEncoder(w)}, "application/x-ndjson", "ndjson"
+	case request.ExportFormatONIX:
+		return &onixExportWriter{enc: xml.NewEncoder(w), w: w, settings: ctrl.onix}, "application/xml", "xml"
+	default:
+		return &csvExportWriter{w: csv.NewWriter(w)}, "text/csv", "csv"
+	}
+}
+
+// sentWriter remembers whether anything is written to the response
+type sentWriter struct {
+	w    io.Writer
+	sent bool
+}
+
+// Write implements io.Writer
+func (s *sentWriter) Write(p []byte) (int, error) {
+	s.sent = true
+	return s.w.Write(p)
+}
+
+// exportWriter writes exported books in a format
+type exportWriter interface {
+	write(book *response.ExportBook) error
+	close() error
+}
+
+// csvExportWriter writes books as CSV with a header, the columns match the import
+type csvExportWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func (c *csvExportWriter) write(book *response.ExportBook) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	var dob string
+	if book.AuthorDob != nil {
+		dob = book.AuthorDob.Format(time.DateOnly)
+	}
+
+	return c.w.Write([]string{
+		strconv.FormatInt(int64(book.ID), 10),
+		book.Title,
+		book.Description,
+		book.ISBN,
+		book.Price.String(),
+		strconv.FormatInt(int64(book.AuthorID), 10),
+		book.AuthorName,
+		dob,
+	})
+}
+
+func (c *csvExportWriter) writeHeader() error {
+	if c.header {
+		return nil
+	}
+	c.header = true
+
+	return c.w.Write([]string{"id", "title", "description", "isbn", "price", "author_id", "author_name", "author_dob"})
+}
+
+func (c *csvExportWriter) close() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// ndjsonExportWriter writes one JSON object per line
+type ndjsonExportWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonExportWriter) write(book *response.ExportBook) error {
+	return n.enc.Encode(book)
+}
+
+func (n *ndjsonExportWriter) close() error {
+	return nil
+}
+
+// onixSettings are message wide values of ONIX export
+type onixSettings struct {
+	currency string
+	sender   string
+	domain   string
+}
+
+// onixExportWriter writes books as products of ONIX 3.0 message
+type onixExportWriter struct {
+	enc      *xml.Encoder
+	w        io.Writer
+	settings onixSettings
+	started  bool
+}
+
+func (o *onixExportWriter) write(book *response.ExportBook) error {
+	if err := o.start(); err != nil {
+		return err
+	}
+
+	return o.enc.Encode(o.product(book))
+}
+
+func (o *onixExportWriter) start() error {
+	if o.started {
+		return nil
+	}
+	o.started = true
+
+	if _, err := io.WriteString(o.w, xml.Header); err != nil {
+		return err
+	}
+	err := o.enc.EncodeToken(xml.StartElement{
+		Name: xml.Name{Local: "ONIXMessage"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "release"}, Value: response.ONIXRelease},
+			{Name: xml.Name{Local: "xmlns"}, Value: response.ONIXNamespace},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return o.enc.Encode(response.ONIXHeader{
+		SenderName:   o.settings.sender,
+		SentDateTime: time.Now().UTC().Format("20060102T1504Z"),
+	})
+}
+
+func (o *onixExportWriter) close() error {
+	if err := o.start(); err != nil {
+		return err
+	}
+	if err := o.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "ONIXMessage"}}); err != nil {
+		return err
+	}
+	return o.enc.Flush()
+}
+
+// product maps the book to ONIX product, code values are from ONIX code lists:
+// identifiers 01 proprietary, 02 ISBN-10, 15 ISBN-13; title 01 distinctive title;
+// contributor A01 author with date 50 of birth; text 03 description; price 01 RRP excluding tax
+func (o *onixExportWriter) product(book *response.ExportBook) *response.ONIXProduct {
+	p := &response.ONIXProduct{
+		RecordReference:  fmt.Sprintf("%s:book:%d", o.settings.domain, book.ID),
+		NotificationType: "03",
+		ProductIdentifiers: []response.ONIXProductIdentifier{
+			{ProductIDType: "01", IDTypeName: "book_id", IDValue: strconv.FormatInt(int64(book.ID), 10)},
+		},
+		DescriptiveDetail: response.ONIXDescriptiveDetail{
+			ProductComposition: "00",
+			ProductForm:        "00",
+			TitleDetail: response.ONIXTitleDetail{
+				TitleType:         "01",
+				TitleElementLevel: "01",
+				TitleText:         book.Title,
+			},
+		},
+		ProductSupply: response.ONIXProductSupply{
+			SupplyDetail: response.ONIXSupplyDetail{
+				SupplierRole:        "00",
+				SupplierName:        o.settings.sender,
+				ProductAvailability: "20",
+				Price: response.ONIXPrice{
+					PriceType:    "01",
+					PriceAmount:  book.Price.String(),
+					CurrencyCode: o.settings.currency,
+				},
+			},
+		},
+	}
+
+	isbn := strings.NewReplacer("-", "", " ", "").Replace(book.ISBN)
+	switch len(isbn) {
+	case 13:
+		p.ProductIdentifiers = append(p.ProductIdentifiers, response.ONIXProductIdentifier{ProductIDType: "15", IDValue: isbn})
+	case 10:
+		p.ProductIdentifiers = append(p.ProductIdentifiers, response.ONIXProductIdentifier{ProductIDType: "02", IDValue: isbn})
+	}
+
+	if book.AuthorName != "" {
+		contributor := response.ONIXContributor{
+			SequenceNumber:  1,
+			ContributorRole: "A01",
+			PersonName:      book.AuthorName,
+		}
+		if book.AuthorDob != nil {
+			contributor.ContributorDate = &response.ONIXContributorDate{
+				ContributorDateRole: "50",
+				Date:                response.ONIXDate{Format: "00", Value: book.AuthorDob.Format("20060102")},
+			}
+		}
+		p.DescriptiveDetail.Contributors = []response.ONIXContributor{contributor}
+	}
+
+	if book.Description != "" {
+		p.CollateralDetail = &response.ONIXCollateralDetail{
+			TextContent: response.ONIXTextContent{TextType: "03", ContentAudience: "00", Text: book.Description},
+		}
+	}
+
+	return p
+}
@@ -5,18 +5,29 @@ import (
 	"errors"
 	"fmt"
 	"github.com/go-chi/chi/v5"
+	"github.com/shopspring/decimal"
 	"github.com/vlaship/book-catalog-go/internal/app/dto"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/request"
 	"github.com/vlaship/book-catalog-go/internal/app/types"
 	"github.com/vlaship/book-catalog-go/internal/apperr"
 	"github.com/vlaship/book-catalog-go/internal/decoder"
+	"github.com/vlaship/book-catalog-go/internal/isbn"
 	"github.com/vlaship/book-catalog-go/internal/validation"
+	"golang.org/x/text/language"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 )
 
 const (
 	headerContentType = "Content-Type"
 	applicationJSON   = "application/json"
 	extractParam      = "Extract param"
+	headerETag        = "ETag"
+	headerIfMatch     = "If-Match"
+	headerAcceptLang  = "Accept-Language"
+	defaultLimit      = 20
 )
 
 // encode is a helper function to encode JSON responses
@@ -36,6 +47,41 @@ func encode(w http.ResponseWriter, resource any) error {
 	return nil
 }
 
+// setETag is a helper function to set the entity version as ETag header
+func setETag(w http.ResponseWriter, version int64) {
+	w.Header().Set(headerETag, strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// preferredLanguage is a helper function to get the most preferred language tag of Accept-Language header,
+// it is empty when the header is absent or malformed
+func preferredLanguage(r *http.Request) string {
+	tags, _, err := language.ParseAcceptLanguage(r.Header.Get(headerAcceptLang))
+	if err != nil || len(tags) == 0 {
+		return ""
+	}
+
+	return tags[0].String()
+}
+
+// getIfMatch is a helper function to get the expected entity version from If-Match header,
+// zero means the header is absent or "*" and the write is unconditional
+func getIfMatch(r *http.Request) (int64, error) {
+	h := strings.TrimSpace(r.Header.Get(headerIfMatch))
+	if h == "" || h == "*" {
+		return 0, nil
+	}
+
+	version, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(h, "W/"), `"`), 10, 64)
+	if err != nil || version <= 0 {
+		return 0, apperr.ErrBadRequest.WithFunc(
+			apperr.WithDetail(fmt.Sprintf("invalid %s header %v", headerIfMatch, h)),
+			apperr.WithTitle(extractParam),
+		)
+	}
+
+	return version, nil
+}
+
 // getBookID is a helper function to get bookID from request
 func getBookID(r *http.Request) (types.ID, error) {
 	param := chi.URLParam(r, "bookID")
@@ -50,6 +96,34 @@ func getBookID(r *http.Request) (types.ID, error) {
 	return bookID, nil
 }
 
+// getUserID is a helper function to get userID from request
+func getUserID(r *http.Request) (types.UserID, error) {
+	param := chi.URLParam(r, "userID")
+	userID, err := types.NewUserID(param)
+	if err != nil {
+		return 0, apperr.ErrBadRequest.WithFunc(
+			apperr.WithDetail(fmt.Sprintf("invalid userID %v", param)),
+			apperr.WithTitle(extractParam),
+		)
+	}
+
+	return userID, nil
+}
+
+// getISBN is a helper function to get normalized ISBN from request
+func getISBN(r *http.Request) (string, error) {
+	param := chi.URLParam(r, "isbn")
+	isbn13, ok := isbn.Normalize(param)
+	if !ok {
+		return "", apperr.ErrBadRequest.WithFunc(
+			apperr.WithDetail(fmt.Sprintf("invalid isbn %v", param)),
+			apperr.WithTitle(extractParam),
+		)
+	}
+
+	return isbn13, nil
+}
+
 // getAuthorID is a helper function to get authorID from request
 func getAuthorID(r *http.Request) (types.ID, error) {
 	param := chi.URLParam(r, "authorID")
@@ -64,6 +138,94 @@ func getAuthorID(r *http.Request) (types.ID, error) {
 	return authorID, nil
 }
 
+// getBookFilter is a helper function to get book filter from query params
+func getBookFilter(r *http.Request, v validation.Validator) (*request.BookFilter, error) {
+	q := r.URL.Query()
+
+	limit, err := getQueryParam(q, "limit", strconv.Atoi)
+	if err != nil {
+		return nil, err
+	}
+	authorID, err := getQueryParam(q, "author_id", types.NewID)
+	if err != nil {
+		return nil, err
+	}
+	minPrice, err := getQueryParam(q, "min_price", decimal.NewFromString)
+	if err != nil {
+		return nil, err
+	}
+	maxPrice, err := getQueryParam(q, "max_price", decimal.NewFromString)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &request.BookFilter{
+		BookExpand: request.BookExpand{
+			Expand: getQueryList(q, "expand"),
+		},
+		Limit:    defaultLimit,
+		Cursor:   q.Get("cursor"),
+		AuthorID: authorID,
+		MinPrice: minPrice,
+		MaxPrice: maxPrice,
+		Title:    q.Get("title"),
+		Sort:     q.Get("sort"),
+	}
+	if limit != nil {
+		req.Limit = *limit
+	}
+
+	if err = v.Struct(req); err != nil {
+		return nil, apperr.ErrValidationRequest.WithFunc(apperr.WithDetail(err.Error()))
+	}
+
+	return req, nil
+}
+
+// getBookExpand is a helper function to get book expand from query params
+func getBookExpand(r *http.Request, v validation.Validator) (*request.BookExpand, error) {
+	req := &request.BookExpand{
+		Expand: getQueryList(r.URL.Query(), "expand"),
+	}
+
+	if err := v.Struct(req); err != nil {
+		return nil, apperr.ErrValidationRequest.WithFunc(apperr.WithDetail(err.Error()))
+	}
+
+	return req, nil
+}
+
+// getQueryList is a helper function to get comma separated or repeated query param
+func getQueryList(q url.Values, name string) []string {
+	var list []string
+	for _, param := range q[name] {
+		for _, v := range strings.Split(param, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				list = append(list, v)
+			}
+		}
+	}
+	return list
+}
+
+// getQueryParam is a helper function to get optional query param, nil if param is absent
+func getQueryParam[T any](q url.Values, name string, parse func(string) (T, error)) (*T, error) {
+	param := q.Get(name)
+	if param == "" {
+		return nil, nil //nolint:nilnil // absent param
+	}
+
+	value, err := parse(param)
+	if err != nil {
+		return nil, apperr.ErrBadRequest.WithFunc(
+			apperr.WithDetail(fmt.Sprintf("invalid %s %v", name, param)),
+			apperr.WithTitle(extractParam),
+		)
+	}
+
+	return &value, nil
+}
+
 // addTitle adds title to problem
 func addTitle(err error, title string) error {
 	var appError apperr.AppError
@@ -0,0 +1,199 @@
+package controller
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/request"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/response"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"github.com/vlaship/book-catalog-go/internal/decoder"
+	"github.com/vlaship/book-catalog-go/internal/httphandling"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+	mw "github.com/vlaship/book-catalog-go/internal/router/middleware"
+	"github.com/vlaship/book-catalog-go/internal/validation"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	importPath = "/v1/import"
+	// importMaxBytes limits the size of an uploaded catalog
+	importMaxBytes = 256 << 20
+	// importBatchSize is a number of rows inserted in one transaction
+	importBatchSize = 1000
+)
+
+// Importer is an interface for bulk import of books and authors
+//
+//go:generate mockgen -destination=../../../test/mock/controller/mock-importer.go -package=mock . Importer
+type Importer interface {
+	ImportBooks(ctx context.Context, rows []request.ImportBook, dryRun bool) (*response.ImportReport, error)
+}
+
+// ImportController is a controller for catalog import
+type ImportController struct {
+	importer Importer
+	valid    validation.Validator
+	handler  httphandling.HTTPErrorHandler
+	editor   func(next http.Handler) http.Handler
+	rows     func(next http.Handler) http.Handler
+	log      logger.Logger
+}
+
+// NewImportController creates new import controller
+func NewImportController(
+	importer Importer,
+	valid validation.Validator,
+	handler httphandling.HTTPErrorHandler,
+	log logger.Logger,
+) *ImportController {
+	return &ImportController{
+		importer: importer,
+		valid:    valid,
+		handler:  handler,
+		editor:   mw.NewRoleMiddleware(handler).RequireRole(model.UserRoleEditor),
+		rows:     mw.NewContentTypeMiddleware(handler).AllowContentType("text/csv", "application/x-ndjson", "application/jsonl"),
+		log:      log.New("ImportController"),
+	}
+}
+
+// RegisterRoutes registers routes
+func (ctrl *ImportController) RegisterRoutes(router chi.Router) {
+	ctrl.log.Trc().Msg("RegisterRoutes")
+
+	router.With(ctrl.editor, ctrl.rows).Post(importPath, ctrl.handler.HandlerError(ctrl.Import))
+}
+
+// Import imports books and authors from CSV with a header or from NDJSON,
+// columns are title, description, isbn, price and author_id or author_name with author_dob,
+// rows are inserted in batches and invalid rows are reported and skipped
+// @Summary Import books and authors
+// @Tags Import
+// @Security BearerAuth
+// @Accept text/csv,application/x-ndjson,application/jsonl
+// @Produce      json
+// @Param dry_run query bool false "Validate rows without inserting"
+// @Param rows body string true "CSV with a header or one JSON object per line"
+// @Success 200 {object} response.ImportReport
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 415 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /v1/import [post]
+func (ctrl *ImportController) Import(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("Import")
+
+	dryRun, err := getQueryParam(r.URL.Query(), "dry_run", strconv.ParseBool)
+	if err != nil {
+		return err
+	}
+
+	rows, err := decoder.NewRowReader(w, r, importMaxBytes)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+
+	// a large upload takes longer than the server read timeout, and the report is written after the whole import
+	rc := http.NewResponseController(w)
+	if err = rc.SetReadDeadline(time.Time{}); err != nil {
+		ctrl.log.Wrn().Err(err).Ctx(r.Context()).Msg("failed to clear read deadline")
+	}
+	if err = rc.SetWriteDeadline(time.Time{}); err != nil {
+		ctrl.log.Wrn().Err(err).Ctx(r.Context()).Msg("failed to clear write deadline")
+	}
+
+	report := &response.ImportReport{
+		DryRun: dryRun != nil && *dryRun,
+		Errors: []response.ImportError{},
+	}
+	batch := make([]request.ImportBook, 0, importBatchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		res, err := ctrl.importer.ImportBooks(r.Context(), batch, report.DryRun)
+		if err != nil {
+			return addTitle(err, "Problem importing catalog")
+		}
+		report.Imported += res.Imported
+		report.AuthorsCreated += res.AuthorsCreated
+		report.Errors = append(report.Errors, res.Errors...)
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		var row request.ImportRow
+		line, err := rows.Next(&row)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var rowErr *decoder.RowError
+		if err != nil && !errors.As(err, &rowErr) {
+			// the body can not be read further, the rows before are already imported
+			report.Errors = append(report.Errors, response.ImportError{Line: line, Error: err.Error()})
+			break
+		}
+		report.Total++
+		if rowErr != nil {
+			report.Errors = append(report.Errors, response.ImportError{Line: line, Error: rowErr.Err.Error()})
+			continue
+		}
+
+		book, err := ctrl.parse(&row, line)
+		if err != nil {
+			report.Errors = append(report.Errors, response.ImportError{Line: line, Error: err.Error()})
+			continue
+		}
+
+		batch = append(batch, *book)
+		if len(batch) == importBatchSize {
+			if err = flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err = flush(); err != nil {
+		return err
+	}
+
+	// errors of rows rejected on insert come after the parse errors of later rows
+	slices.SortStableFunc(report.Errors, func(a, b response.ImportError) int { return cmp.Compare(a.Line, b.Line) })
+
+	return encode(w, report)
+}
+
+// parse converts the row and validates it with the rules of book and author creation
+func (ctrl *ImportController) parse(row *request.ImportRow, line int) (*request.ImportBook, error) {
+	book, err := row.Parse(line)
+	if err != nil {
+		return nil, err
+	}
+
+	if book.Author == nil {
+		if err = ctrl.valid.Struct(&book.Book); err != nil {
+			return nil, err
+		}
+		return book, nil
+	}
+
+	// the author is resolved by name on import
+	if err = ctrl.valid.StructExcept(&book.Book, "AuthorID"); err != nil {
+		return nil, err
+	}
+	if err = ctrl.valid.Struct(book.Author); err != nil {
+		return nil, err
+	}
+
+	return book, nil
+}
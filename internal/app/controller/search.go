@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"context"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/request"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/response"
+	"github.com/vlaship/book-catalog-go/internal/apperr"
+	"github.com/vlaship/book-catalog-go/internal/httphandling"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/validation"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const searchPath = "/v1/search"
+
+// SearchReader is an interface for full-text search reader
+//
+//go:generate mockgen -destination=../../../test/mock/controller/mock-search-reader.go -package=mock . SearchReader
+type SearchReader interface {
+	Search(ctx context.Context, req *request.Search) ([]response.SearchHit, error)
+}
+
+// SearchController is a controller for full-text search
+type SearchController struct {
+	reader  SearchReader
+	valid   validation.Validator
+	handler httphandling.HTTPErrorHandler
+	log     logger.Logger
+}
+
+// NewSearchController creates new search controller
+func NewSearchController(
+	reader SearchReader,
+	valid validation.Validator,
+	handler httphandling.HTTPErrorHandler,
+	log logger.Logger,
+) *SearchController {
+	return &SearchController{
+		reader:  reader,
+		valid:   valid,
+		handler: handler,
+		log:     log.New("SearchController"),
+	}
+}
+
+// RegisterRoutes registers search routes
+func (ctrl *SearchController) RegisterRoutes(router chi.Router) {
+	ctrl.log.Trc().Msg("RegisterRoutes")
+
+	router.Get(searchPath, ctrl.handler.HandlerError(ctrl.Search))
+}
+
+// Search searches books and authors
+// @Summary Search books and authors
+// @Tags Search
+// @Security BearerAuth
+// @Produce      json
+// @Param q query string true "Search query"
+// @Param limit query int false "Max number of hits" minimum(1) maximum(100) default(20)
+// @Success 200 {array} response.SearchHit
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /v1/search [get]
+func (ctrl *SearchController) Search(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("Search")
+
+	q := r.URL.Query()
+	limit, err := getQueryParam(q, "limit", strconv.Atoi)
+	if err != nil {
+		return err
+	}
+
+	req := &request.Search{
+		Query: q.Get("q"),
+		Limit: defaultLimit,
+	}
+	if limit != nil {
+		req.Limit = *limit
+	}
+	if err = ctrl.valid.Struct(req); err != nil {
+		return apperr.ErrValidationRequest.WithFunc(apperr.WithDetail(err.Error()))
+	}
+
+	res, err := ctrl.reader.Search(r.Context(), req)
+	if err != nil {
+		return addTitle(err, "Problem searching")
+	}
+
+	return encode(w, res)
+}
@@ -0,0 +1,270 @@
+package controller
+
+import (
+	"context"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/request"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/response"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"github.com/vlaship/book-catalog-go/internal/apperr"
+	"github.com/vlaship/book-catalog-go/internal/httphandling"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+	mw "github.com/vlaship/book-catalog-go/internal/router/middleware"
+	"github.com/vlaship/book-catalog-go/internal/validation"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// TrashHandler is an interface for listing, restoring and purging soft deleted books and authors
+//
+//go:generate mockgen -destination=../../../test/mock/controller/mock-trash-handler.go -package=mock . TrashHandler
+type TrashHandler interface {
+	GetTrashedBooks(ctx context.Context, req *request.TrashFilter) (*response.Page[response.TrashedBook], error)
+	RestoreBook(ctx context.Context, bookID types.ID) error
+	PurgeBook(ctx context.Context, bookID types.ID) error
+	GetTrashedAuthors(ctx context.Context, req *request.TrashFilter) (*response.Page[response.TrashedAuthor], error)
+	RestoreAuthor(ctx context.Context, authorID types.ID) error
+	PurgeAuthor(ctx context.Context, authorID types.ID) error
+}
+
+// TrashController is a controller for soft deleted books and authors
+type TrashController struct {
+	trash   TrashHandler
+	valid   validation.Validator
+	handler httphandling.HTTPErrorHandler
+	admin   func(next http.Handler) http.Handler
+	log     logger.Logger
+}
+
+// NewTrashController creates new trash controller
+func NewTrashController(
+	trash TrashHandler,
+	valid validation.Validator,
+	handler httphandling.HTTPErrorHandler,
+	log logger.Logger,
+) *TrashController {
+	return &TrashController{
+		trash:   trash,
+		valid:   valid,
+		handler: handler,
+		admin:   mw.NewRoleMiddleware(handler).RequireRole(model.UserRoleAdmin),
+		log:     log.New("TrashController"),
+	}
+}
+
+// RegisterRoutes registers routes
+func (ctrl *TrashController) RegisterRoutes(router chi.Router) {
+	ctrl.log.Trc().Msg("RegisterRoutes")
+
+	router.Group(func(r chi.Router) {
+		r.Use(ctrl.admin)
+
+		r.Get(bookPath+"/trash", ctrl.handler.HandlerError(ctrl.GetTrashedBooks))
+		r.Post(bookPath+"/{bookID}/restore", ctrl.handler.HandlerError(ctrl.RestoreBook))
+		r.Delete(bookPath+"/{bookID}/purge", ctrl.handler.HandlerError(ctrl.PurgeBook))
+
+		r.Get(authorPath+"/trash", ctrl.handler.HandlerError(ctrl.GetTrashedAuthors))
+		r.Post(authorPath+"/{authorID}/restore", ctrl.handler.HandlerError(ctrl.RestoreAuthor))
+		r.Delete(authorPath+"/{authorID}/purge", ctrl.handler.HandlerError(ctrl.PurgeAuthor))
+	})
+}
+
+// GetTrashedBooks gets page of deleted books
+// @Summary Get deleted books
+// @Tags Trash
+// @Security BearerAuth
+// @Produce      json
+// @Param limit query int false "Page size" minimum(1) maximum(100) default(20)
+// @Param cursor query string false "Cursor of the next page"
+// @Success 200 {object} response.Page[response.TrashedBook]
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /v1/book/trash [get]
+func (ctrl *TrashController) GetTrashedBooks(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("GetTrashedBooks")
+
+	req, err := ctrl.getTrashFilter(r)
+	if err != nil {
+		return err
+	}
+
+	res, err := ctrl.trash.GetTrashedBooks(r.Context(), req)
+	if err != nil {
+		return addTitle(err, "Problem getting deleted books")
+	}
+
+	return encode(w, res)
+}
+
+// RestoreBook restores a deleted book
+// @Summary Restore a deleted book
+// @Tags Trash
+// @Security BearerAuth
+// @Param bookID path int true "Book ID"
+// @Success 200 "OK"
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 404 {object} response.ProblemDetail
+// @Failure 409 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /v1/book/{bookID}/restore [post]
+func (ctrl *TrashController) RestoreBook(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("RestoreBook")
+
+	bookID, err := getBookID(r)
+	if err != nil {
+		return err
+	}
+
+	if err = ctrl.trash.RestoreBook(r.Context(), bookID); err != nil {
+		return addTitle(err, "Problem restoring book")
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+// PurgeBook permanently deletes a deleted book
+// @Summary Purge a deleted book
+// @Tags Trash
+// @Security BearerAuth
+// @Param bookID path int true "Book ID"
+// @Success 200 "OK"
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 404 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /v1/book/{bookID}/purge [delete]
+func (ctrl *TrashController) PurgeBook(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("PurgeBook")
+
+	bookID, err := getBookID(r)
+	if err != nil {
+		return err
+	}
+
+	if err = ctrl.trash.PurgeBook(r.Context(), bookID); err != nil {
+		return addTitle(err, "Problem purging book")
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+// GetTrashedAuthors gets page of deleted authors
+// @Summary Get deleted authors
+// @Tags Trash
+// @Security BearerAuth
+// @Produce      json
+// @Param limit query int false "Page size" minimum(1) maximum(100) default(20)
+// @Param cursor query string false "Cursor of the next page"
+// @Success 200 {object} response.Page[response.TrashedAuthor]
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /v1/author/trash [get]
+func (ctrl *TrashController) GetTrashedAuthors(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("GetTrashedAuthors")
+
+	req, err := ctrl.getTrashFilter(r)
+	if err != nil {
+		return err
+	}
+
+	res, err := ctrl.trash.GetTrashedAuthors(r.Context(), req)
+	if err != nil {
+		return addTitle(err, "Problem getting deleted authors")
+	}
+
+	return encode(w, res)
+}
+
+// RestoreAuthor restores a deleted author, the deleted books of the author are not restored
+// @Summary Restore a deleted author
+// @Tags Trash
+// @Security BearerAuth
+// @Param authorID path int true "Author ID"
+// @Success 200 "OK"
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 404 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /v1/author/{authorID}/restore [post]
+func (ctrl *TrashController) RestoreAuthor(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("RestoreAuthor")
+
+	authorID, err := getAuthorID(r)
+	if err != nil {
+		return err
+	}
+
+	if err = ctrl.trash.RestoreAuthor(r.Context(), authorID); err != nil {
+		return addTitle(err, "Problem restoring author")
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+// PurgeAuthor permanently deletes a deleted author together with the deleted books of the author
+// @Summary Purge a deleted author
+// @Tags Trash
+// @Security BearerAuth
+// @Param authorID path int true "Author ID"
+// @Success 200 "OK"
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 404 {object} response.ProblemDetail
+// @Failure 409 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /v1/author/{authorID}/purge [delete]
+func (ctrl *TrashController) PurgeAuthor(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("PurgeAuthor")
+
+	authorID, err := getAuthorID(r)
+	if err != nil {
+		return err
+	}
+
+	if err = ctrl.trash.PurgeAuthor(r.Context(), authorID); err != nil {
+		return addTitle(err, "Problem purging author")
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+// getTrashFilter reads and validates paging query params
+func (ctrl *TrashController) getTrashFilter(r *http.Request) (*request.TrashFilter, error) {
+	q := r.URL.Query()
+	limit, err := getQueryParam(q, "limit", strconv.Atoi)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &request.TrashFilter{
+		Limit:  defaultLimit,
+		Cursor: q.Get("cursor"),
+	}
+	if limit != nil {
+		req.Limit = *limit
+	}
+	if err = ctrl.valid.Struct(req); err != nil {
+		return nil, apperr.ErrValidationRequest.WithFunc(apperr.WithDetail(err.Error()))
+	}
+
+	return req, nil
+}
@@ -23,12 +23,26 @@ type UserReader interface {
 //go:generate mockgen -destination=../../../test/mock/controller/mock-user-writer.go -package=mock . UserWriter
 type UserWriter interface {
 	UpdateInfo(ctx context.Context, req *request.UserData) error
+	ChangePassword(ctx context.Context, req *request.ChangePassword) error
+	ChangeEmail(ctx context.Context, req *request.ChangeEmail) error
+	ConfirmEmail(ctx context.Context, req *request.ConfirmEmail) error
+	DeleteAccount(ctx context.Context) error
+}
+
+// TwoFactorHandler is an interface for two-factor authentication of user
+//
+//go:generate mockgen -destination=../../../test/mock/controller/mock-two-factor-handler.go -package=mock . TwoFactorHandler
+type TwoFactorHandler interface {
+	EnrollTwoFactor(ctx context.Context) (*response.TwoFactorEnrollment, error)
+	ConfirmTwoFactor(ctx context.Context, req *request.TwoFactorCode) error
+	DisableTwoFactor(ctx context.Context, req *request.DisableTwoFactor) error
 }
 
 // UserController is a controller for user
 type UserController struct {
 	reader UserReader
 	writer UserWriter
+	tf     TwoFactorHandler
 	valid  validation.Validator
 	eh     httphandling.HTTPErrorHandler
 	log    logger.Logger
@@ -38,6 +52,7 @@ type UserController struct {
 func NewUserController(
 	reader UserReader,
 	writer UserWriter,
+	tf TwoFactorHandler,
 	valid validation.Validator,
 	eh httphandling.HTTPErrorHandler,
 	log logger.Logger,
@@ -45,6 +60,7 @@ func NewUserController(
 	return &UserController{
 		reader: reader,
 		writer: writer,
+		tf:     tf,
 		valid:  valid,
 		eh:     eh,
 		log:    log.New("UserController"),
@@ -58,6 +74,13 @@ func (ctrl *UserController) RegisterRoutes(router chi.Router) {
 	router.Route("/user", func(r chi.Router) {
 		r.Get("/", ctrl.eh.HandlerError(ctrl.GetUser))
 		r.Put("/info", ctrl.eh.HandlerError(ctrl.UpdateInfo))
+		r.Post("/password", ctrl.eh.HandlerError(ctrl.ChangePassword))
+		r.Post("/email", ctrl.eh.HandlerError(ctrl.ChangeEmail))
+		r.Post("/email/confirm", ctrl.eh.HandlerError(ctrl.ConfirmEmail))
+		r.Delete("/", ctrl.eh.HandlerError(ctrl.DeleteAccount))
+		r.Post("/2fa/enroll", ctrl.eh.HandlerError(ctrl.EnrollTwoFactor))
+		r.Post("/2fa/confirm", ctrl.eh.HandlerError(ctrl.ConfirmTwoFactor))
+		r.Post("/2fa/disable", ctrl.eh.HandlerError(ctrl.DisableTwoFactor))
 	})
 }
 
@@ -106,3 +129,200 @@ func (ctrl *UserController) UpdateInfo(w http.ResponseWriter, r *http.Request) e
 
 	return nil
 }
+
+// ChangePassword changes password of user
+// @Summary Change password
+// @Description The current password is required. The user is signed out of every session.
+// @Description The new password must meet the password policy, the failed rules are listed in the violations.
+// @Tags User
+// @Security BearerAuth
+// @Accept  json
+// @Param password body request.ChangePassword true "Passwords"
+// @Success 200 "OK"
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /user/password [post]
+func (ctrl *UserController) ChangePassword(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("ChangePassword")
+
+	req, err := decode(w, r, &request.ChangePassword{}, ctrl.valid)
+	if err != nil {
+		return err
+	}
+
+	if err = ctrl.writer.ChangePassword(r.Context(), req); err != nil {
+		return addTitle(err, "Problem changing password")
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+// ChangeEmail mails otp confirming the change to the new email
+// @Summary Change email
+// @Description The email is changed once the otp mailed to the new email is confirmed.
+// @Tags User
+// @Security BearerAuth
+// @Accept  json
+// @Param email body request.ChangeEmail true "New email"
+// @Success 200 "OK"
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /user/email [post]
+func (ctrl *UserController) ChangeEmail(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("ChangeEmail")
+
+	req, err := decode(w, r, &request.ChangeEmail{}, ctrl.valid)
+	if err != nil {
+		return err
+	}
+
+	if err = ctrl.writer.ChangeEmail(r.Context(), req); err != nil {
+		return addTitle(err, "Problem changing email")
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+// ConfirmEmail confirms email change
+// @Summary Confirm email change
+// @Description The new email becomes the username used to sign in.
+// @Tags User
+// @Security BearerAuth
+// @Accept  json
+// @Param email body request.ConfirmEmail true "New email and otp"
+// @Success 200 "OK"
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 429 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /user/email/confirm [post]
+func (ctrl *UserController) ConfirmEmail(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("ConfirmEmail")
+
+	req, err := decode(w, r, &request.ConfirmEmail{}, ctrl.valid)
+	if err != nil {
+		return err
+	}
+
+	if err = ctrl.writer.ConfirmEmail(r.Context(), req); err != nil {
+		return addTitle(err, "Problem confirming email")
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+// DeleteAccount deletes account of user
+// @Summary Delete account
+// @Description The personal data of the user is erased and the user is signed out of every session.
+// @Tags User
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /user [delete]
+func (ctrl *UserController) DeleteAccount(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("DeleteAccount")
+
+	if err := ctrl.writer.DeleteAccount(r.Context()); err != nil {
+		return addTitle(err, "Problem deleting account")
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// EnrollTwoFactor starts two-factor authentication enrollment
+// @Summary Enroll two-factor authentication
+// @Description Returns the otpauth URI for the authenticator app and the recovery codes, they are shown only once.
+// @Description Two-factor authentication is enabled once the enrollment is confirmed with a code.
+// @Tags User
+// @Security BearerAuth
+// @Produce  json
+// @Success 200 {object} response.TwoFactorEnrollment
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 409 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /user/2fa/enroll [post]
+func (ctrl *UserController) EnrollTwoFactor(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("EnrollTwoFactor")
+
+	res, err := ctrl.tf.EnrollTwoFactor(r.Context())
+	if err != nil {
+		return addTitle(err, "Problem enrolling two-factor authentication")
+	}
+
+	return encode(w, res)
+}
+
+// ConfirmTwoFactor enables two-factor authentication
+// @Summary Confirm two-factor authentication
+// @Tags User
+// @Security BearerAuth
+// @Accept  json
+// @Param code body request.TwoFactorCode true "Code of the authenticator app"
+// @Success 200 "OK"
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 409 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /user/2fa/confirm [post]
+func (ctrl *UserController) ConfirmTwoFactor(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("ConfirmTwoFactor")
+
+	req, err := decode(w, r, &request.TwoFactorCode{}, ctrl.valid)
+	if err != nil {
+		return err
+	}
+
+	if err = ctrl.tf.ConfirmTwoFactor(r.Context(), req); err != nil {
+		return addTitle(err, "Problem confirming two-factor authentication")
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+// DisableTwoFactor disables two-factor authentication
+// @Summary Disable two-factor authentication
+// @Tags User
+// @Security BearerAuth
+// @Accept  json
+// @Param password body request.DisableTwoFactor true "Current password"
+// @Success 200 "OK"
+// @Failure 400 {object} response.ProblemDetail
+// @Failure 401 {object} response.ProblemDetail
+// @Failure 403 {object} response.ProblemDetail
+// @Failure 500 {object} response.ProblemDetail
+// @Router /user/2fa/disable [post]
+func (ctrl *UserController) DisableTwoFactor(w http.ResponseWriter, r *http.Request) error {
+	ctrl.log.Trc().Ctx(r.Context()).Msg("DisableTwoFactor")
+
+	req, err := decode(w, r, &request.DisableTwoFactor{}, ctrl.valid)
+	if err != nil {
+		return err
+	}
+
+	if err = ctrl.tf.DisableTwoFactor(r.Context(), req); err != nil {
+		return addTitle(err, "Problem disabling two-factor authentication")
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	return nil
+}
@@ -6,12 +6,14 @@ package controller
 import (
 	"github.com/google/wire"
 	"github.com/vlaship/book-catalog-go/internal/app/facade"
+	"github.com/vlaship/book-catalog-go/internal/config"
 	"github.com/vlaship/book-catalog-go/internal/httphandling"
 	"github.com/vlaship/book-catalog-go/internal/logger"
 	"github.com/vlaship/book-catalog-go/internal/validation"
 )
 
 func Wire(
+	cfg *config.Config,
 	facades *facade.Facades,
 	validator validation.Validator,
 	handler httphandling.HTTPErrorHandler,
@@ -22,6 +24,12 @@ func Wire(
 		NewUserController,
 		NewBookController,
 		NewAuthorController,
+		NewSearchController,
+		NewAuditController,
+		NewTrashController,
+		NewImportController,
+		NewExportController,
+		NewAdminUserController,
 		AuthProvider,
 		UserReaderProvider,
 		UserWriterProvider,
@@ -31,6 +39,13 @@ func Wire(
 		BookWriterProvider,
 		AuthorReaderProvider,
 		AuthorWriterProvider,
+		SearchReaderProvider,
+		AuditReaderProvider,
+		TrashHandlerProvider,
+		ImporterProvider,
+		ExporterProvider,
+		UserAdminProvider,
+		TwoFactorHandlerProvider,
 		wire.Struct(new(Controllers), "*"),
 	)
 	return &Controllers{}
@@ -51,6 +66,16 @@ func UserWriterProvider(facades *facade.Facades) UserWriter {
 	return facades.UserFacade
 }
 
+// UserAdminProvider is a provider for UserAdmin
+func UserAdminProvider(facades *facade.Facades) UserAdmin {
+	return facades.AdminUserFacade
+}
+
+// TwoFactorHandlerProvider is a provider for TwoFactorHandler
+func TwoFactorHandlerProvider(facades *facade.Facades) TwoFactorHandler {
+	return facades.UserFacade
+}
+
 // ActivatorProvider is a provider for Activator
 func ActivatorProvider(facades *facade.Facades) Activator {
 	return facades.AuthFacade
@@ -80,3 +105,28 @@ func AuthorReaderProvider(facades *facade.Facades) AuthorReader {
 func AuthorWriterProvider(facades *facade.Facades) AuthorWriter {
 	return facades.AuthorFacade
 }
+
+// SearchReaderProvider is a provider for SearchReader
+func SearchReaderProvider(facades *facade.Facades) SearchReader {
+	return facades.SearchFacade
+}
+
+// AuditReaderProvider is a provider for AuditReader
+func AuditReaderProvider(facades *facade.Facades) AuditReader {
+	return facades.AuditFacade
+}
+
+// TrashHandlerProvider is a provider for TrashHandler
+func TrashHandlerProvider(facades *facade.Facades) TrashHandler {
+	return facades.TrashFacade
+}
+
+// ImporterProvider is a provider for Importer
+func ImporterProvider(facades *facade.Facades) Importer {
+	return facades.ImportFacade
+}
+
+// ExporterProvider is a provider for Exporter
+func ExporterProvider(facades *facade.Facades) Exporter {
+	return facades.BookFacade
+}
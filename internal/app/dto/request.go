@@ -5,7 +5,11 @@ import (
 )
 
 type Request interface {
-	Entity | Auth | UserData
+	Entity | Auth | User
+}
+
+type User interface {
+	UserData | ChangeEmail | ConfirmEmail | UserPatch | TwoFactorCode | DisableTwoFactor
 }
 
 type Entity interface {
@@ -13,5 +17,5 @@ type Entity interface {
 }
 
 type Auth interface {
-	Signin | Signup | Activation | ResendActivation | ResetPassword | ChangePassword | ReplacePassword
+	Signin | RefreshToken | Signup | Activation | ResendActivation | ResetPassword | ChangePassword | ReplacePassword | SigninTwoFactor
 }
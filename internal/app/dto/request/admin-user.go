@@ -0,0 +1,16 @@
+package request
+
+// UserFilter request, Email matches a part of the username
+type UserFilter struct {
+	Limit  int    `validate:"min=1,max=100"`
+	Cursor string `validate:"max=512"`
+	Status string `validate:"omitempty,oneof=active not_activated suspended locked"`
+	Email  string `validate:"max=255"`
+}
+
+// UserPatch is a request model for changing the account of user, absent fields are left as they are
+type UserPatch struct {
+	Status *string `json:"status" validate:"omitempty,oneof=active suspended locked" example:"suspended"`
+	Role   *string `json:"role" validate:"omitempty,oneof=reader editor admin" example:"editor"`
+	Plan   *string `json:"plan" validate:"omitempty,max=64" example:"premium"`
+}
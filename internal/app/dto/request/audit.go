@@ -0,0 +1,11 @@
+package request
+
+import "github.com/vlaship/book-catalog-go/internal/app/types"
+
+// AuditFilter request
+type AuditFilter struct {
+	Limit    int       `validate:"min=1,max=100"`
+	Cursor   string    `validate:"max=512"`
+	Entity   string    `validate:"omitempty,oneof=book author"`
+	EntityID *types.ID `validate:"omitempty,min=1"`
+}
@@ -17,12 +17,24 @@ func (s *Signin) String() string {
 	return fmt.Sprintf("Signin{Username: %s, Password: %s}", mask.String(s.Username.String()), mask.String(s.Password.String()))
 }
 
+// RefreshToken request
+type RefreshToken struct {
+	RefreshToken types.Token `json:"refresh_token" validate:"required"`
+}
+
+// String
+func (r *RefreshToken) String() string {
+	return fmt.Sprintf("RefreshToken{RefreshToken: %s}", mask.String(string(r.RefreshToken)))
+}
+
 // Signup request
 type Signup struct {
 	Username  types.Username `json:"username" validate:"required,email"`
-	Password  types.Password `json:"password" validate:"required,min=8,max=64"`
+	Password  types.Password `json:"password" validate:"required,max=64"`
 	Firstname string         `json:"firstname" validate:"required,min=2,max=64"`
 	Lastname  string         `json:"lastname"`
+	// Language is the preferred language of mails, the Accept-Language header is used when it is empty
+	Language string `json:"language" validate:"omitempty,bcp47_language_tag" example:"en"`
 }
 
 // String
@@ -32,12 +44,13 @@ func (s *Signup) String() string {
 
 // Activation request
 type Activation struct {
-	OTP types.Token `json:"otp" validate:"required,min=64,max=64"`
+	Username types.Username `json:"username" validate:"required,email"`
+	OTP      types.Token    `json:"otp" validate:"required,min=64,max=64"`
 }
 
 // String
 func (a *Activation) String() string {
-	return fmt.Sprintf("Activation{Token: %s}", mask.String(string(a.OTP)))
+	return fmt.Sprintf("Activation{Username: %s, Token: %s}", mask.String(a.Username.String()), mask.String(string(a.OTP)))
 }
 
 // ResendActivation request
@@ -62,14 +75,16 @@ func (r *ResetPassword) String() string {
 
 // ReplacePassword request
 type ReplacePassword struct {
+	Username    types.Username `json:"username" validate:"required,email"`
 	OTP         types.Token    `json:"otp" validate:"required,min=64,max=64"`
-	NewPassword types.Password `json:"new_password" validate:"required,min=8,max=64"`
+	NewPassword types.Password `json:"new_password" validate:"required,max=64"`
 }
 
 // String
 func (r *ReplacePassword) String() string {
 	return fmt.Sprintf(
-		"ReplacePassword{OTP: %s, NewPassword: %s}",
+		"ReplacePassword{Username: %s, OTP: %s, NewPassword: %s}",
+		mask.String(r.Username.String()),
 		mask.String(string(r.OTP)),
 		mask.String(r.NewPassword.String()),
 	)
@@ -78,7 +93,7 @@ func (r *ReplacePassword) String() string {
 // ChangePassword request
 type ChangePassword struct {
 	CurrentPassword types.Password `json:"current_password" validate:"required,min=8,max=64"`
-	NewPassword     types.Password `json:"new_password" validate:"required,min=8,max=64"`
+	NewPassword     types.Password `json:"new_password" validate:"required,max=64,nefield=CurrentPassword"`
 }
 
 // String
@@ -89,3 +104,18 @@ func (u *ChangePassword) String() string {
 		mask.String(u.NewPassword.String()),
 	)
 }
+
+// SigninTwoFactor request, the code is a code of the authenticator app or a recovery code
+type SigninTwoFactor struct {
+	ChallengeToken types.Token `json:"challenge_token" validate:"required,max=64"`
+	Code           string      `json:"code" validate:"required,min=6,max=32" example:"123456"`
+}
+
+// String
+func (r *SigninTwoFactor) String() string {
+	return fmt.Sprintf(
+		"SigninTwoFactor{ChallengeToken: %s, Code: %s}",
+		mask.String(string(r.ChallengeToken)),
+		mask.String(r.Code),
+	)
+}
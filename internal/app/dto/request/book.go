@@ -1,12 +1,15 @@
 package request
 
-import "github.com/vlaship/book-catalog-go/internal/app/types"
+import (
+	"github.com/shopspring/decimal"
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+)
 
 // CreateBook request
 type CreateBook struct {
 	Title       string                `json:"title" validate:"required,min=1,max=255"`
 	Description string                `json:"description" validate:"required,min=1,max=255"`
-	ISBN        string                `json:"isbn" validate:"required,min=1,max=255"`
+	ISBN        string                `json:"isbn" example:"978-0-13-419044-0" validate:"required,isbn"`
 	AuthorID    types.ID              `json:"author_id" validate:"required"`
 	Price       types.PositiveDecimal `json:"price" example:"15.99" swaggertype:"primitive,number" validate:"required"`
 }
@@ -15,7 +18,24 @@ type CreateBook struct {
 type UpdateBook struct {
 	Title       string                `json:"title" validate:"required,min=1,max=255"`
 	Description string                `json:"description" validate:"required,min=1,max=255"`
-	ISBN        string                `json:"isbn" validate:"required,min=1,max=255"`
+	ISBN        string                `json:"isbn" example:"978-0-13-419044-0" validate:"required,isbn"`
 	AuthorID    types.ID              `json:"author_id" validate:"required"`
 	Price       types.PositiveDecimal `json:"price" example:"15.99" swaggertype:"primitive,number" validate:"required"`
 }
+
+// BookExpand request
+type BookExpand struct {
+	Expand []string `validate:"dive,oneof=author"`
+}
+
+// BookFilter request
+type BookFilter struct {
+	BookExpand
+	Limit    int `validate:"min=1,max=100"`
+	Cursor   string
+	AuthorID *types.ID
+	MinPrice *decimal.Decimal
+	MaxPrice *decimal.Decimal
+	Title    string `validate:"max=255"`
+	Sort     string `validate:"omitempty,oneof=id -id title -title price -price"`
+}
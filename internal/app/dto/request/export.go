@@ -0,0 +1,13 @@
+package request
+
+// Export formats
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+	ExportFormatONIX   = "onix"
+)
+
+// ExportFormat request
+type ExportFormat struct {
+	Format string `validate:"required,oneof=csv ndjson onix"`
+}
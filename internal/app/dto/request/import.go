@@ -0,0 +1,77 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"time"
+)
+
+var (
+	errNoPrice  = errors.New("price is required")
+	errNoAuthor = errors.New("author_id or author_name is required")
+)
+
+// ImportRow request is a row of CSV or NDJSON catalog import,
+// the author is referenced by author_id or by author_name and created when author_dob is given
+type ImportRow struct {
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	ISBN        string      `json:"isbn"`
+	Price       json.Number `json:"price"`
+	AuthorID    json.Number `json:"author_id"`
+	AuthorName  string      `json:"author_name"`
+	AuthorDob   string      `json:"author_dob"`
+}
+
+// ImportBook request is a parsed import row
+type ImportBook struct {
+	Line   int
+	Book   CreateBook
+	Author *CreateAuthor
+}
+
+// Parse converts the row into book and author requests, the author is nil when it is referenced by ID
+func (r *ImportRow) Parse(line int) (*ImportBook, error) {
+	if r.Price == "" {
+		return nil, errNoPrice
+	}
+	price, err := decimal.NewFromString(r.Price.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid price %q", r.Price)
+	}
+
+	out := &ImportBook{
+		Line: line,
+		Book: CreateBook{
+			Title:       r.Title,
+			Description: r.Description,
+			ISBN:        r.ISBN,
+			Price:       types.PositiveDecimal{Value: price},
+		},
+	}
+
+	switch {
+	case r.AuthorID != "":
+		id, err := types.NewID(r.AuthorID.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid author_id %q", r.AuthorID)
+		}
+		out.Book.AuthorID = id
+	case r.AuthorName != "":
+		out.Author = &CreateAuthor{Name: r.AuthorName}
+		if r.AuthorDob != "" {
+			dob, err := time.Parse(time.DateOnly, r.AuthorDob)
+			if err != nil {
+				return nil, fmt.Errorf("invalid author_dob %q", r.AuthorDob)
+			}
+			out.Author.Dob = types.DateDay{Time: dob}
+		}
+	default:
+		return nil, errNoAuthor
+	}
+
+	return out, nil
+}
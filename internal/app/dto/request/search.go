@@ -0,0 +1,7 @@
+package request
+
+// Search request
+type Search struct {
+	Query string `validate:"required,min=1,max=255"`
+	Limit int    `validate:"min=1,max=100"`
+}
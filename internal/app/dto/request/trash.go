@@ -0,0 +1,7 @@
+package request
+
+// TrashFilter request
+type TrashFilter struct {
+	Limit  int    `validate:"min=1,max=100"`
+	Cursor string `validate:"max=512"`
+}
@@ -2,22 +2,58 @@ package request
 
 import (
 	"fmt"
+	"github.com/vlaship/book-catalog-go/internal/app/types"
 	"github.com/vlaship/go-mask"
 )
 
-// UserData is a request model for user info
+// UserData is a request model for user info, the email is changed by ChangeEmail only
 type UserData struct {
 	FirstName string `json:"firstname" validate:"required,min=2,max=64" example:"John"`
 	LastName  string `json:"lastname" validate:"required,min=2,max=64" example:"Doe"`
-	Email     string `json:"email" validate:"required,email" example:"email@email.com"`
+	Language  string `json:"language" validate:"omitempty,bcp47_language_tag" example:"en"`
 }
 
 // String
 func (ui *UserData) String() string {
 	return fmt.Sprintf(
-		"UserData{FirstName: %s, LastName: %s, Email: %s}",
+		"UserData{FirstName: %s, LastName: %s}",
 		mask.String(ui.FirstName),
 		mask.String(ui.LastName),
-		mask.String(ui.Email),
 	)
 }
+
+// ChangeEmail is a request model for changing email, the new email is confirmed by the otp mailed to it
+type ChangeEmail struct {
+	Email types.Username `json:"email" validate:"required,email,max=255" example:"new@email.com"`
+}
+
+// String
+func (r *ChangeEmail) String() string {
+	return fmt.Sprintf("ChangeEmail{Email: %s}", mask.String(r.Email.String()))
+}
+
+// ConfirmEmail is a request model for confirming email change
+type ConfirmEmail struct {
+	Email types.Username `json:"email" validate:"required,email,max=255" example:"new@email.com"`
+	OTP   types.Token    `json:"otp" validate:"required,min=64,max=64"`
+}
+
+// String
+func (r *ConfirmEmail) String() string {
+	return fmt.Sprintf("ConfirmEmail{Email: %s, OTP: %s}", mask.String(r.Email.String()), mask.String(string(r.OTP)))
+}
+
+// TwoFactorCode is a request model for confirming two-factor authentication enrollment
+type TwoFactorCode struct {
+	Code string `json:"code" validate:"required,len=6,numeric" example:"123456"`
+}
+
+// DisableTwoFactor is a request model for disabling two-factor authentication
+type DisableTwoFactor struct {
+	Password types.Password `json:"password" validate:"required,min=8,max=64"`
+}
+
+// String
+func (r *DisableTwoFactor) String() string {
+	return fmt.Sprintf("DisableTwoFactor{Password: %s}", mask.String(r.Password.String()))
+}
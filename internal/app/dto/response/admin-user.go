@@ -0,0 +1,19 @@
+package response
+
+import (
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"time"
+)
+
+// AdminUser is a response model for user account as seen by admins
+type AdminUser struct {
+	ID        types.UserID   `json:"id" example:"1"`
+	Username  types.Username `json:"username" example:"email@email.com"`
+	Role      string         `json:"role" example:"reader" enums:"reader,editor,admin"`
+	Plan      string         `json:"plan,omitempty" example:"premium"`
+	Status    string         `json:"status" example:"active" enums:"active,not_activated,suspended,locked"`
+	Info      UserInfo       `json:"info"`
+	CreatedAt time.Time      `json:"created_at" example:"2025-01-01T00:00:00Z"`
+	// LockedUntil is set while the user is temporarily locked after failed signins
+	LockedUntil *time.Time `json:"locked_until,omitempty" example:"2025-01-01T00:30:00Z"`
+}
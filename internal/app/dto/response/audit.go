@@ -0,0 +1,20 @@
+package response
+
+import (
+	"encoding/json"
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"time"
+)
+
+// AuditRecord response
+type AuditRecord struct {
+	ID        types.ID        `json:"id" example:"1"`
+	Action    string          `json:"action" example:"update" enums:"create,update,delete,restore,purge"`
+	Entity    string          `json:"entity" example:"book" enums:"book,author"`
+	EntityID  types.ID        `json:"entity_id" example:"1"`
+	UserID    *types.UserID   `json:"user_id,omitempty" example:"1"`
+	RequestID *string         `json:"request_id,omitempty" example:"host/abc-000001"`
+	Before    json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After     json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at" example:"2026-10-17T10:00:00Z"`
+}
@@ -4,10 +4,13 @@ import "github.com/vlaship/book-catalog-go/internal/app/types"
 
 // Signin response
 type Signin struct {
-	AccessToken  types.Token `json:"access_token"`
-	Type         string      `json:"type" example:"Bearer"`
-	ExpiresIn    int64       `json:"expires_in" example:"3600"`
-	RefreshToken types.Token `json:"refresh_token"`
+	AccessToken  types.Token `json:"access_token,omitempty"`
+	Type         string      `json:"type,omitempty" example:"Bearer"`
+	ExpiresIn    int64       `json:"expires_in,omitempty" example:"3600"`
+	RefreshToken types.Token `json:"refresh_token,omitempty"`
+	// ChallengeToken is returned instead of the tokens when the user has two-factor authentication,
+	// it is exchanged for the tokens with a code at /v1/auth/signin/2fa
+	ChallengeToken types.Token `json:"challenge_token,omitempty"`
 }
 
 // Signup response
@@ -9,9 +9,16 @@ type CreateAuthor struct {
 
 // Author response
 type Author struct {
-	ID   types.ID      `json:"id" example:"1"`
-	Name string        `json:"name" example:"John Doe"`
-	Dob  types.DateDay `json:"dob" swaggertype:"primitive,string" example:"2021-01-01"`
+	ID      types.ID      `json:"id" example:"1"`
+	Name    string        `json:"name" example:"John Doe"`
+	Dob     types.DateDay `json:"dob" swaggertype:"primitive,string" example:"2021-01-01"`
+	Version int64         `json:"-"`
+}
+
+// AuthorBooks response
+type AuthorBooks struct {
+	Author Author         `json:"author"`
+	Books  Page[ListBook] `json:"books"`
 }
 
 // ListAuthor response
@@ -12,13 +12,28 @@ type Book struct {
 	ID          types.ID      `json:"id" example:"1"`
 	Title       string        `json:"title" example:"Book Title"`
 	Description string        `json:"description" example:"Book Description"`
-	ISBN        string        `json:"isbn" example:"1234567890"`
+	ISBN        string        `json:"isbn" example:"9780134190440"`
 	AuthorID    types.ID      `json:"author_id" example:"1"`
 	Price       types.Decimal `json:"price" example:"15.99"`
+	Author      *Author       `json:"author,omitempty"`
+	Version     int64         `json:"-"`
 }
 
 // ListBook response
 type ListBook struct {
-	ID    types.ID `json:"id" example:"1"`
-	Title string   `json:"title" example:"Book Title"`
+	ID     types.ID `json:"id" example:"1"`
+	Title  string   `json:"title" example:"Book Title"`
+	Author *Author  `json:"author,omitempty"`
+}
+
+// ExportBook response is a flat book row of catalog export, the columns match the import
+type ExportBook struct {
+	ID          types.ID       `json:"id" example:"1"`
+	Title       string         `json:"title" example:"Book Title"`
+	Description string         `json:"description" example:"Book Description"`
+	ISBN        string         `json:"isbn" example:"9780134190440"`
+	Price       types.Decimal  `json:"price" example:"15.99"`
+	AuthorID    types.ID       `json:"author_id" example:"1"`
+	AuthorName  string         `json:"author_name,omitempty" example:"John Doe"`
+	AuthorDob   *types.DateDay `json:"author_dob,omitempty" swaggertype:"primitive,string" example:"2021-01-01"`
 }
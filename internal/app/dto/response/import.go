@@ -0,0 +1,16 @@
+package response
+
+// ImportReport response
+type ImportReport struct {
+	DryRun         bool          `json:"dry_run" example:"false"`
+	Total          int           `json:"total" example:"3"`
+	Imported       int           `json:"imported" example:"2"`
+	AuthorsCreated int           `json:"authors_created" example:"1"`
+	Errors         []ImportError `json:"errors"`
+}
+
+// ImportError response
+type ImportError struct {
+	Line  int    `json:"line" example:"3"`
+	Error string `json:"error" example:"invalid price \"abc\""`
+}
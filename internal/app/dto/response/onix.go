@@ -0,0 +1,101 @@
+package response
+
+import "encoding/xml"
+
+// ONIX 3.0 reference names, code values are from the ONIX code lists
+const (
+	ONIXNamespace = "http://ns.editeur.org/onix/3.0/reference"
+	ONIXRelease   = "3.0"
+)
+
+// ONIXHeader is the header of ONIX message
+type ONIXHeader struct {
+	XMLName      xml.Name `xml:"Header"`
+	SenderName   string   `xml:"Sender>SenderName"`
+	SentDateTime string   `xml:"SentDateTime"`
+}
+
+// ONIXProduct is a product record of ONIX message
+type ONIXProduct struct {
+	XMLName            xml.Name                `xml:"Product"`
+	RecordReference    string                  `xml:"RecordReference"`
+	NotificationType   string                  `xml:"NotificationType"`
+	ProductIdentifiers []ONIXProductIdentifier `xml:"ProductIdentifier"`
+	DescriptiveDetail  ONIXDescriptiveDetail   `xml:"DescriptiveDetail"`
+	CollateralDetail   *ONIXCollateralDetail   `xml:"CollateralDetail,omitempty"`
+	ProductSupply      ONIXProductSupply       `xml:"ProductSupply"`
+}
+
+// ONIXProductIdentifier is an identifier of ONIX product
+type ONIXProductIdentifier struct {
+	ProductIDType string `xml:"ProductIDType"`
+	IDTypeName    string `xml:"IDTypeName,omitempty"`
+	IDValue       string `xml:"IDValue"`
+}
+
+// ONIXDescriptiveDetail is a description of ONIX product
+type ONIXDescriptiveDetail struct {
+	ProductComposition string            `xml:"ProductComposition"`
+	ProductForm        string            `xml:"ProductForm"`
+	TitleDetail        ONIXTitleDetail   `xml:"TitleDetail"`
+	Contributors       []ONIXContributor `xml:"Contributor"`
+}
+
+// ONIXTitleDetail is a title of ONIX product
+type ONIXTitleDetail struct {
+	TitleType         string `xml:"TitleType"`
+	TitleElementLevel string `xml:"TitleElement>TitleElementLevel"`
+	TitleText         string `xml:"TitleElement>TitleText"`
+}
+
+// ONIXContributor is a contributor of ONIX product
+type ONIXContributor struct {
+	SequenceNumber  int                  `xml:"SequenceNumber"`
+	ContributorRole string               `xml:"ContributorRole"`
+	PersonName      string               `xml:"PersonName"`
+	ContributorDate *ONIXContributorDate `xml:"ContributorDate,omitempty"`
+}
+
+// ONIXContributorDate is a date of ONIX contributor
+type ONIXContributorDate struct {
+	ContributorDateRole string   `xml:"ContributorDateRole"`
+	Date                ONIXDate `xml:"Date"`
+}
+
+// ONIXDate is a date with its format code
+type ONIXDate struct {
+	Format string `xml:"dateformat,attr"`
+	Value  string `xml:",chardata"`
+}
+
+// ONIXCollateralDetail is a supporting content of ONIX product
+type ONIXCollateralDetail struct {
+	TextContent ONIXTextContent `xml:"TextContent"`
+}
+
+// ONIXTextContent is a text of ONIX product
+type ONIXTextContent struct {
+	TextType        string `xml:"TextType"`
+	ContentAudience string `xml:"ContentAudience"`
+	Text            string `xml:"Text"`
+}
+
+// ONIXProductSupply is a supply of ONIX product
+type ONIXProductSupply struct {
+	SupplyDetail ONIXSupplyDetail `xml:"SupplyDetail"`
+}
+
+// ONIXSupplyDetail is a supplier, availability and price of ONIX product
+type ONIXSupplyDetail struct {
+	SupplierRole        string    `xml:"Supplier>SupplierRole"`
+	SupplierName        string    `xml:"Supplier>SupplierName"`
+	ProductAvailability string    `xml:"ProductAvailability"`
+	Price               ONIXPrice `xml:"Price"`
+}
+
+// ONIXPrice is a price of ONIX product
+type ONIXPrice struct {
+	PriceType    string `xml:"PriceType"`
+	PriceAmount  string `xml:"PriceAmount"`
+	CurrencyCode string `xml:"CurrencyCode"`
+}
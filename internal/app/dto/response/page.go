@@ -0,0 +1,7 @@
+package response
+
+// Page response
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJpZCI6MTc5NDk0NzE1NzUxNDUyMjYyNH0"`
+}
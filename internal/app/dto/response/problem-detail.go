@@ -10,6 +10,14 @@ type ProblemDetail struct {
 	Detail    string `json:"detail" example:"Content-Type header is missing"`
 	Timestamp string `json:"timestamp" example:"2021-07-01T15:04:05.999999-07:00"`
 	Instance  string `json:"instance" example:"/api/v1/author"`
+	// Violations lists the failed rules of the request
+	Violations []Violation `json:"violations,omitempty"`
+}
+
+// Violation is a failed rule of the request
+type Violation struct {
+	Rule   string `json:"rule" example:"min_length"`
+	Detail string `json:"detail" example:"password must be at least 8 characters long"`
 }
 
 func (p ProblemDetail) JSON() []byte {
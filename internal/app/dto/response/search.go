@@ -0,0 +1,12 @@
+package response
+
+import "github.com/vlaship/book-catalog-go/internal/app/types"
+
+// SearchHit response
+type SearchHit struct {
+	Kind    string   `json:"kind" example:"book" enums:"book,author"`
+	ID      types.ID `json:"id" example:"1"`
+	Title   string   `json:"title" example:"Book Title"`
+	Snippet string   `json:"snippet" example:"<mark>Book</mark> Title"`
+	Rank    float32  `json:"rank" example:"0.0607927"`
+}
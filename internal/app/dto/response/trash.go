@@ -0,0 +1,21 @@
+package response
+
+import (
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"time"
+)
+
+// TrashedBook response
+type TrashedBook struct {
+	ID        types.ID  `json:"id" example:"1"`
+	Title     string    `json:"title" example:"The Go Programming Language"`
+	AuthorID  types.ID  `json:"author_id" example:"1"`
+	DeletedAt time.Time `json:"deleted_at" example:"2026-10-17T10:00:00Z"`
+}
+
+// TrashedAuthor response
+type TrashedAuthor struct {
+	ID        types.ID  `json:"id" example:"1"`
+	Name      string    `json:"name" example:"Alan Donovan"`
+	DeletedAt time.Time `json:"deleted_at" example:"2026-10-17T10:00:00Z"`
+}
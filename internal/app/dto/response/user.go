@@ -7,7 +7,10 @@ import (
 // User is a response model for user
 type User struct {
 	Username types.Username `json:"username" example:"email@email.com"`
+	Role     string         `json:"role" example:"reader" enums:"reader,editor,admin"`
 	Info     UserInfo       `json:"info"`
+	// TwoFactor reports whether two-factor authentication is enabled
+	TwoFactor bool `json:"two_factor"`
 }
 
 // UserInfo is a response model for user info
@@ -15,4 +18,13 @@ type UserInfo struct {
 	FirstName string `json:"firstname" example:"John"`
 	LastName  string `json:"lastname" example:"Doe"`
 	Email     string `json:"email" example:"email@email.com"`
+	Language  string `json:"language,omitempty" example:"en"`
+}
+
+// TwoFactorEnrollment is a response model for two-factor authentication enrollment,
+// the secret and the recovery codes are shown only once
+type TwoFactorEnrollment struct {
+	URI           string   `json:"otpauth_uri" example:"otpauth://totp/Book%20Catalog:email@email.com?secret=..."`
+	Secret        string   `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	RecoveryCodes []string `json:"recovery_codes" example:"abcdefgh-ijklmnop"`
 }
@@ -0,0 +1,168 @@
+package facade
+
+import (
+	"context"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/request"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/response"
+	"github.com/vlaship/book-catalog-go/internal/app/mapper"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"github.com/vlaship/book-catalog-go/internal/apperr"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
+)
+
+// UserAdmin is an interface for managing the accounts of users
+//
+//go:generate mockgen -destination=../../../test/mock/facade/mock-user-admin.go -package=mock . UserAdmin
+type UserAdmin interface {
+	GetUserByID(ctx context.Context, userID types.UserID) (*model.User, error)
+	GetUsers(ctx context.Context, filter model.UserFilter) (*model.Page[model.User], error)
+	UpdateUser(ctx context.Context, userID types.UserID, patch model.UserPatch) error
+	DeleteUser(ctx context.Context, userID types.UserID) error
+	InvalidatePassword(ctx context.Context, user model.User) error
+}
+
+// AdminUserFacade is a facade for managing the accounts of users
+type AdminUserFacade struct {
+	users  UserAdmin
+	th     TokenHandler
+	sender MailSender
+	m      mapper.AdminUser
+	log    logger.Logger
+}
+
+// NewAdminUserFacade creates a new AdminUserFacade instance.
+func NewAdminUserFacade(
+	users UserAdmin,
+	th TokenHandler,
+	sender MailSender,
+	log logger.Logger,
+) *AdminUserFacade {
+	return &AdminUserFacade{
+		users:  users,
+		th:     th,
+		sender: sender,
+		m:      mapper.AdminUser{},
+		log:    log.New("AdminUserFacade"),
+	}
+}
+
+// GetUsers returns page of users
+func (f *AdminUserFacade) GetUsers(
+	ctx context.Context,
+	req *request.UserFilter,
+) (*response.Page[response.AdminUser], error) {
+	ctx, span := tracing.Start(ctx, "AdminUserFacade.GetUsers")
+	defer span.End()
+
+	f.log.Trc().Ctx(ctx).Values("filter", req).Msg("GetUsers")
+
+	filter, err := f.m.FilterReq(req)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := f.users.GetUsers(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.m.PageResp(page), nil
+}
+
+// GetUser returns user
+func (f *AdminUserFacade) GetUser(ctx context.Context, userID types.UserID) (*response.AdminUser, error) {
+	ctx, span := tracing.Start(ctx, "AdminUserFacade.GetUser")
+	defer span.End()
+
+	f.log.Trc().Ctx(ctx).Values("userID", userID).Msg("GetUser")
+
+	user, err := f.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	resp := f.m.Resp(user)
+
+	return &resp, nil
+}
+
+// UpdateUser changes the status, role or plan of user
+func (f *AdminUserFacade) UpdateUser(
+	ctx context.Context,
+	userID types.UserID,
+	req *request.UserPatch,
+) (*response.AdminUser, error) {
+	ctx, span := tracing.Start(ctx, "AdminUserFacade.UpdateUser")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Values("userID", userID, "req", req).Msg("UpdateUser")
+
+	if err := f.users.UpdateUser(ctx, userID, f.m.PatchReq(req)); err != nil {
+		return nil, err
+	}
+
+	return f.GetUser(ctx, userID)
+}
+
+// DeleteUser deletes user
+func (f *AdminUserFacade) DeleteUser(ctx context.Context, userID types.UserID) error {
+	ctx, span := tracing.Start(ctx, "AdminUserFacade.DeleteUser")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Values("userID", userID).Msg("DeleteUser")
+
+	return f.users.DeleteUser(ctx, userID)
+}
+
+// ForcePasswordReset invalidates the password of an active user, signs the user out
+// and mails a reset password otp
+func (f *AdminUserFacade) ForcePasswordReset(ctx context.Context, userID types.UserID) error {
+	ctx, span := tracing.Start(ctx, "AdminUserFacade.ForcePasswordReset")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Values("userID", userID).Msg("ForcePasswordReset")
+
+	user, err := f.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err = user.GetAppError(); err != nil {
+		return err
+	}
+
+	// the old password stops working even if the mail is not delivered, the user can ask for a new otp
+	if err = f.users.InvalidatePassword(ctx, *user); err != nil {
+		return err
+	}
+
+	otp, err := f.th.GenerateOTP(ctx, types.OTPPurposeResetPassword, user.Username)
+	if err != nil {
+		return err
+	}
+
+	return f.sender.SendResetPasswordMail(ctx, user.Username, user.Data.Language, otp)
+}
+
+// ResendActivation mails a new activation otp to a user that is not activated yet
+func (f *AdminUserFacade) ResendActivation(ctx context.Context, userID types.UserID) error {
+	ctx, span := tracing.Start(ctx, "AdminUserFacade.ResendActivation")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Values("userID", userID).Msg("ResendActivation")
+
+	user, err := f.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Data.Status != model.UserStatusNotActivated {
+		return apperr.ErrConflict.WithFunc(apperr.WithDetail("user is already activated"))
+	}
+
+	otp, err := f.th.GenerateOTP(ctx, types.OTPPurposeActivation, user.Username)
+	if err != nil {
+		return err
+	}
+
+	return f.sender.SendActivationMail(ctx, user.Username, user.Data.Language, otp)
+}
@@ -0,0 +1,57 @@
+package facade
+
+import (
+	"context"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/request"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/response"
+	"github.com/vlaship/book-catalog-go/internal/app/mapper"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
+)
+
+// AuditReader is an interface for audit log reader
+//
+//go:generate mockgen -destination=../../../test/mock/facade/mock-audit-reader.go -package=mock . AuditReader
+type AuditReader interface {
+	GetAuditRecords(ctx context.Context, filter model.AuditFilter) (*model.Page[model.AuditRecord], error)
+}
+
+// AuditFacade is a facade for audit log
+type AuditFacade struct {
+	reader AuditReader
+	m      mapper.Audit
+	log    logger.Logger
+}
+
+// NewAuditFacade creates new audit log facade
+func NewAuditFacade(reader AuditReader, log logger.Logger) *AuditFacade {
+	return &AuditFacade{
+		reader: reader,
+		m:      mapper.Audit{},
+		log:    log.New("AuditFacade"),
+	}
+}
+
+// GetAuditRecords returns page of audit records by filter
+func (f *AuditFacade) GetAuditRecords(
+	ctx context.Context,
+	req *request.AuditFilter,
+) (*response.Page[response.AuditRecord], error) {
+	ctx, span := tracing.Start(ctx, "AuditFacade.GetAuditRecords")
+	defer span.End()
+
+	f.log.Trc().Ctx(ctx).Values("filter", req).Msg("GetAuditRecords")
+
+	filter, err := f.m.AuditFilterReq(req)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := f.reader.GetAuditRecords(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.m.AuditPageResp(page), nil
+}
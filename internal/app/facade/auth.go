@@ -10,6 +10,7 @@ import (
 	"github.com/vlaship/book-catalog-go/internal/app/types"
 	"github.com/vlaship/book-catalog-go/internal/apperr"
 	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
 )
 
 // Auth interface
@@ -17,25 +18,27 @@ import (
 //go:generate mockgen -destination=../../../test/mock/facade/mock-auth.go -package=mock . Auth
 type Auth interface {
 	Signin(ctx context.Context, signin model.User) (*model.Signin, error)
+	SigninTwoFactor(ctx context.Context, challenge types.Token, code string) (*model.Signin, error)
 	Signup(ctx context.Context, input model.User) (*model.User, error)
+	Refresh(ctx context.Context, token types.Token) (*model.Signin, error)
+	Signout(ctx context.Context, token types.Token) error
 }
 
-// MailSender is an interface for mail sender
+// MailSender is an interface for mail sender, mails are queued in the transaction carried by ctx if any
 //
 //go:generate mockgen -destination=../../../test/mock/facade/mock-mail-sender.go -package=mock . MailSender
 type MailSender interface {
-	SendActivationMail(email types.Username, otp types.Token) error
-	SendResetPasswordMail(email types.Username, otp types.Token) error
+	SendActivationMail(ctx context.Context, email types.Username, lang string, otp types.Token) error
+	SendResetPasswordMail(ctx context.Context, email types.Username, lang string, otp types.Token) error
+	SendChangeEmailMail(ctx context.Context, email types.Username, lang string, otp types.Token) error
 }
 
 // TokenHandler is an interface for token generator
 //
 //go:generate mockgen -destination=../../../test/mock/facade/mock-token-generator.go -package=mock . TokenHandler
 type TokenHandler interface {
-	GenerateActivationOTP(ctx context.Context, username types.Username) types.Token
-	GenerateResetPasswordOTP(ctx context.Context, username types.Username) types.Token
-	ValidateActivationOTP(ctx context.Context, otp types.Token) (types.Username, error)
-	ValidateResetPasswordOTP(ctx context.Context, otp types.Token) (types.Username, error)
+	GenerateOTP(ctx context.Context, purpose types.OTPPurpose, username types.Username) (types.Token, error)
+	ValidateOTP(ctx context.Context, purpose types.OTPPurpose, username types.Username, otp types.Token) error
 }
 
 // AuthFacade is a facade for authentication.
@@ -71,6 +74,9 @@ func NewAuthFacade(
 
 // Signin logging in
 func (f *AuthFacade) Signin(ctx context.Context, req *request.Signin) (*response.Signin, error) {
+	ctx, span := tracing.Start(ctx, "AuthFacade.Signin")
+	defer span.End()
+
 	f.log.Dbg().Ctx(ctx).Values("req", req.String()).Msg("Signin")
 
 	user := f.m.Signin.Model(req)
@@ -83,38 +89,86 @@ func (f *AuthFacade) Signin(ctx context.Context, req *request.Signin) (*response
 	return &resp, nil
 }
 
+// SigninTwoFactor completing signin with two-factor authentication code
+func (f *AuthFacade) SigninTwoFactor(ctx context.Context, req *request.SigninTwoFactor) (*response.Signin, error) {
+	ctx, span := tracing.Start(ctx, "AuthFacade.SigninTwoFactor")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Values("req", req.String()).Msg("SigninTwoFactor")
+
+	out, err := f.auth.SigninTwoFactor(ctx, req.ChallengeToken, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	resp := f.m.Signin.Resp(out)
+
+	return &resp, nil
+}
+
+// Refresh refreshing tokens
+func (f *AuthFacade) Refresh(ctx context.Context, req *request.RefreshToken) (*response.Signin, error) {
+	ctx, span := tracing.Start(ctx, "AuthFacade.Refresh")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Values("req", req.String()).Msg("Refresh")
+
+	out, err := f.auth.Refresh(ctx, req.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	resp := f.m.Signin.Resp(out)
+
+	return &resp, nil
+}
+
+// Signout signing out
+func (f *AuthFacade) Signout(ctx context.Context, req *request.RefreshToken) error {
+	ctx, span := tracing.Start(ctx, "AuthFacade.Signout")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Values("req", req.String()).Msg("Signout")
+
+	return f.auth.Signout(ctx, req.RefreshToken)
+}
+
 // Signup signing up
 func (f *AuthFacade) Signup(ctx context.Context, req *request.Signup) error {
+	ctx, span := tracing.Start(ctx, "AuthFacade.Signup")
+	defer span.End()
+
 	f.log.Dbg().Ctx(ctx).Values("req", req.String()).Msg("Signup")
 
 	input := f.m.Signup.Model(req)
-	user, err := f.auth.Signup(ctx, input)
-	if err != nil {
-		return err
-	}
+	_, err := f.auth.Signup(ctx, input)
 
-	return f.sendActivationMail(ctx, user.Username)
+	return err
 }
 
 // Activate activating user
 func (f *AuthFacade) Activate(ctx context.Context, req *request.Activation) error {
-	f.log.Dbg().Ctx(ctx).Values("req", req).Msg("Activate")
+	ctx, span := tracing.Start(ctx, "AuthFacade.Activate")
+	defer span.End()
 
-	username, err := f.th.ValidateActivationOTP(ctx, req.OTP)
-	if err != nil {
+	f.log.Dbg().Ctx(ctx).Values("req", req.String()).Msg("Activate")
+
+	if err := f.th.ValidateOTP(ctx, types.OTPPurposeActivation, req.Username, req.OTP); err != nil {
 		return err
 	}
 
 	u := model.User{
-		Username: username,
+		Username: req.Username,
 		Data:     model.UserData{Status: model.UserStatusActive},
 	}
 
 	return f.uw.Activate(ctx, u)
 }
 
-// Resend resending activation mail
+// Resend resending activation mail, only users that are not activated get it,
+// unknown and other users are not reported to keep usernames from being probed
 func (f *AuthFacade) Resend(ctx context.Context, req *request.ResendActivation) error {
+	ctx, span := tracing.Start(ctx, "AuthFacade.Resend")
+	defer span.End()
+
 	f.log.Dbg().Ctx(ctx).Values("req", req).Msg("Resend")
 
 	user, err := f.ur.GetUserByUsername(ctx, req.Username)
@@ -124,12 +178,19 @@ func (f *AuthFacade) Resend(ctx context.Context, req *request.ResendActivation)
 		}
 		return err
 	}
+	if user.Data.Status != model.UserStatusNotActivated {
+		f.log.Dbg().Ctx(ctx).Values("userID", user.ID).Msg("user is not awaiting activation")
+		return nil
+	}
 
-	return f.sendActivationMail(ctx, user.Username)
+	return f.sendActivationMail(ctx, user)
 }
 
 // Reset resetting password
 func (f *AuthFacade) Reset(ctx context.Context, req *request.ResetPassword) error {
+	ctx, span := tracing.Start(ctx, "AuthFacade.Reset")
+	defer span.End()
+
 	f.log.Dbg().Ctx(ctx).Values("req", req.String()).Msg("Reset")
 
 	user, err := f.ur.GetUserByUsername(ctx, req.Username)
@@ -140,28 +201,34 @@ func (f *AuthFacade) Reset(ctx context.Context, req *request.ResetPassword) erro
 		return err
 	}
 
-	otp := f.th.GenerateResetPasswordOTP(ctx, user.Username)
-	return f.sender.SendResetPasswordMail(user.Username, otp)
+	otp, err := f.th.GenerateOTP(ctx, types.OTPPurposeResetPassword, user.Username)
+	if err != nil {
+		return err
+	}
+
+	return f.sender.SendResetPasswordMail(ctx, user.Username, user.Data.Language, otp)
 }
 
 // Replace replacing password
 func (f *AuthFacade) Replace(ctx context.Context, req *request.ReplacePassword) error {
-	f.log.Dbg().Ctx(ctx).Values("req", req.String()).Msg("Replace")
+	ctx, span := tracing.Start(ctx, "AuthFacade.Replace")
+	defer span.End()
 
-	username, err := f.th.ValidateResetPasswordOTP(ctx, req.OTP)
-	if err != nil {
-		return err
-	}
+	f.log.Dbg().Ctx(ctx).Values("req", req.String()).Msg("Replace")
 
 	u := model.User{
-		Username: username,
+		Username: req.Username,
 		Password: req.NewPassword,
 	}
 
-	return f.uw.UpdatePassword(ctx, u)
+	return f.uw.ReplacePassword(ctx, u, req.OTP)
 }
 
-func (f *AuthFacade) sendActivationMail(ctx context.Context, username types.Username) error {
-	otp := f.th.GenerateActivationOTP(ctx, username)
-	return f.sender.SendActivationMail(username, otp)
+func (f *AuthFacade) sendActivationMail(ctx context.Context, user *model.User) error {
+	otp, err := f.th.GenerateOTP(ctx, types.OTPPurposeActivation, user.Username)
+	if err != nil {
+		return err
+	}
+
+	return f.sender.SendActivationMail(ctx, user.Username, user.Data.Language, otp)
 }
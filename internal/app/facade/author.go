@@ -8,6 +8,7 @@ import (
 	"github.com/vlaship/book-catalog-go/internal/app/model"
 	"github.com/vlaship/book-catalog-go/internal/app/types"
 	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
 )
 
 // AuthorReader is an interface for author reader
@@ -23,29 +24,36 @@ type AuthorReader interface {
 //go:generate mockgen -destination=../../../test/mock/facade/mock-author-writer.go -package=mock . AuthorWriter
 type AuthorWriter interface {
 	CreateAuthor(ctx context.Context, author *model.Author) (*model.Author, error)
-	UpdateAuthor(ctx context.Context, authorID types.ID, author *model.Author) error
-	DeleteAuthor(ctx context.Context, authorID types.ID) error
+	UpdateAuthor(ctx context.Context, authorID types.ID, author *model.Author, version int64) error
+	DeleteAuthor(ctx context.Context, authorID types.ID, version int64) error
 }
 
 type AuthorFacade struct {
 	reader AuthorReader
 	writer AuthorWriter
+	books  BookReader
 	m      mapper.Author
+	bm     mapper.Book
 	log    logger.Logger
 }
 
 // NewAuthorFacade creates new author facade
-func NewAuthorFacade(reader AuthorReader, writer AuthorWriter, log logger.Logger) *AuthorFacade {
+func NewAuthorFacade(reader AuthorReader, writer AuthorWriter, books BookReader, log logger.Logger) *AuthorFacade {
 	return &AuthorFacade{
 		reader: reader,
 		writer: writer,
+		books:  books,
 		m:      mapper.Author{},
+		bm:     mapper.Book{},
 		log:    log.New("AuthorFacade"),
 	}
 }
 
 // GetAuthors returns all authors
 func (f *AuthorFacade) GetAuthors(ctx context.Context) ([]response.ListAuthor, error) {
+	ctx, span := tracing.Start(ctx, "AuthorFacade.GetAuthors")
+	defer span.End()
+
 	f.log.Trc().Ctx(ctx).Msg("GetAuthors")
 
 	authors, err := f.reader.GetAuthors(ctx)
@@ -58,6 +66,9 @@ func (f *AuthorFacade) GetAuthors(ctx context.Context) ([]response.ListAuthor, e
 
 // GetAuthor returns author by id
 func (f *AuthorFacade) GetAuthor(ctx context.Context, authorID types.ID) (*response.Author, error) {
+	ctx, span := tracing.Start(ctx, "AuthorFacade.GetAuthor")
+	defer span.End()
+
 	f.log.Dbg().Ctx(ctx).Msg("GetAuthor")
 
 	author, err := f.reader.GetAuthor(ctx, authorID)
@@ -68,8 +79,41 @@ func (f *AuthorFacade) GetAuthor(ctx context.Context, authorID types.ID) (*respo
 	return f.m.AuthorResp(author), nil
 }
 
+// GetAuthorBooks returns author with page of their books
+func (f *AuthorFacade) GetAuthorBooks(
+	ctx context.Context,
+	authorID types.ID,
+	req *request.BookFilter,
+) (*response.AuthorBooks, error) {
+	ctx, span := tracing.Start(ctx, "AuthorFacade.GetAuthorBooks")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Values("authorID", authorID, "filter", req).Msg("GetAuthorBooks")
+
+	author, err := f.reader.GetAuthor(ctx, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := f.bm.BookFilterReq(req)
+	if err != nil {
+		return nil, err
+	}
+	filter.AuthorID = &author.ID
+
+	books, err := f.books.GetBooks(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.m.AuthorBooksResp(author, books), nil
+}
+
 // CreateAuthor inserts new author
 func (f *AuthorFacade) CreateAuthor(ctx context.Context, author *request.CreateAuthor) (*response.CreateAuthor, error) {
+	ctx, span := tracing.Start(ctx, "AuthorFacade.CreateAuthor")
+	defer span.End()
+
 	f.log.Dbg().Ctx(ctx).Values("author", author).Msg("CreateAuthorReq")
 
 	req := f.m.CreateAuthorReq(author)
@@ -83,15 +127,26 @@ func (f *AuthorFacade) CreateAuthor(ctx context.Context, author *request.CreateA
 }
 
 // UpdateAuthor updates author
-func (f *AuthorFacade) UpdateAuthor(ctx context.Context, authorID types.ID, author *request.UpdateAuthor) error {
-	f.log.Dbg().Ctx(ctx).Values("author", author).Msg("UpdateAuthorReq")
-
-	return f.writer.UpdateAuthor(ctx, authorID, f.m.UpdateAuthorReq(author))
+func (f *AuthorFacade) UpdateAuthor(
+	ctx context.Context,
+	authorID types.ID,
+	author *request.UpdateAuthor,
+	version int64,
+) error {
+	ctx, span := tracing.Start(ctx, "AuthorFacade.UpdateAuthor")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Values("author", author, "version", version).Msg("UpdateAuthorReq")
+
+	return f.writer.UpdateAuthor(ctx, authorID, f.m.UpdateAuthorReq(author), version)
 }
 
 // DeleteAuthor deletes author
-func (f *AuthorFacade) DeleteAuthor(ctx context.Context, authorID types.ID) error {
-	f.log.Dbg().Ctx(ctx).Values("authorID", authorID).Msg("DeleteAuthor")
+func (f *AuthorFacade) DeleteAuthor(ctx context.Context, authorID types.ID, version int64) error {
+	ctx, span := tracing.Start(ctx, "AuthorFacade.DeleteAuthor")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Values("authorID", authorID, "version", version).Msg("DeleteAuthor")
 
-	return f.writer.DeleteAuthor(ctx, authorID)
+	return f.writer.DeleteAuthor(ctx, authorID, version)
 }
@@ -8,14 +8,17 @@ import (
 	"github.com/vlaship/book-catalog-go/internal/app/model"
 	"github.com/vlaship/book-catalog-go/internal/app/types"
 	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
 )
 
 // BookReader is an interface for book reader
 //
 //go:generate mockgen -destination=../../../test/mock/facade/mock-book-reader.go -package=mock . BookReader
 type BookReader interface {
-	GetBook(ctx context.Context, bookID types.ID) (*model.Book, error)
-	GetBooks(ctx context.Context) ([]model.Book, error)
+	GetBook(ctx context.Context, bookID types.ID, expand model.BookExpand) (*model.Book, error)
+	GetBookByISBN(ctx context.Context, isbn string, expand model.BookExpand) (*model.Book, error)
+	GetBooks(ctx context.Context, filter model.BookFilter) (*model.Page[model.Book], error)
+	ExportBooks(ctx context.Context, filter model.BookFilter, fn func(book *model.Book) error) error
 }
 
 // BookWriter is an interface for book writer
@@ -23,8 +26,8 @@ type BookReader interface {
 //go:generate mockgen -destination=../../../test/mock/facade/mock-book-writer.go -package=mock . BookWriter
 type BookWriter interface {
 	CreateBook(ctx context.Context, book *model.Book) (*model.Book, error)
-	UpdateBook(ctx context.Context, bookID types.ID, book *model.Book) error
-	DeleteBook(ctx context.Context, bookID types.ID) error
+	UpdateBook(ctx context.Context, bookID types.ID, book *model.Book, version int64) error
+	DeleteBook(ctx context.Context, bookID types.ID, version int64) error
 }
 
 // BookFacade is a facade for book
@@ -50,10 +53,13 @@ func NewBookFacade(
 }
 
 // GetBook returns book by id
-func (f *BookFacade) GetBook(ctx context.Context, bookID types.ID) (*response.Book, error) {
-	f.log.Dbg().Ctx(ctx).Values("bookID", bookID).Msg("GetBook")
+func (f *BookFacade) GetBook(ctx context.Context, bookID types.ID, req *request.BookExpand) (*response.Book, error) {
+	ctx, span := tracing.Start(ctx, "BookFacade.GetBook")
+	defer span.End()
 
-	book, err := f.reader.GetBook(ctx, bookID)
+	f.log.Dbg().Ctx(ctx).Values("bookID", bookID, "expand", req).Msg("GetBook")
+
+	book, err := f.reader.GetBook(ctx, bookID, f.m.BookExpandReq(req))
 	if err != nil {
 		return nil, err
 	}
@@ -61,20 +67,46 @@ func (f *BookFacade) GetBook(ctx context.Context, bookID types.ID) (*response.Bo
 	return f.m.BookResp(book), nil
 }
 
-// GetBooks returns all books
-func (f *BookFacade) GetBooks(ctx context.Context) ([]response.ListBook, error) {
-	f.log.Trc().Ctx(ctx).Msg("GetBooks")
+// GetBookByISBN returns book by normalized ISBN
+func (f *BookFacade) GetBookByISBN(ctx context.Context, isbn string, req *request.BookExpand) (*response.Book, error) {
+	ctx, span := tracing.Start(ctx, "BookFacade.GetBookByISBN")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Values("isbn", isbn, "expand", req).Msg("GetBookByISBN")
 
-	books, err := f.reader.GetBooks(ctx)
+	book, err := f.reader.GetBookByISBN(ctx, isbn, f.m.BookExpandReq(req))
 	if err != nil {
 		return nil, err
 	}
 
-	return f.m.BooksResp(books), nil
+	return f.m.BookResp(book), nil
+}
+
+// GetBooks returns page of books by filter
+func (f *BookFacade) GetBooks(ctx context.Context, req *request.BookFilter) (*response.Page[response.ListBook], error) {
+	ctx, span := tracing.Start(ctx, "BookFacade.GetBooks")
+	defer span.End()
+
+	f.log.Trc().Ctx(ctx).Values("filter", req).Msg("GetBooks")
+
+	filter, err := f.m.BookFilterReq(req)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := f.reader.GetBooks(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.m.BooksPageResp(page), nil
 }
 
 // CreateBook creates new book
 func (f *BookFacade) CreateBook(ctx context.Context, book *request.CreateBook) (*response.CreateBook, error) {
+	ctx, span := tracing.Start(ctx, "BookFacade.CreateBook")
+	defer span.End()
+
 	f.log.Dbg().Ctx(ctx).Values("book", book).Msg("CreateBookReq")
 
 	req := f.m.CreateBookReq(book)
@@ -87,15 +119,42 @@ func (f *BookFacade) CreateBook(ctx context.Context, book *request.CreateBook) (
 }
 
 // UpdateBook updates book by id
-func (f *BookFacade) UpdateBook(ctx context.Context, bookID types.ID, book *request.UpdateBook) error {
-	f.log.Dbg().Ctx(ctx).Values("book", book).Msg("UpdateBookReq")
+func (f *BookFacade) UpdateBook(ctx context.Context, bookID types.ID, book *request.UpdateBook, version int64) error {
+	ctx, span := tracing.Start(ctx, "BookFacade.UpdateBook")
+	defer span.End()
 
-	return f.writer.UpdateBook(ctx, bookID, f.m.UpdateBookReq(book))
+	f.log.Dbg().Ctx(ctx).Values("book", book, "version", version).Msg("UpdateBookReq")
+
+	return f.writer.UpdateBook(ctx, bookID, f.m.UpdateBookReq(book), version)
 }
 
 // DeleteBook deletes book by id
-func (f *BookFacade) DeleteBook(ctx context.Context, bookID types.ID) error {
-	f.log.Dbg().Ctx(ctx).Values("bookID", bookID).Msg("DeleteBook")
+func (f *BookFacade) DeleteBook(ctx context.Context, bookID types.ID, version int64) error {
+	ctx, span := tracing.Start(ctx, "BookFacade.DeleteBook")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Values("bookID", bookID, "version", version).Msg("DeleteBook")
+
+	return f.writer.DeleteBook(ctx, bookID, version)
+}
+
+// ExportBooks calls fn for every book by filter, the limit of the filter is ignored
+func (f *BookFacade) ExportBooks(
+	ctx context.Context,
+	req *request.BookFilter,
+	fn func(book *response.ExportBook) error,
+) error {
+	ctx, span := tracing.Start(ctx, "BookFacade.ExportBooks")
+	defer span.End()
+
+	f.log.Trc().Ctx(ctx).Values("filter", req).Msg("ExportBooks")
+
+	filter, err := f.m.BookFilterReq(req)
+	if err != nil {
+		return err
+	}
 
-	return f.writer.DeleteBook(ctx, bookID)
+	return f.reader.ExportBooks(ctx, filter, func(book *model.Book) error {
+		return fn(f.m.ExportBookResp(book))
+	})
 }
@@ -2,8 +2,13 @@ package facade
 
 // Facades is an interface for facades
 type Facades struct {
-	AuthorFacade *AuthorFacade
-	BookFacade   *BookFacade
-	AuthFacade   *AuthFacade
-	UserFacade   *UserFacade
+	AuthorFacade    *AuthorFacade
+	BookFacade      *BookFacade
+	AuthFacade      *AuthFacade
+	UserFacade      *UserFacade
+	SearchFacade    *SearchFacade
+	AuditFacade     *AuditFacade
+	TrashFacade     *TrashFacade
+	ImportFacade    *ImportFacade
+	AdminUserFacade *AdminUserFacade
 }
@@ -0,0 +1,53 @@
+package facade
+
+import (
+	"context"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/request"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/response"
+	"github.com/vlaship/book-catalog-go/internal/app/mapper"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
+)
+
+// Importer is an interface for bulk import of books and authors
+//
+//go:generate mockgen -destination=../../../test/mock/facade/mock-importer.go -package=mock . Importer
+type Importer interface {
+	ImportBooks(ctx context.Context, rows []model.ImportBook, dryRun bool) (*model.ImportResult, error)
+}
+
+// ImportFacade is a facade for catalog import
+type ImportFacade struct {
+	importer Importer
+	m        mapper.Import
+	log      logger.Logger
+}
+
+// NewImportFacade creates new import facade
+func NewImportFacade(importer Importer, log logger.Logger) *ImportFacade {
+	return &ImportFacade{
+		importer: importer,
+		m:        mapper.Import{},
+		log:      log.New("ImportFacade"),
+	}
+}
+
+// ImportBooks imports a batch of validated rows
+func (f *ImportFacade) ImportBooks(
+	ctx context.Context,
+	rows []request.ImportBook,
+	dryRun bool,
+) (*response.ImportReport, error) {
+	ctx, span := tracing.Start(ctx, "ImportFacade.ImportBooks")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Values("rows", len(rows), "dryRun", dryRun).Msg("ImportBooks")
+
+	out, err := f.importer.ImportBooks(ctx, f.m.ImportBooksReq(rows), dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.m.ImportReportResp(len(rows), dryRun, out), nil
+}
@@ -0,0 +1,49 @@
+package facade
+
+import (
+	"context"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/request"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/response"
+	"github.com/vlaship/book-catalog-go/internal/app/mapper"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
+)
+
+// SearchReader is an interface for full-text search reader
+//
+//go:generate mockgen -destination=../../../test/mock/facade/mock-search-reader.go -package=mock . SearchReader
+type SearchReader interface {
+	Search(ctx context.Context, query string, limit int) ([]model.SearchHit, error)
+}
+
+// SearchFacade is a facade for full-text search
+type SearchFacade struct {
+	reader SearchReader
+	m      mapper.Search
+	log    logger.Logger
+}
+
+// NewSearchFacade creates new search facade
+func NewSearchFacade(reader SearchReader, log logger.Logger) *SearchFacade {
+	return &SearchFacade{
+		reader: reader,
+		m:      mapper.Search{},
+		log:    log.New("SearchFacade"),
+	}
+}
+
+// Search returns books and authors matching the query
+func (f *SearchFacade) Search(ctx context.Context, req *request.Search) ([]response.SearchHit, error) {
+	ctx, span := tracing.Start(ctx, "SearchFacade.Search")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Values("req", req).Msg("Search")
+
+	hits, err := f.reader.Search(ctx, req.Query, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.m.SearchHitsResp(hits), nil
+}
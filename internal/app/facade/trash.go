@@ -0,0 +1,126 @@
+package facade
+
+import (
+	"context"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/request"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/response"
+	"github.com/vlaship/book-catalog-go/internal/app/mapper"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
+)
+
+// TrashHandler is an interface for listing, restoring and purging soft deleted books and authors
+//
+//go:generate mockgen -destination=../../../test/mock/facade/mock-trash-handler.go -package=mock . TrashHandler
+type TrashHandler interface {
+	GetTrashedBooks(ctx context.Context, filter model.TrashFilter) (*model.Page[model.Book], error)
+	RestoreBook(ctx context.Context, bookID types.ID) error
+	PurgeBook(ctx context.Context, bookID types.ID) error
+	GetTrashedAuthors(ctx context.Context, filter model.TrashFilter) (*model.Page[model.Author], error)
+	RestoreAuthor(ctx context.Context, authorID types.ID) error
+	PurgeAuthor(ctx context.Context, authorID types.ID) error
+}
+
+// TrashFacade is a facade for soft deleted books and authors
+type TrashFacade struct {
+	handler TrashHandler
+	m       mapper.Trash
+	log     logger.Logger
+}
+
+// NewTrashFacade creates new trash facade
+func NewTrashFacade(handler TrashHandler, log logger.Logger) *TrashFacade {
+	return &TrashFacade{
+		handler: handler,
+		m:       mapper.Trash{},
+		log:     log.New("TrashFacade"),
+	}
+}
+
+// GetTrashedBooks returns page of soft deleted books
+func (f *TrashFacade) GetTrashedBooks(
+	ctx context.Context,
+	req *request.TrashFilter,
+) (*response.Page[response.TrashedBook], error) {
+	ctx, span := tracing.Start(ctx, "TrashFacade.GetTrashedBooks")
+	defer span.End()
+
+	f.log.Trc().Ctx(ctx).Values("filter", req).Msg("GetTrashedBooks")
+
+	filter, err := f.m.TrashFilterReq(req)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := f.handler.GetTrashedBooks(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.m.TrashedBookPageResp(page), nil
+}
+
+// RestoreBook restores soft deleted book
+func (f *TrashFacade) RestoreBook(ctx context.Context, bookID types.ID) error {
+	ctx, span := tracing.Start(ctx, "TrashFacade.RestoreBook")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Values("bookID", bookID).Msg("RestoreBook")
+
+	return f.handler.RestoreBook(ctx, bookID)
+}
+
+// PurgeBook permanently deletes soft deleted book
+func (f *TrashFacade) PurgeBook(ctx context.Context, bookID types.ID) error {
+	ctx, span := tracing.Start(ctx, "TrashFacade.PurgeBook")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Values("bookID", bookID).Msg("PurgeBook")
+
+	return f.handler.PurgeBook(ctx, bookID)
+}
+
+// GetTrashedAuthors returns page of soft deleted authors
+func (f *TrashFacade) GetTrashedAuthors(
+	ctx context.Context,
+	req *request.TrashFilter,
+) (*response.Page[response.TrashedAuthor], error) {
+	ctx, span := tracing.Start(ctx, "TrashFacade.GetTrashedAuthors")
+	defer span.End()
+
+	f.log.Trc().Ctx(ctx).Values("filter", req).Msg("GetTrashedAuthors")
+
+	filter, err := f.m.TrashFilterReq(req)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := f.handler.GetTrashedAuthors(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.m.TrashedAuthorPageResp(page), nil
+}
+
+// RestoreAuthor restores soft deleted author
+func (f *TrashFacade) RestoreAuthor(ctx context.Context, authorID types.ID) error {
+	ctx, span := tracing.Start(ctx, "TrashFacade.RestoreAuthor")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Values("authorID", authorID).Msg("RestoreAuthor")
+
+	return f.handler.RestoreAuthor(ctx, authorID)
+}
+
+// PurgeAuthor permanently deletes soft deleted author and the deleted books of the author
+func (f *TrashFacade) PurgeAuthor(ctx context.Context, authorID types.ID) error {
+	ctx, span := tracing.Start(ctx, "TrashFacade.PurgeAuthor")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Values("authorID", authorID).Msg("PurgeAuthor")
+
+	return f.handler.PurgeAuthor(ctx, authorID)
+}
@@ -2,13 +2,18 @@ package facade
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/vlaship/book-catalog-go/internal/app/common"
 	"github.com/vlaship/book-catalog-go/internal/app/dto/request"
 	"github.com/vlaship/book-catalog-go/internal/app/dto/response"
 	"github.com/vlaship/book-catalog-go/internal/app/mapper"
 	"github.com/vlaship/book-catalog-go/internal/app/model"
 	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"github.com/vlaship/book-catalog-go/internal/apperr"
 	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
+	"strings"
 )
 
 // UserReader is an interface for user reader
@@ -23,14 +28,29 @@ type UserReader interface {
 //go:generate mockgen -destination=../../../test/mock/facade/mock-user-writer.go -package=mock . UserWriter
 type UserWriter interface {
 	Activate(ctx context.Context, user model.User) error
-	UpdatePassword(ctx context.Context, user model.User) error
+	ReplacePassword(ctx context.Context, user model.User, otp types.Token) error
 	UpdateInfo(ctx context.Context, user model.User) error
+	ChangePassword(ctx context.Context, current, password types.Password) error
+	ChangeEmail(ctx context.Context, email types.Username) error
+	DeleteAccount(ctx context.Context) error
+}
+
+// TwoFactorHandler is an interface for two-factor authentication of the caller
+//
+//go:generate mockgen -destination=../../../test/mock/facade/mock-two-factor-handler.go -package=mock . TwoFactorHandler
+type TwoFactorHandler interface {
+	Enroll(ctx context.Context) (*model.TwoFactorEnrollment, error)
+	Confirm(ctx context.Context, code string) error
+	Disable(ctx context.Context, password types.Password) error
 }
 
 // UserFacade is an interface for user facade
 type UserFacade struct {
 	reader UserReader
 	writer UserWriter
+	tf     TwoFactorHandler
+	th     TokenHandler
+	sender MailSender
 	m      mapper.User
 	log    logger.Logger
 }
@@ -39,11 +59,17 @@ type UserFacade struct {
 func NewUserFacade(
 	reader UserReader,
 	writer UserWriter,
+	tf TwoFactorHandler,
+	th TokenHandler,
+	sender MailSender,
 	log logger.Logger,
 ) *UserFacade {
 	return &UserFacade{
 		reader: reader,
 		writer: writer,
+		tf:     tf,
+		th:     th,
+		sender: sender,
 		m:      mapper.User{},
 		log:    log.New("UserFacade"),
 	}
@@ -51,6 +77,9 @@ func NewUserFacade(
 
 // GetUser returns user
 func (f *UserFacade) GetUser(ctx context.Context) response.User {
+	ctx, span := tracing.Start(ctx, "UserFacade.GetUser")
+	defer span.End()
+
 	f.log.Trc().Ctx(ctx).Msg("GetUser")
 
 	user := common.GetUser(ctx)
@@ -59,8 +88,112 @@ func (f *UserFacade) GetUser(ctx context.Context) response.User {
 
 // UpdateInfo updates user
 func (f *UserFacade) UpdateInfo(ctx context.Context, req *request.UserData) error {
+	ctx, span := tracing.Start(ctx, "UserFacade.UpdateInfo")
+	defer span.End()
+
 	f.log.Dbg().Ctx(ctx).Values("user", req).Msg("UpdateInfo")
 
 	user := f.m.Model(req)
 	return f.writer.UpdateInfo(ctx, user)
 }
+
+// ChangePassword changes password of user
+func (f *UserFacade) ChangePassword(ctx context.Context, req *request.ChangePassword) error {
+	ctx, span := tracing.Start(ctx, "UserFacade.ChangePassword")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Values("req", req.String()).Msg("ChangePassword")
+
+	return f.writer.ChangePassword(ctx, req.CurrentPassword, req.NewPassword)
+}
+
+// ChangeEmail mails otp confirming the email change to the new email
+func (f *UserFacade) ChangeEmail(ctx context.Context, req *request.ChangeEmail) error {
+	ctx, span := tracing.Start(ctx, "UserFacade.ChangeEmail")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Values("req", req.String()).Msg("ChangeEmail")
+
+	_, err := f.reader.GetUserByUsername(ctx, req.Email)
+	switch {
+	case err == nil:
+		return apperr.ErrAlreadyExists.WithFunc(apperr.WithDetail("email is already in use"))
+	case !errors.Is(err, apperr.ErrNotFound):
+		return err
+	}
+
+	user := common.GetUser(ctx)
+	otp, err := f.th.GenerateOTP(ctx, types.OTPPurposeChangeEmail, emailChangeSubject(user.ID, req.Email))
+	if err != nil {
+		return err
+	}
+
+	return f.sender.SendChangeEmailMail(ctx, req.Email, user.Data.Language, otp)
+}
+
+// ConfirmEmail changes email of user once the otp mailed to the new email is validated
+func (f *UserFacade) ConfirmEmail(ctx context.Context, req *request.ConfirmEmail) error {
+	ctx, span := tracing.Start(ctx, "UserFacade.ConfirmEmail")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Values("req", req.String()).Msg("ConfirmEmail")
+
+	user := common.GetUser(ctx)
+	if err := f.th.ValidateOTP(ctx, types.OTPPurposeChangeEmail, emailChangeSubject(user.ID, req.Email), req.OTP); err != nil {
+		return err
+	}
+
+	return f.writer.ChangeEmail(ctx, req.Email)
+}
+
+// DeleteAccount deletes account of user
+func (f *UserFacade) DeleteAccount(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "UserFacade.DeleteAccount")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Msg("DeleteAccount")
+
+	return f.writer.DeleteAccount(ctx)
+}
+
+// EnrollTwoFactor starts two-factor authentication enrollment
+func (f *UserFacade) EnrollTwoFactor(ctx context.Context) (*response.TwoFactorEnrollment, error) {
+	ctx, span := tracing.Start(ctx, "UserFacade.EnrollTwoFactor")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Msg("EnrollTwoFactor")
+
+	out, err := f.tf.Enroll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := f.m.TwoFactorEnrollmentResp(out)
+
+	return &resp, nil
+}
+
+// ConfirmTwoFactor enables two-factor authentication
+func (f *UserFacade) ConfirmTwoFactor(ctx context.Context, req *request.TwoFactorCode) error {
+	ctx, span := tracing.Start(ctx, "UserFacade.ConfirmTwoFactor")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Msg("ConfirmTwoFactor")
+
+	return f.tf.Confirm(ctx, req.Code)
+}
+
+// DisableTwoFactor disables two-factor authentication
+func (f *UserFacade) DisableTwoFactor(ctx context.Context, req *request.DisableTwoFactor) error {
+	ctx, span := tracing.Start(ctx, "UserFacade.DisableTwoFactor")
+	defer span.End()
+
+	f.log.Dbg().Ctx(ctx).Values("req", req.String()).Msg("DisableTwoFactor")
+
+	return f.tf.Disable(ctx, req.Password)
+}
+
+// emailChangeSubject binds the otp of email change to both the user and the new email,
+// so the otp confirms only the email it is mailed to and only for the user who asked for it
+func emailChangeSubject(userID types.UserID, email types.Username) types.Username {
+	return types.Username(fmt.Sprintf("%d:%s", userID, strings.ToLower(string(email))))
+}
@@ -15,6 +15,11 @@ func Wire(services *service.Services, log logger.Logger) *Facades {
 		NewAuthorFacade,
 		NewAuthFacade,
 		NewUserFacade,
+		NewSearchFacade,
+		NewAuditFacade,
+		NewTrashFacade,
+		NewImportFacade,
+		NewAdminUserFacade,
 		AuthProvider,
 		BookReaderProvider,
 		BookWriterProvider,
@@ -24,6 +29,12 @@ func Wire(services *service.Services, log logger.Logger) *Facades {
 		TokenHandlerProvider,
 		UserReaderProvider,
 		UserWriterProvider,
+		SearchReaderProvider,
+		AuditReaderProvider,
+		TrashHandlerProvider,
+		ImporterProvider,
+		UserAdminProvider,
+		TwoFactorHandlerProvider,
 		wire.Struct(new(Facades), "*"),
 	)
 	return &Facades{}
@@ -36,7 +47,7 @@ func AuthProvider(services *service.Services) Auth {
 
 // MailSenderProvider is a provider for MailSender
 func MailSenderProvider(services *service.Services) MailSender {
-	return services.SendMailService
+	return services.OutboxService
 }
 
 // TokenHandlerProvider is a provider for TokenHandler
@@ -54,6 +65,16 @@ func UserWriterProvider(services *service.Services) UserWriter {
 	return services.UserService
 }
 
+// UserAdminProvider is a provider for UserAdmin
+func UserAdminProvider(services *service.Services) UserAdmin {
+	return services.UserService
+}
+
+// TwoFactorHandlerProvider is a provider for TwoFactorHandler
+func TwoFactorHandlerProvider(services *service.Services) TwoFactorHandler {
+	return services.TwoFactorService
+}
+
 // BookReaderProvider is a provider for BookReader
 func BookReaderProvider(services *service.Services) BookReader {
 	return services.BookService
@@ -73,3 +94,23 @@ func AuthorReaderProvider(services *service.Services) AuthorReader {
 func AuthorWriterProvider(services *service.Services) AuthorWriter {
 	return services.AuthorService
 }
+
+// SearchReaderProvider is a provider for SearchReader
+func SearchReaderProvider(services *service.Services) SearchReader {
+	return services.SearchService
+}
+
+// AuditReaderProvider is a provider for AuditReader
+func AuditReaderProvider(services *service.Services) AuditReader {
+	return services.AuditService
+}
+
+// TrashHandlerProvider is a provider for TrashHandler
+func TrashHandlerProvider(services *service.Services) TrashHandler {
+	return services.TrashService
+}
+
+// ImporterProvider is a provider for Importer
+func ImporterProvider(services *service.Services) Importer {
+	return services.ImportService
+}
@@ -0,0 +1,90 @@
+package mapper
+
+import (
+	"github.com/vlaship/book-catalog-go/internal/app/dto/request"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/response"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"time"
+)
+
+// userStatuses maps API user statuses to the model ones
+var userStatuses = map[string]model.UserStatus{
+	"active":        model.UserStatusActive,
+	"not_activated": model.UserStatusNotActivated,
+	"suspended":     model.UserStatusSuspended,
+	"locked":        model.UserStatusLocked,
+}
+
+// AdminUser is a mapper for user accounts managed by admins
+type AdminUser struct {
+	user User
+}
+
+// FilterReq creates a new user filter model
+func (m *AdminUser) FilterReq(req *request.UserFilter) (model.UserFilter, error) {
+	cursor, err := decodeCursor(req.Cursor, "")
+	if err != nil {
+		return model.UserFilter{}, err
+	}
+
+	filter := model.UserFilter{
+		Limit:  req.Limit,
+		Cursor: cursor,
+		Email:  req.Email,
+	}
+	if status, ok := userStatuses[req.Status]; ok {
+		filter.Status = &status
+	}
+
+	return filter, nil
+}
+
+// PatchReq creates a new user patch model
+func (m *AdminUser) PatchReq(req *request.UserPatch) model.UserPatch {
+	patch := model.UserPatch{Plan: req.Plan}
+	if req.Status != nil {
+		status := userStatuses[*req.Status]
+		patch.Status = &status
+	}
+	if req.Role != nil {
+		role := model.UserRole(*req.Role)
+		patch.Role = &role
+	}
+
+	return patch
+}
+
+// Resp creates a new admin user response
+func (m *AdminUser) Resp(out *model.User) response.AdminUser {
+	user := m.user.Resp(out)
+
+	var lockedUntil *time.Time
+	if out.IsLocked(time.Now()) {
+		lockedUntil = out.LockedUntil
+	}
+
+	return response.AdminUser{
+		ID:          out.ID,
+		Username:    user.Username,
+		Role:        user.Role,
+		Plan:        out.Data.Plan,
+		Status:      statusResp(out.Data.Status),
+		Info:        user.Info,
+		CreatedAt:   out.CreatedAt,
+		LockedUntil: lockedUntil,
+	}
+}
+
+// PageResp creates a new page of admin user response
+func (m *AdminUser) PageResp(out *model.Page[model.User]) *response.Page[response.AdminUser] {
+	return pageResp(out, m.Resp)
+}
+
+func statusResp(status model.UserStatus) string {
+	for name, s := range userStatuses {
+		if s == status {
+			return name
+		}
+	}
+	return string(status)
+}
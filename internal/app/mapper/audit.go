@@ -0,0 +1,42 @@
+package mapper
+
+import (
+	"github.com/vlaship/book-catalog-go/internal/app/dto/request"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/response"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+)
+
+// Audit is a mapper for audit log
+type Audit struct{}
+
+// AuditFilterReq creates a new audit filter model
+func (m *Audit) AuditFilterReq(req *request.AuditFilter) (model.AuditFilter, error) {
+	cursor, err := decodeCursor(req.Cursor, "")
+	if err != nil {
+		return model.AuditFilter{}, err
+	}
+
+	return model.AuditFilter{
+		Limit:      req.Limit,
+		Cursor:     cursor,
+		EntityType: model.AuditEntity(req.Entity),
+		EntityID:   req.EntityID,
+	}, nil
+}
+
+// AuditPageResp creates a new page of audit record response
+func (m *Audit) AuditPageResp(out *model.Page[model.AuditRecord]) *response.Page[response.AuditRecord] {
+	return pageResp(out, func(record *model.AuditRecord) response.AuditRecord {
+		return response.AuditRecord{
+			ID:        record.ID,
+			Action:    string(record.Action),
+			Entity:    string(record.EntityType),
+			EntityID:  record.EntityID,
+			UserID:    record.UserID,
+			RequestID: record.RequestID,
+			Before:    record.Before,
+			After:     record.After,
+			CreatedAt: record.CreatedAt,
+		}
+	})
+}
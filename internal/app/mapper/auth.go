@@ -26,6 +26,10 @@ func (Signin) Model(in *request.Signin) model.User {
 
 // Resp creates new auth mapper
 func (Signin) Resp(out *model.Signin) response.Signin {
+	if out.ChallengeToken != "" {
+		return response.Signin{ChallengeToken: out.ChallengeToken}
+	}
+
 	return response.Signin{
 		AccessToken:  out.AccessToken,
 		Type:         "Bearer",
@@ -43,6 +47,8 @@ func (Signup) Model(in *request.Signup) model.User {
 			FirstName: in.Firstname,
 			LastName:  in.Lastname,
 			Status:    model.UserStatusNotActivated,
+			Role:      model.UserRoleReader,
+			Language:  in.Language,
 		},
 	}
 }
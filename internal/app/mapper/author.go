@@ -36,9 +36,18 @@ func (m *Author) CreateAuthorResp(out *model.Author) *response.CreateAuthor {
 // AuthorResp creates a new author response
 func (m *Author) AuthorResp(out *model.Author) *response.Author {
 	return &response.Author{
-		ID:   out.ID,
-		Name: out.Name,
-		Dob:  types.DateDay{Time: out.Dob},
+		ID:      out.ID,
+		Name:    out.Name,
+		Dob:     types.DateDay{Time: out.Dob},
+		Version: out.Version,
+	}
+}
+
+// AuthorBooksResp creates a new author with page of books response
+func (m *Author) AuthorBooksResp(author *model.Author, books *model.Page[model.Book]) *response.AuthorBooks {
+	return &response.AuthorBooks{
+		Author: *m.AuthorResp(author),
+		Books:  *(&Book{}).BooksPageResp(books),
 	}
 }
 
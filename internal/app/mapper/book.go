@@ -5,6 +5,9 @@ import (
 	"github.com/vlaship/book-catalog-go/internal/app/dto/response"
 	"github.com/vlaship/book-catalog-go/internal/app/model"
 	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"github.com/vlaship/book-catalog-go/internal/apperr"
+	"github.com/vlaship/book-catalog-go/internal/isbn"
+	"slices"
 )
 
 // Book is a mapper for book
@@ -15,7 +18,7 @@ func (m *Book) CreateBookReq(req *request.CreateBook) *model.Book {
 	return &model.Book{
 		Title:       req.Title,
 		Description: req.Description,
-		ISBN:        req.ISBN,
+		ISBN:        normalizeISBN(req.ISBN),
 		AuthorID:    req.AuthorID,
 		Price:       req.Price.Value,
 	}
@@ -26,7 +29,7 @@ func (m *Book) UpdateBookReq(req *request.UpdateBook) *model.Book {
 	return &model.Book{
 		Title:       req.Title,
 		Description: req.Description,
-		ISBN:        req.ISBN,
+		ISBN:        normalizeISBN(req.ISBN),
 		AuthorID:    req.AuthorID,
 		Price:       req.Price.Value,
 	}
@@ -48,17 +51,88 @@ func (m *Book) BookResp(out *model.Book) *response.Book {
 		ISBN:        out.ISBN,
 		AuthorID:    out.AuthorID,
 		Price:       types.Decimal{Decimal: out.Price},
+		Author:      m.authorResp(out.Author),
+		Version:     out.Version,
 	}
 }
 
-// BooksResp creates a new list of book response
-func (m *Book) BooksResp(out []model.Book) []response.ListBook {
-	books := make([]response.ListBook, 0, len(out))
-	for i := range out {
-		books = append(books, response.ListBook{
-			ID:    out[i].ID,
-			Title: out[i].Title,
-		})
+// ExportBookResp creates a new export book response
+func (m *Book) ExportBookResp(out *model.Book) *response.ExportBook {
+	res := &response.ExportBook{
+		ID:          out.ID,
+		Title:       out.Title,
+		Description: out.Description,
+		ISBN:        out.ISBN,
+		Price:       types.Decimal{Decimal: out.Price},
+		AuthorID:    out.AuthorID,
+	}
+	if out.Author != nil {
+		res.AuthorName = out.Author.Name
+		res.AuthorDob = &types.DateDay{Time: out.Author.Dob}
+	}
+	return res
+}
+
+// BookExpandReq creates a new book expand model
+func (m *Book) BookExpandReq(req *request.BookExpand) model.BookExpand {
+	return model.BookExpand{
+		Author: slices.Contains(req.Expand, "author"),
+	}
+}
+
+// normalizeISBN converts validated ISBN to ISBN-13 without hyphens, as it is stored
+func normalizeISBN(s string) string {
+	if n, ok := isbn.Normalize(s); ok {
+		return n
 	}
-	return books
+	return s
+}
+
+func (m *Book) authorResp(out *model.Author) *response.Author {
+	if out == nil {
+		return nil
+	}
+	return (&Author{}).AuthorResp(out)
+}
+
+// BookFilterReq creates a new book filter model
+func (m *Book) BookFilterReq(req *request.BookFilter) (model.BookFilter, error) {
+	sort := model.BookSort(req.Sort)
+	if sort == "" {
+		sort = model.BookSortID
+	}
+
+	if req.MinPrice != nil && req.MaxPrice != nil && req.MinPrice.GreaterThan(*req.MaxPrice) {
+		return model.BookFilter{}, apperr.ErrBadRequest.WithFunc(
+			apperr.WithDetail("min_price must not be greater than max_price"),
+			apperr.WithTitle(extractParam),
+		)
+	}
+
+	cursor, err := decodeCursor(req.Cursor, string(sort))
+	if err != nil {
+		return model.BookFilter{}, err
+	}
+
+	return model.BookFilter{
+		Limit:    req.Limit,
+		Cursor:   cursor,
+		AuthorID: req.AuthorID,
+		MinPrice: req.MinPrice,
+		MaxPrice: req.MaxPrice,
+		Title:    req.Title,
+		Sort:     sort,
+		Expand:   m.BookExpandReq(&req.BookExpand),
+	}, nil
+}
+
+// BooksPageResp creates a new page of book response
+func (m *Book) BooksPageResp(out *model.Page[model.Book]) *response.Page[response.ListBook] {
+	return pageResp(out, func(book *model.Book) response.ListBook {
+		return response.ListBook{
+			ID:     book.ID,
+			Title:  book.Title,
+			Author: m.authorResp(book.Author),
+		}
+	})
 }
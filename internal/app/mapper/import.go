@@ -0,0 +1,51 @@
+package mapper
+
+import (
+	"github.com/vlaship/book-catalog-go/internal/app/dto/request"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/response"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+)
+
+// Import is a mapper for catalog import
+type Import struct{}
+
+// ImportBooksReq creates new import book models
+func (m *Import) ImportBooksReq(req []request.ImportBook) []model.ImportBook {
+	out := make([]model.ImportBook, len(req))
+	for i := range req {
+		row := &req[i]
+		out[i] = model.ImportBook{
+			Line: row.Line,
+			Book: model.Book{
+				Title:       row.Book.Title,
+				Description: row.Book.Description,
+				ISBN:        normalizeISBN(row.Book.ISBN),
+				AuthorID:    row.Book.AuthorID,
+				Price:       row.Book.Price.Value,
+			},
+		}
+		if row.Author != nil {
+			out[i].Author = &model.Author{
+				Name: row.Author.Name,
+				Dob:  row.Author.Dob.Time,
+			}
+		}
+	}
+	return out
+}
+
+// ImportReportResp creates a new import report response of a batch
+func (m *Import) ImportReportResp(total int, dryRun bool, out *model.ImportResult) *response.ImportReport {
+	errs := make([]response.ImportError, len(out.Errors))
+	for i, e := range out.Errors {
+		errs[i] = response.ImportError{Line: e.Line, Error: e.Error}
+	}
+
+	return &response.ImportReport{
+		DryRun:         dryRun,
+		Total:          total,
+		Imported:       out.Imported,
+		AuthorsCreated: out.AuthorsCreated,
+		Errors:         errs,
+	}
+}
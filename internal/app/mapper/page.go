@@ -0,0 +1,60 @@
+package mapper
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/vlaship/book-catalog-go/internal/app/dto/response"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"github.com/vlaship/book-catalog-go/internal/apperr"
+)
+
+// extractParam is the title of errors of request params, it is the one of the controllers
+const extractParam = "Extract param"
+
+// encodeCursor encodes cursor to opaque string
+func encodeCursor(c *model.Cursor) string {
+	if c == nil {
+		return ""
+	}
+	b, _ := json.Marshal(c) //nolint:errchkjson // cursor is always marshalable
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor decodes opaque string to cursor, sort must match the sort the cursor was issued for
+func decodeCursor(s, sort string) (*model.Cursor, error) {
+	if s == "" {
+		return nil, nil //nolint:nilnil // no cursor means first page
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errInvalidCursor()
+	}
+	var c model.Cursor
+	if err = json.Unmarshal(b, &c); err != nil || c.Sort != sort {
+		return nil, errInvalidCursor()
+	}
+
+	return &c, nil
+}
+
+func errInvalidCursor() error {
+	return apperr.ErrBadRequest.WithFunc(
+		apperr.WithDetail("invalid cursor"),
+		apperr.WithTitle(extractParam),
+	)
+}
+
+// pageResp maps page of models to page response
+func pageResp[T, R any](page *model.Page[T], item func(t *T) R) *response.Page[R] {
+	items := make([]R, 0, len(page.Items))
+	for i := range page.Items {
+		items = append(items, item(&page.Items[i]))
+	}
+
+	return &response.Page[R]{
+		Items:      items,
+		NextCursor: encodeCursor(page.Next),
+	}
+}
@@ -0,0 +1,24 @@
+package mapper
+
+import (
+	"github.com/vlaship/book-catalog-go/internal/app/dto/response"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+)
+
+// Search is a mapper for search
+type Search struct{}
+
+// SearchHitsResp creates a new list of search hit response
+func (m *Search) SearchHitsResp(out []model.SearchHit) []response.SearchHit {
+	hits := make([]response.SearchHit, 0, len(out))
+	for i := range out {
+		hits = append(hits, response.SearchHit{
+			Kind:    string(out[i].Kind),
+			ID:      out[i].ID,
+			Title:   out[i].Title,
+			Snippet: out[i].Snippet,
+			Rank:    out[i].Rank,
+		})
+	}
+	return hits
+}
@@ -0,0 +1,54 @@
+package mapper
+
+import (
+	"github.com/vlaship/book-catalog-go/internal/app/dto/request"
+	"github.com/vlaship/book-catalog-go/internal/app/dto/response"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"time"
+)
+
+// Trash is a mapper for soft deleted books and authors
+type Trash struct{}
+
+// TrashFilterReq creates a new trash filter model
+func (m *Trash) TrashFilterReq(req *request.TrashFilter) (model.TrashFilter, error) {
+	cursor, err := decodeCursor(req.Cursor, "")
+	if err != nil {
+		return model.TrashFilter{}, err
+	}
+
+	return model.TrashFilter{
+		Limit:  req.Limit,
+		Cursor: cursor,
+	}, nil
+}
+
+// TrashedBookPageResp creates a new page of trashed book response
+func (m *Trash) TrashedBookPageResp(out *model.Page[model.Book]) *response.Page[response.TrashedBook] {
+	return pageResp(out, func(book *model.Book) response.TrashedBook {
+		return response.TrashedBook{
+			ID:        book.ID,
+			Title:     book.Title,
+			AuthorID:  book.AuthorID,
+			DeletedAt: deletedAt(book.DeletedAt),
+		}
+	})
+}
+
+// TrashedAuthorPageResp creates a new page of trashed author response
+func (m *Trash) TrashedAuthorPageResp(out *model.Page[model.Author]) *response.Page[response.TrashedAuthor] {
+	return pageResp(out, func(author *model.Author) response.TrashedAuthor {
+		return response.TrashedAuthor{
+			ID:        author.ID,
+			Name:      author.Name,
+			DeletedAt: deletedAt(author.DeletedAt),
+		}
+	})
+}
+
+func deletedAt(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
@@ -15,7 +15,7 @@ func (m *User) Model(req *request.UserData) model.User {
 		Data: model.UserData{
 			FirstName: req.FirstName,
 			LastName:  req.LastName,
-			Email:     req.Email,
+			Language:  req.Language,
 		},
 	}
 }
@@ -23,11 +23,23 @@ func (m *User) Model(req *request.UserData) model.User {
 // Resp creates a new user response
 func (m *User) Resp(out *model.User) response.User {
 	return response.User{
-		Username: out.Username,
+		Username:  out.Username,
+		Role:      string(out.GetRole()),
+		TwoFactor: out.TwoFactor,
 		Info: response.UserInfo{
 			FirstName: out.Data.FirstName,
 			LastName:  out.Data.LastName,
 			Email:     out.Data.Email,
+			Language:  out.Data.Language,
 		},
 	}
 }
+
+// TwoFactorEnrollmentResp creates a new two-factor enrollment response
+func (m *User) TwoFactorEnrollmentResp(out *model.TwoFactorEnrollment) response.TwoFactorEnrollment {
+	return response.TwoFactorEnrollment{
+		URI:           out.URI,
+		Secret:        out.Secret,
+		RecoveryCodes: out.RecoveryCodes,
+	}
+}
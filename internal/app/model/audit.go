@@ -0,0 +1,54 @@
+package model
+
+import (
+	"encoding/json"
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"time"
+)
+
+// AuditAction is a kind of catalog mutation
+type AuditAction string
+
+// AuditAction values
+const (
+	AuditActionCreate  AuditAction = "create"
+	AuditActionUpdate  AuditAction = "update"
+	AuditActionDelete  AuditAction = "delete"
+	AuditActionRestore AuditAction = "restore"
+	AuditActionPurge   AuditAction = "purge"
+)
+
+// AuditEntity is a type of audited entity
+type AuditEntity string
+
+// AuditEntity values
+const (
+	AuditEntityBook   AuditEntity = "book"
+	AuditEntityAuthor AuditEntity = "author"
+)
+
+// AuditRecord model, Before and After hold the changed fields only
+type AuditRecord struct {
+	ID         types.ID        `db:"id"`
+	Action     AuditAction     `db:"action"`
+	EntityType AuditEntity     `db:"entity_type"`
+	EntityID   types.ID        `db:"entity_id"`
+	UserID     *types.UserID   `db:"user_id"`
+	RequestID  *string         `db:"request_id"`
+	Before     json.RawMessage `db:"before"`
+	After      json.RawMessage `db:"after"`
+	CreatedAt  time.Time       `db:"created_at"`
+}
+
+// AuditFilter is a filter for audit log listing
+type AuditFilter struct {
+	Limit      int
+	Cursor     *Cursor
+	EntityType AuditEntity
+	EntityID   *types.ID
+}
+
+// AuditCursor returns the keyset cursor pointing right after the record
+func AuditCursor(record *AuditRecord) *Cursor {
+	return &Cursor{ID: record.ID}
+}
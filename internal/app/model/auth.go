@@ -2,10 +2,11 @@ package model
 
 import "github.com/vlaship/book-catalog-go/internal/app/types"
 
-// Signin model
+// Signin model, users with two-factor authentication get the challenge token only
 type Signin struct {
-	AccessToken  types.Token
-	Type         string
-	ExpiresIn    int64
-	RefreshToken types.Token
+	AccessToken    types.Token
+	Type           string
+	ExpiresIn      int64
+	RefreshToken   types.Token
+	ChallengeToken types.Token
 }
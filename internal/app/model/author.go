@@ -6,7 +6,9 @@ import (
 )
 
 type Author struct {
-	ID   types.ID  `db:"id"`
-	Name string    `db:"name"`
-	Dob  time.Time `db:"dob"`
+	ID        types.ID   `db:"id"`
+	Name      string     `db:"name"`
+	Dob       time.Time  `db:"dob"`
+	Version   int64      `db:"version"`
+	DeletedAt *time.Time `db:"deleted_at"`
 }
@@ -3,6 +3,7 @@ package model
 import (
 	"github.com/shopspring/decimal"
 	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"time"
 )
 
 // Book model
@@ -13,4 +14,54 @@ type Book struct {
 	ISBN        string          `db:"isbn"`
 	AuthorID    types.ID        `db:"author_id"`
 	Price       decimal.Decimal `db:"price"`
+	Version     int64           `db:"version"`
+	DeletedAt   *time.Time      `db:"deleted_at"`
+	Author      *Author
+}
+
+// BookExpand is a set of relations embedded into book
+type BookExpand struct {
+	Author bool
+}
+
+// BookFilter is a filter for book listing
+type BookFilter struct {
+	Limit    int
+	Cursor   *Cursor
+	AuthorID *types.ID
+	MinPrice *decimal.Decimal
+	MaxPrice *decimal.Decimal
+	Title    string
+	Sort     BookSort
+	Expand   BookExpand
+}
+
+// BookSort is a sort order for book listing
+type BookSort string
+
+// BookSort values, a leading "-" means descending order
+const (
+	BookSortID        BookSort = "id"
+	BookSortIDDesc    BookSort = "-id"
+	BookSortTitle     BookSort = "title"
+	BookSortTitleDesc BookSort = "-title"
+	BookSortPrice     BookSort = "price"
+	BookSortPriceDesc BookSort = "-price"
+)
+
+// Desc reports whether the sort order is descending
+func (s BookSort) Desc() bool {
+	return len(s) > 0 && s[0] == '-'
+}
+
+// Cursor returns the keyset cursor pointing right after the book
+func (s BookSort) Cursor(book *Book) *Cursor {
+	c := Cursor{ID: book.ID, Sort: string(s)}
+	switch s {
+	case BookSortTitle, BookSortTitleDesc:
+		c.Value = book.Title
+	case BookSortPrice, BookSortPriceDesc:
+		c.Value = book.Price.String()
+	}
+	return &c
 }
@@ -10,9 +10,9 @@ type Property interface {
 }
 
 type common interface {
-	User
+	User | RefreshToken | OutboxMessage | TwoFactor
 }
 
 type business interface {
-	Book | Author
+	Book | Author | SearchHit | AuditRecord
 }
@@ -0,0 +1,22 @@
+package model
+
+// ImportBook is a book row of catalog import, the book refers to an existing author by AuthorID
+// or to Author which is looked up by name and created when it has a date of birth
+type ImportBook struct {
+	Line   int
+	Book   Book
+	Author *Author
+}
+
+// ImportError is an error of a single import row
+type ImportError struct {
+	Line  int
+	Error string
+}
+
+// ImportResult is a result of importing a batch of rows
+type ImportResult struct {
+	Imported       int
+	AuthorsCreated int
+	Errors         []ImportError
+}
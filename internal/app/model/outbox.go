@@ -0,0 +1,49 @@
+package model
+
+import (
+	"encoding/json"
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"time"
+)
+
+// OutboxKind is a kind of outgoing message
+type OutboxKind string
+
+// OutboxKind values
+const (
+	OutboxKindActivationMail    OutboxKind = "activation_mail"
+	OutboxKindResetPasswordMail OutboxKind = "reset_password_mail"
+	OutboxKindChangeEmailMail   OutboxKind = "change_email_mail"
+	OutboxKindUserLockedMail    OutboxKind = "user_locked_mail"
+)
+
+// OutboxStatus is a delivery status of outgoing message
+type OutboxStatus string
+
+// OutboxStatus values, a message is dead once it runs out of attempts
+const (
+	OutboxStatusPending OutboxStatus = "pending"
+	OutboxStatusSent    OutboxStatus = "sent"
+	OutboxStatusDead    OutboxStatus = "dead"
+)
+
+// OutboxMessage model
+type OutboxMessage struct {
+	ID            types.ID        `db:"outbox_id"`
+	Kind          OutboxKind      `db:"kind"`
+	Recipient     types.Username  `db:"recipient"`
+	Payload       json.RawMessage `db:"payload"`
+	Status        OutboxStatus    `db:"status"`
+	Attempts      int             `db:"attempts"`
+	LastError     *string         `db:"last_error"`
+	NextAttemptAt time.Time       `db:"next_attempt_at"`
+	CreatedAt     time.Time       `db:"created_at"`
+}
+
+// MailPayload is the payload of mail messages, security notifications carry the lock and the IP instead of otp
+type MailPayload struct {
+	OTP         types.Token `json:"otp,omitempty"`
+	Language    string      `json:"language,omitempty"`
+	LockedUntil *time.Time  `json:"locked_until,omitempty"`
+	IP          string      `json:"ip,omitempty"`
+}
@@ -0,0 +1,29 @@
+package model
+
+import "github.com/vlaship/book-catalog-go/internal/app/types"
+
+// Cursor is a keyset position: the ID of the last seen row and, for non-ID sorts, its sort value
+type Cursor struct {
+	ID    types.ID `json:"id"`
+	Value string   `json:"v,omitempty"`
+	Sort  string   `json:"s,omitempty"`
+}
+
+// Page is a page of items with the cursor of the next page
+type Page[T any] struct {
+	Items []T
+	Next  *Cursor
+}
+
+// NewPage creates a page from items fetched with limit+1, trimming the extra row into the next cursor
+func NewPage[T any](items []T, limit int, cursor func(t *T) *Cursor) *Page[T] {
+	if len(items) <= limit {
+		return &Page[T]{Items: items}
+	}
+
+	items = items[:limit]
+	return &Page[T]{
+		Items: items,
+		Next:  cursor(&items[limit-1]),
+	}
+}
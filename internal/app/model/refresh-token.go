@@ -0,0 +1,17 @@
+package model
+
+import (
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"time"
+)
+
+// RefreshToken model, only the hash of the opaque token is stored
+type RefreshToken struct {
+	ID        types.ID     `db:"token_id"`
+	Hash      string       `db:"token_hash"`
+	FamilyID  types.ID     `db:"family_id"`
+	UserID    types.UserID `db:"user_id"`
+	ExpiresAt time.Time    `db:"expires_at"`
+	UsedAt    *time.Time   `db:"used_at"`
+	RevokedAt *time.Time   `db:"revoked_at"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import "github.com/vlaship/book-catalog-go/internal/app/types"
+
+// SearchKind is a kind of search hit
+type SearchKind string
+
+// SearchKind values
+const (
+	SearchKindBook   SearchKind = "book"
+	SearchKindAuthor SearchKind = "author"
+)
+
+// SearchHit model
+type SearchHit struct {
+	Kind    SearchKind `db:"kind"`
+	ID      types.ID   `db:"id"`
+	Title   string     `db:"title"`
+	Snippet string     `db:"snippet"`
+	Rank    float32    `db:"rank"`
+}
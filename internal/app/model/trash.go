@@ -0,0 +1,17 @@
+package model
+
+// TrashFilter is a filter for listing of soft deleted entities
+type TrashFilter struct {
+	Limit  int
+	Cursor *Cursor
+}
+
+// BookTrashCursor returns the keyset cursor pointing right after the trashed book
+func BookTrashCursor(book *Book) *Cursor {
+	return &Cursor{ID: book.ID}
+}
+
+// AuthorTrashCursor returns the keyset cursor pointing right after the trashed author
+func AuthorTrashCursor(author *Author) *Cursor {
+	return &Cursor{ID: author.ID}
+}
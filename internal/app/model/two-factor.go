@@ -0,0 +1,21 @@
+package model
+
+import "github.com/vlaship/book-catalog-go/internal/app/types"
+
+// TwoFactor is the TOTP two-factor authentication of user, the secret is encrypted
+// and the recovery codes are hashed, a pending enrollment has a secret but is not enabled yet
+type TwoFactor struct {
+	UserID        types.UserID `db:"user_id"`
+	Secret        []byte       `db:"totp_secret"`
+	Enabled       bool         `db:"totp_enabled"`
+	RecoveryCodes []string     `db:"totp_recovery_codes"`
+	LastStep      int64        `db:"totp_last_step"`
+}
+
+// TwoFactorEnrollment is what the user needs to set up an authenticator app,
+// it is shown once, the recovery codes are not stored in plain
+type TwoFactorEnrollment struct {
+	URI           string
+	Secret        string
+	RecoveryCodes []string
+}
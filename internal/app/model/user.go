@@ -5,6 +5,7 @@ import (
 	"github.com/vlaship/book-catalog-go/internal/app/types"
 	"github.com/vlaship/book-catalog-go/internal/apperr"
 	"github.com/vlaship/go-mask"
+	"time"
 )
 
 // User is a model for user
@@ -13,6 +14,17 @@ type User struct {
 	Username types.Username `db:"username"`
 	Password types.Password `db:"password"`
 	Data     UserData       `jsonb:"user_data"`
+	// CreatedAt is read by the lookup by ID and the user listing only
+	CreatedAt time.Time `db:"created_at"`
+	// TwoFactor reports whether the user signs in with a TOTP code
+	TwoFactor bool `db:"totp_enabled"`
+	// LockedUntil is set when the user is temporarily locked after too many failed signins
+	LockedUntil *time.Time `db:"locked_until"`
+}
+
+// IsLocked reports whether the user is temporarily locked at the time
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
 }
 
 // String
@@ -32,6 +44,8 @@ type UserData struct {
 	Email     string     `json:"email,omitempty"`
 	Plan      string     `json:"user_plan,omitempty"`
 	Status    UserStatus `json:"status,omitempty"`
+	Role      UserRole   `json:"role,omitempty"`
+	Language  string     `json:"language,omitempty"`
 }
 
 // String
@@ -51,8 +65,39 @@ type UserStatus string
 const (
 	UserStatusActive       UserStatus = ""
 	UserStatusNotActivated UserStatus = "user_status_not_activated"
+	UserStatusSuspended    UserStatus = "user_status_suspended"
+	UserStatusLocked       UserStatus = "user_status_locked"
+)
+
+// UserRole codes
+type UserRole string
+
+// UserRole codes, each role includes the permissions of the previous one
+const (
+	UserRoleReader UserRole = "reader"
+	UserRoleEditor UserRole = "editor"
+	UserRoleAdmin  UserRole = "admin"
 )
 
+var userRoleLevels = map[UserRole]int{
+	UserRoleReader: 1,
+	UserRoleEditor: 2,
+	UserRoleAdmin:  3,
+}
+
+// Includes reports whether the role has the permissions of the other role
+func (r UserRole) Includes(other UserRole) bool {
+	return userRoleLevels[r] >= userRoleLevels[other]
+}
+
+// GetRole returns user role, users without role are readers
+func (u *User) GetRole() UserRole {
+	if _, ok := userRoleLevels[u.Data.Role]; !ok {
+		return UserRoleReader
+	}
+	return u.Data.Role
+}
+
 // GetAppError by user status
 func (u *User) GetAppError() error {
 	switch u.Data.Status {
@@ -60,7 +105,31 @@ func (u *User) GetAppError() error {
 		return nil
 	case UserStatusNotActivated:
 		return apperr.ErrUserNotActivated
+	case UserStatusSuspended:
+		return apperr.ErrUserSuspended
+	case UserStatusLocked:
+		return apperr.ErrUserLocked
 	default:
 		return apperr.ErrForbidden
 	}
 }
+
+// UserFilter is a filter for user listing, Email matches a part of the username
+type UserFilter struct {
+	Limit  int
+	Cursor *Cursor
+	Status *UserStatus
+	Email  string
+}
+
+// UserCursor returns the keyset cursor pointing right after the user
+func UserCursor(user *User) *Cursor {
+	return &Cursor{ID: types.ID(user.ID)}
+}
+
+// UserPatch is a partial update of the account of a user, nil fields are left as they are
+type UserPatch struct {
+	Status *UserStatus
+	Role   *UserRole
+	Plan   *string
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"github.com/vlaship/book-catalog-go/internal/database"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+)
+
+// AuditRepository is an interface for audit log repository
+type AuditRepository struct {
+	pool database.ConnPool
+	log  logger.Logger
+}
+
+// NewAuditRepository creates new audit log repository
+func NewAuditRepository(pool database.ConnPool, log logger.Logger) *AuditRepository {
+	return &AuditRepository{
+		pool: pool,
+		log:  log.New("AuditRepository"),
+	}
+}
+
+func (r *AuditRepository) l() logger.Logger {
+	return r.log
+}
+
+func (r *AuditRepository) p() database.ConnPool {
+	return r.pool
+}
+
+const entityNameAuditRecord = "audit record"
+
+const (
+	insertAuditRecord = `
+	INSERT INTO catalog.audit_log (audit_id, action, entity_type, entity_id, user_id, request_id, before, after)
+	VALUES ($1, $2, $3, $4, $5, $6, $7::JSONB, $8::JSONB)
+	RETURNING audit_id, created_at;
+`
+	getAuditRecords = `
+	SELECT audit_id, action, entity_type, entity_id, user_id, request_id, before, after, created_at
+	FROM catalog.audit_log
+	WHERE TRUE`
+)
+
+// CreateAuditRecord inserts new audit record, it joins the transaction carried by ctx if any
+func (r *AuditRepository) CreateAuditRecord(ctx context.Context, record *model.AuditRecord) (*model.AuditRecord, error) {
+	r.log.Dbg().Ctx(ctx).Values("action", record.Action, "entity", record.EntityType, "entityID", record.EntityID).
+		Msg("CreateAuditRecord")
+
+	req := entity[model.AuditRecord]{
+		query:      insertAuditRecord,
+		entityName: entityNameAuditRecord,
+		args: []any{
+			record.ID,
+			record.Action,
+			record.EntityType,
+			record.EntityID,
+			record.UserID,
+			record.RequestID,
+			jsonArg(record.Before),
+			jsonArg(record.After),
+		},
+		destinations: func(out *model.AuditRecord) []any {
+			return []any{&out.ID, &out.CreatedAt}
+		},
+	}
+
+	return create(ctx, r, req)
+}
+
+// CopyAuditRecords inserts audit records in bulk, it joins the transaction carried by ctx if any
+func (r *AuditRepository) CopyAuditRecords(ctx context.Context, records []model.AuditRecord) error {
+	r.log.Dbg().Ctx(ctx).Values("records", len(records)).Msg("CopyAuditRecords")
+
+	rows := make([][]any, len(records))
+	for i := range records {
+		rec := &records[i]
+		rows[i] = []any{
+			rec.ID,
+			rec.Action,
+			rec.EntityType,
+			rec.EntityID,
+			rec.UserID,
+			rec.RequestID,
+			jsonArg(rec.Before),
+			jsonArg(rec.After),
+		}
+	}
+
+	req := copyRequest{
+		table:      "audit_log",
+		entityName: entityNameAuditRecord,
+		columns:    []string{"audit_id", "action", "entity_type", "entity_id", "user_id", "request_id", "before", "after"},
+		rows:       rows,
+	}
+
+	return copyFrom(ctx, r, req)
+}
+
+// GetAuditRecords returns audit records by filter, newest first
+func (r *AuditRepository) GetAuditRecords(ctx context.Context, filter model.AuditFilter) ([]model.AuditRecord, error) {
+	r.log.Trc().Ctx(ctx).Values("filter", filter).Msg("GetAuditRecords")
+
+	q := newQueryBuilder(getAuditRecords)
+	if filter.EntityType != "" {
+		q.and("entity_type = " + q.arg(filter.EntityType))
+	}
+	if filter.EntityID != nil {
+		q.and("entity_id = " + q.arg(*filter.EntityID))
+	}
+	if filter.Cursor != nil {
+		q.and("audit_id < " + q.arg(filter.Cursor.ID))
+	}
+	q.write(" ORDER BY audit_id DESC LIMIT " + q.arg(filter.Limit))
+
+	req := entity[model.AuditRecord]{
+		query:      q.query(),
+		entityName: entityNameAuditRecord,
+		args:       q.args,
+		destinations: func(record *model.AuditRecord) []any {
+			return []any{
+				&record.ID,
+				&record.Action,
+				&record.EntityType,
+				&record.EntityID,
+				&record.UserID,
+				&record.RequestID,
+				&record.Before,
+				&record.After,
+				&record.CreatedAt,
+			}
+		},
+	}
+
+	return getAll(ctx, r, req)
+}
+
+// jsonArg passes raw JSON as text so that an empty value is stored as NULL
+func jsonArg(raw json.RawMessage) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
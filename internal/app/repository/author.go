@@ -2,10 +2,12 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"github.com/vlaship/book-catalog-go/internal/app/model"
 	"github.com/vlaship/book-catalog-go/internal/app/types"
 	"github.com/vlaship/book-catalog-go/internal/database"
 	"github.com/vlaship/book-catalog-go/internal/logger"
+	"time"
 )
 
 // AuthorRepository is an interface for author repository
@@ -34,12 +36,17 @@ const entityNameAuthor = "author"
 
 const (
 	getAuthors = `
-	SELECT author_id, author_name, author_dob
+	SELECT author_id, author_name, author_dob, version
 	FROM catalog.authors WHERE deleted = FALSE;
 `
 	getAuthorByID = `
-	SELECT author_id, author_name, author_dob
+	SELECT author_id, author_name, author_dob, version
 	FROM catalog.authors WHERE author_id = $1 AND deleted = FALSE;
+`
+	lockAuthorByID = `
+	SELECT author_id, author_name, author_dob, version
+	FROM catalog.authors WHERE author_id = $1 AND deleted = FALSE
+	FOR UPDATE;
 `
 	insertAuthor = `
 	INSERT INTO catalog.authors (author_id, author_name, author_dob)
@@ -48,11 +55,45 @@ const (
 `
 	updateAuthor = `
 	UPDATE catalog.authors
-	SET author_name = $2, author_dob = $3, updated_at = now()
-	WHERE author_id = $1 AND deleted = FALSE;
+	SET author_name = $2, author_dob = $3
+	WHERE author_id = $1 AND deleted = FALSE AND ($4::BIGINT = 0 OR version = $4);
 `
 	deleteAuthor = `
-	UPDATE catalog.authors SET deleted = TRUE WHERE author_id = $1;
+	UPDATE catalog.authors SET deleted = TRUE, deleted_at = now()
+	WHERE author_id = $1 AND deleted = FALSE AND ($2::BIGINT = 0 OR version = $2);
+`
+	getAuthorsByIDs = `
+	SELECT author_id, author_name, author_dob, version
+	FROM catalog.authors WHERE author_id = ANY($1) AND deleted = FALSE;
+`
+	getAuthorsByNames = `
+	SELECT author_id, author_name, author_dob, version
+	FROM catalog.authors WHERE lower(author_name) = ANY($1) AND deleted = FALSE
+	ORDER BY author_id;
+`
+	getTrashedAuthors = `
+	SELECT author_id, author_name, author_dob, version, deleted_at
+	FROM catalog.authors
+	WHERE deleted = TRUE`
+	lockTrashedAuthorByID = `
+	SELECT author_id, author_name, author_dob, version, deleted_at
+	FROM catalog.authors WHERE author_id = $1 AND deleted = TRUE
+	FOR UPDATE;
+`
+	restoreAuthor = `
+	UPDATE catalog.authors SET deleted = FALSE, deleted_at = NULL
+	WHERE author_id = $1 AND deleted = TRUE;
+`
+	purgeAuthor = `
+	DELETE FROM catalog.authors
+	WHERE author_id = $1 AND deleted = TRUE;
+`
+	// authors that still have books, trashed ones not yet expired included, are kept
+	purgeExpiredAuthors = `
+	DELETE FROM catalog.authors a
+	WHERE a.deleted = TRUE AND a.deleted_at < $1
+	AND NOT EXISTS (SELECT 1 FROM catalog.books b WHERE b.author_id = a.author_id)
+	RETURNING a.author_id, a.author_name, a.author_dob, a.version, a.deleted_at;
 `
 )
 
@@ -68,6 +109,7 @@ func (r *AuthorRepository) GetAuthors(ctx context.Context) ([]model.Author, erro
 				&author.ID,
 				&author.Name,
 				&author.Dob,
+				&author.Version,
 			}
 		},
 	}
@@ -88,6 +130,28 @@ func (r *AuthorRepository) GetAuthor(ctx context.Context, authorID types.ID) (*m
 				&author.ID,
 				&author.Name,
 				&author.Dob,
+				&author.Version,
+			}
+		},
+	}
+
+	return getOne(ctx, r, req)
+}
+
+// LockAuthor returns author by id and locks it until the end of the transaction carried by ctx
+func (r *AuthorRepository) LockAuthor(ctx context.Context, authorID types.ID) (*model.Author, error) {
+	r.log.Dbg().Ctx(ctx).Values("authorID", authorID).Msg("LockAuthor")
+
+	req := entity[model.Author]{
+		query:      lockAuthorByID,
+		entityName: entityNameAuthor,
+		args:       []any{authorID},
+		destinations: func(author *model.Author) []any {
+			return []any{
+				&author.ID,
+				&author.Name,
+				&author.Dob,
+				&author.Version,
 			}
 		},
 	}
@@ -109,13 +173,14 @@ func (r *AuthorRepository) CreateAuthor(ctx context.Context, author *model.Autho
 	return create(ctx, r, req)
 }
 
-// UpdateAuthor updates author
+// UpdateAuthor updates author, a non-zero version must match the current one
 func (r *AuthorRepository) UpdateAuthor(
 	ctx context.Context,
 	authorID types.ID,
 	author *model.Author,
+	version int64,
 ) error {
-	r.log.Dbg().Ctx(ctx).Values("author", author).Msg("UpdateAuthor")
+	r.log.Dbg().Ctx(ctx).Values("author", author, "version", version).Msg("UpdateAuthor")
 
 	req := execRequest{
 		query:      updateAuthor,
@@ -124,21 +189,206 @@ func (r *AuthorRepository) UpdateAuthor(
 			authorID,
 			author.Name,
 			author.Dob,
+			version,
 		},
 	}
 
 	return exec(ctx, r, req)
 }
 
-// DeleteAuthor deletes author
-func (r *AuthorRepository) DeleteAuthor(ctx context.Context, authorID types.ID) error {
-	r.log.Dbg().Ctx(ctx).Values("authorID", authorID).Msg("DeleteAuthor")
+// DeleteAuthor deletes author, a non-zero version must match the current one
+func (r *AuthorRepository) DeleteAuthor(ctx context.Context, authorID types.ID, version int64) error {
+	r.log.Dbg().Ctx(ctx).Values("authorID", authorID, "version", version).Msg("DeleteAuthor")
 
 	req := execRequest{
 		query:      deleteAuthor,
 		entityName: entityNameAuthor,
+		args:       []any{authorID, version},
+	}
+
+	return exec(ctx, r, req)
+}
+
+// GetAuthorsByIDs returns authors by ids, unknown ids are skipped
+func (r *AuthorRepository) GetAuthorsByIDs(ctx context.Context, authorIDs []types.ID) ([]model.Author, error) {
+	r.log.Dbg().Ctx(ctx).Values("authorIDs", len(authorIDs)).Msg("GetAuthorsByIDs")
+
+	req := entity[model.Author]{
+		query:        getAuthorsByIDs,
+		entityName:   entityNameAuthor,
+		args:         []any{authorIDs},
+		destinations: authorColumns,
+	}
+
+	return getAll(ctx, r, req)
+}
+
+// GetAuthorsByNames returns authors by lower case names, the oldest author comes first for a repeated name
+func (r *AuthorRepository) GetAuthorsByNames(ctx context.Context, names []string) ([]model.Author, error) {
+	r.log.Dbg().Ctx(ctx).Values("names", len(names)).Msg("GetAuthorsByNames")
+
+	req := entity[model.Author]{
+		query:        getAuthorsByNames,
+		entityName:   entityNameAuthor,
+		args:         []any{names},
+		destinations: authorColumns,
+	}
+
+	return getAll(ctx, r, req)
+}
+
+// CopyAuthors inserts authors in bulk
+func (r *AuthorRepository) CopyAuthors(ctx context.Context, authors []model.Author) error {
+	r.log.Dbg().Ctx(ctx).Values("authors", len(authors)).Msg("CopyAuthors")
+
+	rows := make([][]any, len(authors))
+	for i := range authors {
+		a := &authors[i]
+		rows[i] = []any{a.ID, a.Name, a.Dob}
+	}
+
+	req := copyRequest{
+		table:      "authors",
+		entityName: entityNameAuthor,
+		columns:    []string{"author_id", "author_name", "author_dob"},
+		rows:       rows,
+	}
+
+	return copyFrom(ctx, r, req)
+}
+
+// authorColumns returns scan destinations of author
+func authorColumns(author *model.Author) []any {
+	return []any{
+		&author.ID,
+		&author.Name,
+		&author.Dob,
+		&author.Version,
+	}
+}
+
+// GetTrashedAuthors returns page of soft deleted authors
+func (r *AuthorRepository) GetTrashedAuthors(ctx context.Context, filter model.TrashFilter) ([]model.Author, error) {
+	r.log.Trc().Ctx(ctx).Values("filter", filter).Msg("GetTrashedAuthors")
+
+	q := newQueryBuilder(getTrashedAuthors)
+	if filter.Cursor != nil {
+		q.and("author_id > " + q.arg(filter.Cursor.ID))
+	}
+	q.write(" ORDER BY author_id LIMIT " + q.arg(filter.Limit))
+
+	req := entity[model.Author]{
+		query:        q.query(),
+		entityName:   entityNameAuthor,
+		args:         q.args,
+		destinations: trashedAuthorDestinations,
+	}
+
+	return getAll(ctx, r, req)
+}
+
+// LockTrashedAuthor returns soft deleted author by id and locks it until the end of the transaction carried by ctx
+func (r *AuthorRepository) LockTrashedAuthor(ctx context.Context, authorID types.ID) (*model.Author, error) {
+	r.log.Dbg().Ctx(ctx).Values("authorID", authorID).Msg("LockTrashedAuthor")
+
+	req := entity[model.Author]{
+		query:        lockTrashedAuthorByID,
+		entityName:   entityNameAuthor,
+		args:         []any{authorID},
+		destinations: trashedAuthorDestinations,
+	}
+
+	return getOne(ctx, r, req)
+}
+
+// RestoreAuthor undoes soft delete of author
+func (r *AuthorRepository) RestoreAuthor(ctx context.Context, authorID types.ID) error {
+	r.log.Dbg().Ctx(ctx).Values("authorID", authorID).Msg("RestoreAuthor")
+
+	req := execRequest{
+		query:      restoreAuthor,
+		entityName: entityNameAuthor,
 		args:       []any{authorID},
 	}
 
 	return exec(ctx, r, req)
 }
+
+// PurgeAuthor permanently deletes soft deleted author
+func (r *AuthorRepository) PurgeAuthor(ctx context.Context, authorID types.ID) error {
+	r.log.Dbg().Ctx(ctx).Values("authorID", authorID).Msg("PurgeAuthor")
+
+	req := execRequest{
+		query:      purgeAuthor,
+		entityName: entityNameAuthor,
+		args:       []any{authorID},
+	}
+
+	return exec(ctx, r, req)
+}
+
+// PurgeExpiredAuthors permanently deletes authors soft deleted before the time and returns them
+func (r *AuthorRepository) PurgeExpiredAuthors(ctx context.Context, before time.Time) ([]model.Author, error) {
+	r.log.Dbg().Ctx(ctx).Values("before", before).Msg("PurgeExpiredAuthors")
+
+	req := entity[model.Author]{
+		query:        purgeExpiredAuthors,
+		entityName:   entityNameAuthor,
+		args:         []any{before},
+		destinations: trashedAuthorDestinations,
+	}
+
+	return getAll(ctx, r, req)
+}
+
+// trashedAuthorDestinations returns scan destinations of soft deleted author
+func trashedAuthorDestinations(author *model.Author) []any {
+	return []any{
+		&author.ID,
+		&author.Name,
+		&author.Dob,
+		&author.Version,
+		&author.DeletedAt,
+	}
+}
+
+// joinedAuthor scans a column of left joined author, the author stays nil when the columns are NULL
+type joinedAuthor struct {
+	author **model.Author
+	set    func(author *model.Author, src any) bool
+}
+
+// Scan implements sql.Scanner
+func (j joinedAuthor) Scan(src any) error {
+	if src == nil {
+		return nil
+	}
+	if *j.author == nil {
+		*j.author = &model.Author{}
+	}
+	if !j.set(*j.author, src) {
+		return fmt.Errorf("unsupported type %T of author column", src)
+	}
+	return nil
+}
+
+// authorDestinations returns scan destinations of left joined author columns: id, name, dob
+func authorDestinations(author **model.Author) []any {
+	return []any{
+		joinedAuthor{author: author, set: func(a *model.Author, src any) bool {
+			id, ok := src.(int64)
+			a.ID = types.ID(id)
+			return ok
+		}},
+		joinedAuthor{author: author, set: func(a *model.Author, src any) bool {
+			name, ok := src.(string)
+			a.Name = name
+			return ok
+		}},
+		joinedAuthor{author: author, set: func(a *model.Author, src any) bool {
+			dob, ok := src.(time.Time)
+			a.Dob = dob
+			return ok
+		}},
+	}
+}
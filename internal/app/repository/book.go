@@ -2,10 +2,12 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"github.com/vlaship/book-catalog-go/internal/app/model"
 	"github.com/vlaship/book-catalog-go/internal/app/types"
 	"github.com/vlaship/book-catalog-go/internal/database"
 	"github.com/vlaship/book-catalog-go/internal/logger"
+	"time"
 )
 
 // BookRepository is an interface for book repository
@@ -20,18 +22,36 @@ const (
 
 const (
 	getBooks = `
-	SELECT book_id, book_title, book_desc, book_isbn, author_id, book_price
-	FROM catalog.books
-	WHERE deleted = FALSE;
-`
+	SELECT b.book_id, b.book_title, b.book_desc, b.book_isbn, b.author_id, b.book_price, b.version%s
+	FROM catalog.books b%s
+	WHERE b.deleted = FALSE`
 	getBookByID = `
-	SELECT book_id, book_title, book_desc, book_isbn, author_id, book_price
-	FROM catalog.books
-	WHERE book_id = $1 AND deleted = FALSE;
+	SELECT b.book_id, b.book_title, b.book_desc, b.book_isbn, b.author_id, b.book_price, b.version%s
+	FROM catalog.books b%s
+	WHERE b.book_id = $1 AND b.deleted = FALSE;
+`
+	getBookByISBN = `
+	SELECT b.book_id, b.book_title, b.book_desc, b.book_isbn, b.author_id, b.book_price, b.version%s
+	FROM catalog.books b%s
+	WHERE b.book_isbn = $1 AND b.deleted = FALSE;
+`
+	getBooksByISBNs = `
+	SELECT b.book_id, b.book_title, b.book_desc, b.book_isbn, b.author_id, b.book_price, b.version
+	FROM catalog.books b
+	WHERE b.book_isbn = ANY($1) AND b.deleted = FALSE;
 `
+	lockBookByID = `
+	SELECT b.book_id, b.book_title, b.book_desc, b.book_isbn, b.author_id, b.book_price, b.version
+	FROM catalog.books b
+	WHERE b.book_id = $1 AND b.deleted = FALSE
+	FOR UPDATE;
+`
+	bookAuthorColumns = `, a.author_id, a.author_name, a.author_dob`
+	bookAuthorJoin    = `
+	LEFT JOIN catalog.authors a ON a.author_id = b.author_id AND a.deleted = FALSE`
 	updateBookByID = `
 	UPDATE catalog.books SET book_title = $2, book_desc = $3, book_isbn = $4, author_id = $5, book_price = $6
-	WHERE book_id = $1 AND deleted = FALSE;
+	WHERE book_id = $1 AND deleted = FALSE AND ($7::BIGINT = 0 OR version = $7);
 `
 	insertBook = `
 	INSERT INTO catalog.books (book_id, book_title, book_desc, book_isbn, author_id, book_price)
@@ -39,10 +59,55 @@ const (
 	RETURNING book_id;
 `
 	deleteBookByID = `
-	UPDATE catalog.books SET deleted = TRUE WHERE book_id = $1;
+	UPDATE catalog.books SET deleted = TRUE, deleted_at = now()
+	WHERE book_id = $1 AND deleted = FALSE AND ($2::BIGINT = 0 OR version = $2);
+`
+	trashedBookColumns = `book_id, book_title, book_desc, book_isbn, author_id, book_price, version, deleted_at`
+	getTrashedBooks    = `
+	SELECT ` + trashedBookColumns + `
+	FROM catalog.books
+	WHERE deleted = TRUE`
+	lockTrashedBookByID = `
+	SELECT ` + trashedBookColumns + `
+	FROM catalog.books
+	WHERE book_id = $1 AND deleted = TRUE
+	FOR UPDATE;
+`
+	restoreBookByID = `
+	UPDATE catalog.books SET deleted = FALSE, deleted_at = NULL
+	WHERE book_id = $1 AND deleted = TRUE;
+`
+	purgeBookByID = `
+	DELETE FROM catalog.books
+	WHERE book_id = $1 AND deleted = TRUE;
+`
+	purgeBooksByAuthorID = `
+	DELETE FROM catalog.books
+	WHERE author_id = $1 AND deleted = TRUE
+	RETURNING ` + trashedBookColumns + `;
+`
+	purgeExpiredBooks = `
+	DELETE FROM catalog.books
+	WHERE deleted = TRUE AND deleted_at < $1
+	RETURNING ` + trashedBookColumns + `;
 `
 )
 
+// bookSortKey is a column and its type used for keyset pagination
+type bookSortKey struct {
+	column string
+	cast   string
+}
+
+var bookSortKeys = map[model.BookSort]bookSortKey{
+	model.BookSortID:        {column: "b.book_id"},
+	model.BookSortIDDesc:    {column: "b.book_id"},
+	model.BookSortTitle:     {column: "b.book_title", cast: "text"},
+	model.BookSortTitleDesc: {column: "b.book_title", cast: "text"},
+	model.BookSortPrice:     {column: "b.book_price", cast: "numeric"},
+	model.BookSortPriceDesc: {column: "b.book_price", cast: "numeric"},
+}
+
 // NewBookRepository creates new book repository
 func NewBookRepository(pool database.ConnPool, log logger.Logger) *BookRepository {
 	return &BookRepository{
@@ -59,51 +124,175 @@ func (r *BookRepository) p() database.ConnPool {
 	return r.pool
 }
 
-// GetBooks get list of books
-func (r *BookRepository) GetBooks(ctx context.Context) ([]model.Book, error) {
-	r.log.Trc().Ctx(ctx).Msg("GetBooks")
+// GetBooks get page of books by filter
+func (r *BookRepository) GetBooks(ctx context.Context, filter model.BookFilter) ([]model.Book, error) {
+	r.log.Trc().Ctx(ctx).Values("filter", filter).Msg("GetBooks")
+
+	q := r.listQuery(filter)
+	q.write(" LIMIT " + q.arg(filter.Limit))
 
 	req := entity[model.Book]{
-		query:      getBooks,
+		query:      q.query(),
 		entityName: entityNameBook,
+		args:       q.args,
 		destinations: func(book *model.Book) []any {
-			return []any{
-				&book.ID,
-				&book.Title,
-				&book.Description,
-				&book.ISBN,
-				&book.AuthorID,
-				&book.Price,
-			}
+			return r.destinations(book, filter.Expand)
 		},
 	}
 
 	return getAll(ctx, r, req)
 }
 
+// StreamBooks calls fn for every book by filter in the sort order, the limit of the filter is ignored
+// and the books are not collected in memory
+func (r *BookRepository) StreamBooks(ctx context.Context, filter model.BookFilter, fn func(book *model.Book) error) error {
+	r.log.Trc().Ctx(ctx).Values("filter", filter).Msg("StreamBooks")
+
+	q := r.listQuery(filter)
+
+	req := entity[model.Book]{
+		query:      q.query(),
+		entityName: entityNameBook,
+		args:       q.args,
+		destinations: func(book *model.Book) []any {
+			return r.destinations(book, filter.Expand)
+		},
+	}
+
+	return forEach(ctx, r, req, fn)
+}
+
+// listQuery builds the query of books by filter with keyset condition and order
+func (r *BookRepository) listQuery(filter model.BookFilter) *queryBuilder {
+	q := newQueryBuilder(r.withExpand(getBooks, filter.Expand))
+	if filter.AuthorID != nil {
+		q.and("b.author_id = " + q.arg(*filter.AuthorID))
+	}
+	if filter.MinPrice != nil {
+		q.and("b.book_price >= " + q.arg(*filter.MinPrice))
+	}
+	if filter.MaxPrice != nil {
+		q.and("b.book_price <= " + q.arg(*filter.MaxPrice))
+	}
+	if filter.Title != "" {
+		q.and("b.book_title ILIKE " + q.arg(contains(filter.Title)))
+	}
+
+	key, ok := bookSortKeys[filter.Sort]
+	if !ok {
+		key = bookSortKeys[model.BookSortID]
+	}
+	op, dir := ">", "ASC"
+	if filter.Sort.Desc() {
+		op, dir = "<", "DESC"
+	}
+
+	if c := filter.Cursor; c != nil {
+		if key.cast == "" {
+			q.and(fmt.Sprintf("b.book_id %s %s", op, q.arg(c.ID)))
+		} else {
+			q.and(fmt.Sprintf("(%s, b.book_id) %s (%s::%s, %s)", key.column, op, q.arg(c.Value), key.cast, q.arg(c.ID)))
+		}
+	}
+
+	if key.cast == "" {
+		q.write(fmt.Sprintf(" ORDER BY b.book_id %s", dir))
+	} else {
+		q.write(fmt.Sprintf(" ORDER BY %s %s, b.book_id %s", key.column, dir, dir))
+	}
+
+	return q
+}
+
 // GetBook get book by ID
-func (r *BookRepository) GetBook(ctx context.Context, bookID types.ID) (*model.Book, error) {
-	r.log.Dbg().Ctx(ctx).Values("bookID", bookID).Msg("GetBook")
+func (r *BookRepository) GetBook(ctx context.Context, bookID types.ID, expand model.BookExpand) (*model.Book, error) {
+	r.log.Dbg().Ctx(ctx).Values("bookID", bookID, "expand", expand).Msg("GetBook")
 
 	req := entity[model.Book]{
-		query:      getBookByID,
+		query:      r.withExpand(getBookByID, expand),
 		entityName: entityNameBook,
 		args:       []any{bookID},
 		destinations: func(book *model.Book) []any {
-			return []any{
-				&book.ID,
-				&book.Title,
-				&book.Description,
-				&book.ISBN,
-				&book.AuthorID,
-				&book.Price,
-			}
+			return r.destinations(book, expand)
+		},
+	}
+
+	return getOne(ctx, r, req)
+}
+
+// GetBookByISBN get book by normalized ISBN
+func (r *BookRepository) GetBookByISBN(ctx context.Context, isbn string, expand model.BookExpand) (*model.Book, error) {
+	r.log.Dbg().Ctx(ctx).Values("isbn", isbn, "expand", expand).Msg("GetBookByISBN")
+
+	req := entity[model.Book]{
+		query:      r.withExpand(getBookByISBN, expand),
+		entityName: entityNameBook,
+		args:       []any{isbn},
+		destinations: func(book *model.Book) []any {
+			return r.destinations(book, expand)
 		},
 	}
 
 	return getOne(ctx, r, req)
 }
 
+// GetBooksByISBNs get books by normalized ISBNs
+func (r *BookRepository) GetBooksByISBNs(ctx context.Context, isbns []string) ([]model.Book, error) {
+	r.log.Dbg().Ctx(ctx).Values("isbns", len(isbns)).Msg("GetBooksByISBNs")
+
+	req := entity[model.Book]{
+		query:      getBooksByISBNs,
+		entityName: entityNameBook,
+		args:       []any{isbns},
+		destinations: func(book *model.Book) []any {
+			return r.destinations(book, model.BookExpand{})
+		},
+	}
+
+	return getAll(ctx, r, req)
+}
+
+// LockBook get book by ID and lock it until the end of the transaction carried by ctx
+func (r *BookRepository) LockBook(ctx context.Context, bookID types.ID) (*model.Book, error) {
+	r.log.Dbg().Ctx(ctx).Values("bookID", bookID).Msg("LockBook")
+
+	req := entity[model.Book]{
+		query:      lockBookByID,
+		entityName: entityNameBook,
+		args:       []any{bookID},
+		destinations: func(book *model.Book) []any {
+			return r.destinations(book, model.BookExpand{})
+		},
+	}
+
+	return getOne(ctx, r, req)
+}
+
+// withExpand adds columns and joins of expanded relations to query
+func (r *BookRepository) withExpand(query string, expand model.BookExpand) string {
+	if expand.Author {
+		return fmt.Sprintf(query, bookAuthorColumns, bookAuthorJoin)
+	}
+	return fmt.Sprintf(query, "", "")
+}
+
+// destinations returns scan destinations of book and its expanded relations
+func (r *BookRepository) destinations(book *model.Book, expand model.BookExpand) []any {
+	dest := []any{
+		&book.ID,
+		&book.Title,
+		&book.Description,
+		&book.ISBN,
+		&book.AuthorID,
+		&book.Price,
+		&book.Version,
+	}
+	if expand.Author {
+		dest = append(dest, authorDestinations(&book.Author)...)
+	}
+	return dest
+}
+
 // CreateBook create book
 func (r *BookRepository) CreateBook(ctx context.Context, book *model.Book) (*model.Book, error) {
 	r.log.Dbg().Ctx(ctx).Values("book", book).Msg("CreateBook")
@@ -125,13 +314,14 @@ func (r *BookRepository) CreateBook(ctx context.Context, book *model.Book) (*mod
 	return create(ctx, r, req)
 }
 
-// UpdateBook update book by userID and ID
+// UpdateBook update book by ID, a non-zero version must match the current one
 func (r *BookRepository) UpdateBook(
 	ctx context.Context,
 	bookID types.ID,
 	book *model.Book,
+	version int64,
 ) error {
-	r.log.Dbg().Ctx(ctx).Values("bookID", bookID, "book", book).Msg("UpdateBook")
+	r.log.Dbg().Ctx(ctx).Values("bookID", bookID, "book", book, "version", version).Msg("UpdateBook")
 
 	req := execRequest{
 		query:      updateBookByID,
@@ -143,21 +333,135 @@ func (r *BookRepository) UpdateBook(
 			book.ISBN,
 			book.AuthorID,
 			book.Price,
+			version,
 		},
 	}
 
 	return exec(ctx, r, req)
 }
 
-// DeleteBook delete book by ID
-func (r *BookRepository) DeleteBook(ctx context.Context, bookID types.ID) error {
-	r.log.Dbg().Ctx(ctx).Values("bookID", bookID).Msg("DeleteBook")
+// DeleteBook delete book by ID, a non-zero version must match the current one
+func (r *BookRepository) DeleteBook(ctx context.Context, bookID types.ID, version int64) error {
+	r.log.Dbg().Ctx(ctx).Values("bookID", bookID, "version", version).Msg("DeleteBook")
 
 	req := execRequest{
 		query:      deleteBookByID,
 		entityName: entityNameBook,
+		args:       []any{bookID, version},
+	}
+
+	return exec(ctx, r, req)
+}
+
+// CopyBooks insert books in bulk
+func (r *BookRepository) CopyBooks(ctx context.Context, books []model.Book) error {
+	r.log.Dbg().Ctx(ctx).Values("books", len(books)).Msg("CopyBooks")
+
+	rows := make([][]any, len(books))
+	for i := range books {
+		b := &books[i]
+		rows[i] = []any{b.ID, b.Title, b.Description, b.ISBN, b.AuthorID, b.Price}
+	}
+
+	req := copyRequest{
+		table:      "books",
+		entityName: entityNameBook,
+		columns:    []string{"book_id", "book_title", "book_desc", "book_isbn", "author_id", "book_price"},
+		rows:       rows,
+	}
+
+	return copyFrom(ctx, r, req)
+}
+
+// GetTrashedBooks returns page of soft deleted books
+func (r *BookRepository) GetTrashedBooks(ctx context.Context, filter model.TrashFilter) ([]model.Book, error) {
+	r.log.Trc().Ctx(ctx).Values("filter", filter).Msg("GetTrashedBooks")
+
+	q := newQueryBuilder(getTrashedBooks)
+	if filter.Cursor != nil {
+		q.and("book_id > " + q.arg(filter.Cursor.ID))
+	}
+	q.write(" ORDER BY book_id LIMIT " + q.arg(filter.Limit))
+
+	req := entity[model.Book]{
+		query:        q.query(),
+		entityName:   entityNameBook,
+		args:         q.args,
+		destinations: r.trashedDestinations,
+	}
+
+	return getAll(ctx, r, req)
+}
+
+// LockTrashedBook get soft deleted book by ID and lock it until the end of the transaction carried by ctx
+func (r *BookRepository) LockTrashedBook(ctx context.Context, bookID types.ID) (*model.Book, error) {
+	r.log.Dbg().Ctx(ctx).Values("bookID", bookID).Msg("LockTrashedBook")
+
+	req := entity[model.Book]{
+		query:        lockTrashedBookByID,
+		entityName:   entityNameBook,
+		args:         []any{bookID},
+		destinations: r.trashedDestinations,
+	}
+
+	return getOne(ctx, r, req)
+}
+
+// RestoreBook undo soft delete of book by ID
+func (r *BookRepository) RestoreBook(ctx context.Context, bookID types.ID) error {
+	r.log.Dbg().Ctx(ctx).Values("bookID", bookID).Msg("RestoreBook")
+
+	req := execRequest{
+		query:      restoreBookByID,
+		entityName: entityNameBook,
+		args:       []any{bookID},
+	}
+
+	return exec(ctx, r, req)
+}
+
+// PurgeBook permanently delete soft deleted book by ID
+func (r *BookRepository) PurgeBook(ctx context.Context, bookID types.ID) error {
+	r.log.Dbg().Ctx(ctx).Values("bookID", bookID).Msg("PurgeBook")
+
+	req := execRequest{
+		query:      purgeBookByID,
+		entityName: entityNameBook,
 		args:       []any{bookID},
 	}
 
 	return exec(ctx, r, req)
 }
+
+// PurgeAuthorBooks permanently delete soft deleted books of the author and return them
+func (r *BookRepository) PurgeAuthorBooks(ctx context.Context, authorID types.ID) ([]model.Book, error) {
+	r.log.Dbg().Ctx(ctx).Values("authorID", authorID).Msg("PurgeAuthorBooks")
+
+	req := entity[model.Book]{
+		query:        purgeBooksByAuthorID,
+		entityName:   entityNameBook,
+		args:         []any{authorID},
+		destinations: r.trashedDestinations,
+	}
+
+	return getAll(ctx, r, req)
+}
+
+// PurgeExpiredBooks permanently delete books soft deleted before the time and return them
+func (r *BookRepository) PurgeExpiredBooks(ctx context.Context, before time.Time) ([]model.Book, error) {
+	r.log.Dbg().Ctx(ctx).Values("before", before).Msg("PurgeExpiredBooks")
+
+	req := entity[model.Book]{
+		query:        purgeExpiredBooks,
+		entityName:   entityNameBook,
+		args:         []any{before},
+		destinations: r.trashedDestinations,
+	}
+
+	return getAll(ctx, r, req)
+}
+
+// trashedDestinations returns scan destinations of soft deleted book
+func (r *BookRepository) trashedDestinations(book *model.Book) []any {
+	return append(r.destinations(book, model.BookExpand{}), &book.DeletedAt)
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/vlaship/book-catalog-go/internal/logger"
 )
 
+const schema = "catalog"
+
 // Repo is an interface for repositories
 //
 //go:generate mockgen -destination=../../../test/mock/repository/mock-repo.go -package=mocks . Repo
@@ -21,6 +23,8 @@ type execRequest struct {
 	query      string
 	entityName string
 	args       []any
+	// anyRows skips the check that exactly one row is affected
+	anyRows bool
 }
 
 // entity is a struct for get one/all request
@@ -31,6 +35,14 @@ type entity[T model.Entity] struct {
 	destinations func(t *T) []any
 }
 
+// copyRequest is a struct for bulk insert request
+type copyRequest struct {
+	table      string
+	entityName string
+	columns    []string
+	rows       [][]any
+}
+
 // getProperty is a struct for get property request
 type getProperty[T model.Property] struct {
 	propertyName string
@@ -41,10 +53,9 @@ func getOne[T model.Entity](
 	r Repo,
 	req entity[T],
 ) (*T, error) {
-	tx, err := r.p().Begin(ctx)
+	tx, err := begin(ctx, r)
 	if err != nil {
-		r.l().Err(err).Ctx(ctx).Msg(database.FailedBeginTransaction)
-		return nil, database.GetErrorByCode(err)
+		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
@@ -79,10 +90,9 @@ func getAll[T model.Entity](
 	r Repo,
 	req entity[T],
 ) ([]T, error) {
-	tx, err := r.p().Begin(ctx)
+	tx, err := begin(ctx, r)
 	if err != nil {
-		r.l().Err(err).Ctx(ctx).Msg(database.FailedBeginTransaction)
-		return nil, database.GetErrorByCode(err)
+		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
@@ -112,15 +122,58 @@ func getAll[T model.Entity](
 	return entities, nil
 }
 
+// forEach scans rows one by one and calls fn for each of them without collecting the rows,
+// an error of fn stops the iteration and is returned as is
+func forEach[T model.Entity](
+	ctx context.Context,
+	r Repo,
+	req entity[T],
+	fn func(t *T) error,
+) error {
+	tx, err := begin(ctx, r)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, req.query, req.args...)
+	if err != nil {
+		r.l().Wrn().Err(err).Ctx(ctx).Msg("failed to query %s", req.entityName)
+		return database.GetErrorByCode(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t T
+		if err = rows.Scan(req.destinations(&t)...); err != nil {
+			r.l().Err(err).Ctx(ctx).Msg("failed to scan %s", req.entityName)
+			return database.GetErrorByCode(err)
+		}
+		if err = fn(&t); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		r.l().Err(err).Ctx(ctx).Msg("failed to read %s", req.entityName)
+		return database.GetErrorByCode(err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		r.l().Err(err).Ctx(ctx).Msg(database.FailedCommitTransaction)
+		return database.GetErrorByCode(err)
+	}
+
+	return nil
+}
+
 func create[T model.Entity](
 	ctx context.Context,
 	r Repo,
 	req entity[T],
 ) (*T, error) {
-	tx, err := r.p().Begin(ctx)
+	tx, err := begin(ctx, r)
 	if err != nil {
-		r.l().Err(err).Ctx(ctx).Msg(database.FailedBeginTransaction)
-		return nil, database.GetErrorByCode(err)
+		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
@@ -143,10 +196,9 @@ func exec(
 	r Repo,
 	req execRequest,
 ) error {
-	tx, err := r.p().Begin(ctx)
+	tx, err := begin(ctx, r)
 	if err != nil {
-		r.l().Err(err).Ctx(ctx).Msg(database.FailedBeginTransaction)
-		return database.GetErrorByCode(err)
+		return err
 	}
 	defer tx.Rollback(ctx)
 
@@ -160,7 +212,33 @@ func exec(
 		return database.GetErrorByCode(err)
 	}
 
-	if err = database.CheckAffectedRows(tag); err != nil {
+	if !req.anyRows {
+		if err = database.CheckAffectedRows(tag); err != nil {
+			return database.GetErrorByCode(err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		r.l().Err(err).Ctx(ctx).Msg(database.FailedCommitTransaction)
+		return database.GetErrorByCode(err)
+	}
+
+	return nil
+}
+
+func copyFrom(
+	ctx context.Context,
+	r Repo,
+	req copyRequest,
+) error {
+	tx, err := begin(ctx, r)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = tx.CopyFrom(ctx, pgx.Identifier{schema, req.table}, req.columns, pgx.CopyFromRows(req.rows)); err != nil {
+		r.l().Wrn().Err(err).Ctx(ctx).Msg("failed to copy %s", req.entityName)
 		return database.GetErrorByCode(err)
 	}
 
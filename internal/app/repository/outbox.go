@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"github.com/vlaship/book-catalog-go/internal/database"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+	"time"
+)
+
+// OutboxRepository is an interface for outbox repository
+type OutboxRepository struct {
+	pool database.ConnPool
+	log  logger.Logger
+}
+
+// NewOutboxRepository creates new outbox repository
+func NewOutboxRepository(pool database.ConnPool, log logger.Logger) *OutboxRepository {
+	return &OutboxRepository{
+		pool: pool,
+		log:  log.New("OutboxRepository"),
+	}
+}
+
+func (r *OutboxRepository) l() logger.Logger {
+	return r.log
+}
+
+func (r *OutboxRepository) p() database.ConnPool {
+	return r.pool
+}
+
+const entityNameOutboxMessage = "outbox message"
+
+const (
+	insertOutboxMessage = `
+	INSERT INTO catalog.outbox (outbox_id, kind, recipient, payload)
+	VALUES ($1, $2, $3, $4::JSONB)
+	RETURNING outbox_id, kind, recipient, payload, status, attempts, last_error, next_attempt_at, created_at;
+`
+	claimOutboxMessages = `
+	UPDATE catalog.outbox SET next_attempt_at = $2
+	WHERE outbox_id IN (
+		SELECT outbox_id FROM catalog.outbox
+		WHERE status = 'pending' AND next_attempt_at <= $1
+		ORDER BY next_attempt_at
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING outbox_id, kind, recipient, payload, status, attempts, last_error, next_attempt_at, created_at;
+`
+	markOutboxMessageSent = `
+	UPDATE catalog.outbox SET status = 'sent', attempts = attempts + 1, payload = NULL, last_error = NULL, sent_at = now()
+	WHERE outbox_id = $1 AND status = 'pending';
+`
+	markOutboxMessageFailed = `
+	UPDATE catalog.outbox SET status = $2, attempts = attempts + 1, last_error = $3, next_attempt_at = $4
+	WHERE outbox_id = $1 AND status = 'pending';
+`
+)
+
+// CreateOutboxMessage queues new message, it joins the transaction carried by ctx if any
+func (r *OutboxRepository) CreateOutboxMessage(
+	ctx context.Context,
+	msg *model.OutboxMessage,
+) (*model.OutboxMessage, error) {
+	r.log.Dbg().Ctx(ctx).Values("outboxID", msg.ID, "kind", msg.Kind).Msg("CreateOutboxMessage")
+
+	req := entity[model.OutboxMessage]{
+		query:        insertOutboxMessage,
+		entityName:   entityNameOutboxMessage,
+		args:         []any{msg.ID, msg.Kind, msg.Recipient, jsonArg(msg.Payload)},
+		destinations: outboxDestinations,
+	}
+
+	return create(ctx, r, req)
+}
+
+// ClaimOutboxMessages leases up to limit pending messages due at now until the lease expires,
+// concurrent workers skip the messages another worker has locked
+func (r *OutboxRepository) ClaimOutboxMessages(
+	ctx context.Context,
+	now time.Time,
+	lease time.Time,
+	limit int,
+) ([]model.OutboxMessage, error) {
+	r.log.Trc().Ctx(ctx).Values("limit", limit).Msg("ClaimOutboxMessages")
+
+	req := entity[model.OutboxMessage]{
+		query:        claimOutboxMessages,
+		entityName:   entityNameOutboxMessage,
+		args:         []any{now, lease, limit},
+		destinations: outboxDestinations,
+	}
+
+	return getAll(ctx, r, req)
+}
+
+// MarkOutboxMessageSent marks pending message as sent and clears its payload
+func (r *OutboxRepository) MarkOutboxMessageSent(ctx context.Context, id types.ID) error {
+	r.log.Dbg().Ctx(ctx).Values("outboxID", id).Msg("MarkOutboxMessageSent")
+
+	req := execRequest{
+		query:      markOutboxMessageSent,
+		entityName: entityNameOutboxMessage,
+		args:       []any{id},
+	}
+
+	return exec(ctx, r, req)
+}
+
+// MarkOutboxMessageFailed records failed attempt of pending message, the message is retried at next
+// while the status stays pending
+func (r *OutboxRepository) MarkOutboxMessageFailed(
+	ctx context.Context,
+	id types.ID,
+	status model.OutboxStatus,
+	lastError string,
+	next time.Time,
+) error {
+	r.log.Dbg().Ctx(ctx).Values("outboxID", id, "status", status).Msg("MarkOutboxMessageFailed")
+
+	req := execRequest{
+		query:      markOutboxMessageFailed,
+		entityName: entityNameOutboxMessage,
+		args:       []any{id, status, lastError, next},
+	}
+
+	return exec(ctx, r, req)
+}
+
+func outboxDestinations(out *model.OutboxMessage) []any {
+	return []any{
+		&out.ID,
+		&out.Kind,
+		&out.Recipient,
+		&out.Payload,
+		&out.Status,
+		&out.Attempts,
+		&out.LastError,
+		&out.NextAttemptAt,
+		&out.CreatedAt,
+	}
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+)
+
+// likeEscaper escapes LIKE wildcards in user input
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// queryBuilder is a helper to build queries with optional conditions
+type queryBuilder struct {
+	sb   strings.Builder
+	args []any
+}
+
+// newQueryBuilder creates a query builder starting with base query
+func newQueryBuilder(base string) *queryBuilder {
+	q := &queryBuilder{}
+	q.sb.WriteString(base)
+	return q
+}
+
+// arg adds argument and returns its placeholder
+func (q *queryBuilder) arg(v any) string {
+	q.args = append(q.args, v)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+// and adds condition joined with AND
+func (q *queryBuilder) and(cond string) {
+	q.sb.WriteString(" AND ")
+	q.sb.WriteString(cond)
+}
+
+// write adds raw part of query
+func (q *queryBuilder) write(s string) {
+	q.sb.WriteString(s)
+}
+
+// contains returns pattern for substring search with LIKE
+func contains(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
+
+// query returns built query
+func (q *queryBuilder) query() string {
+	return q.sb.String()
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"github.com/vlaship/book-catalog-go/internal/database"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+)
+
+// RefreshTokenRepository is an interface for refresh token repository
+type RefreshTokenRepository struct {
+	pool database.ConnPool
+	log  logger.Logger
+}
+
+// NewRefreshTokenRepository creates new refresh token repository
+func NewRefreshTokenRepository(pool database.ConnPool, log logger.Logger) *RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		pool: pool,
+		log:  log.New("RefreshTokenRepository"),
+	}
+}
+
+func (r *RefreshTokenRepository) l() logger.Logger {
+	return r.log
+}
+
+func (r *RefreshTokenRepository) p() database.ConnPool {
+	return r.pool
+}
+
+const entityNameRefreshToken = "refresh token"
+
+const (
+	insertRefreshToken = `
+	INSERT INTO catalog.refresh_tokens (token_id, token_hash, family_id, user_id, expires_at)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING token_id, family_id, user_id, expires_at;
+`
+	rotateRefreshToken = `
+	WITH used AS (
+		UPDATE catalog.refresh_tokens SET used_at = now()
+		WHERE token_hash = $1 AND used_at IS NULL AND revoked_at IS NULL AND expires_at > now()
+		RETURNING family_id, user_id
+	)
+	INSERT INTO catalog.refresh_tokens (token_id, token_hash, family_id, user_id, expires_at)
+	SELECT $2, $3, family_id, user_id, $4 FROM used
+	RETURNING token_id, family_id, user_id, expires_at;
+`
+	getRefreshTokenByHash = `
+	SELECT token_id, token_hash, family_id, user_id, expires_at, used_at, revoked_at
+	FROM catalog.refresh_tokens WHERE token_hash = $1;
+`
+	revokeRefreshTokenFamily = `
+	UPDATE catalog.refresh_tokens SET revoked_at = now()
+	WHERE family_id = $1 AND revoked_at IS NULL;
+`
+	revokeRefreshTokenFamilyByHash = `
+	UPDATE catalog.refresh_tokens SET revoked_at = now()
+	WHERE family_id = (SELECT family_id FROM catalog.refresh_tokens WHERE token_hash = $1) AND revoked_at IS NULL;
+`
+	revokeRefreshTokensByUser = `
+	UPDATE catalog.refresh_tokens SET revoked_at = now()
+	WHERE user_id = $1 AND revoked_at IS NULL;
+`
+)
+
+// CreateRefreshToken inserts new refresh token
+func (r *RefreshTokenRepository) CreateRefreshToken(
+	ctx context.Context,
+	token *model.RefreshToken,
+) (*model.RefreshToken, error) {
+	r.log.Dbg().Ctx(ctx).Values("tokenID", token.ID, "familyID", token.FamilyID).Msg("CreateRefreshToken")
+
+	req := entity[model.RefreshToken]{
+		query:      insertRefreshToken,
+		entityName: entityNameRefreshToken,
+		args:       []any{token.ID, token.Hash, token.FamilyID, token.UserID, token.ExpiresAt},
+		destinations: func(out *model.RefreshToken) []any {
+			return []any{&out.ID, &out.FamilyID, &out.UserID, &out.ExpiresAt}
+		},
+	}
+
+	return create(ctx, r, req)
+}
+
+// RotateRefreshToken atomically marks the live token with hash as used and inserts its successor
+// into the same family, returns apperr.ErrNotFound if there is no live token with hash
+func (r *RefreshTokenRepository) RotateRefreshToken(
+	ctx context.Context,
+	hash string,
+	next *model.RefreshToken,
+) (*model.RefreshToken, error) {
+	r.log.Dbg().Ctx(ctx).Values("tokenID", next.ID).Msg("RotateRefreshToken")
+
+	req := entity[model.RefreshToken]{
+		query:      rotateRefreshToken,
+		entityName: entityNameRefreshToken,
+		args:       []any{hash, next.ID, next.Hash, next.ExpiresAt},
+		destinations: func(out *model.RefreshToken) []any {
+			return []any{&out.ID, &out.FamilyID, &out.UserID, &out.ExpiresAt}
+		},
+	}
+
+	return create(ctx, r, req)
+}
+
+// GetRefreshToken returns refresh token by hash
+func (r *RefreshTokenRepository) GetRefreshToken(ctx context.Context, hash string) (*model.RefreshToken, error) {
+	r.log.Trc().Ctx(ctx).Msg("GetRefreshToken")
+
+	req := entity[model.RefreshToken]{
+		query:      getRefreshTokenByHash,
+		entityName: entityNameRefreshToken,
+		args:       []any{hash},
+		destinations: func(out *model.RefreshToken) []any {
+			return []any{
+				&out.ID,
+				&out.Hash,
+				&out.FamilyID,
+				&out.UserID,
+				&out.ExpiresAt,
+				&out.UsedAt,
+				&out.RevokedAt,
+			}
+		},
+	}
+
+	return getOne(ctx, r, req)
+}
+
+// RevokeRefreshTokenFamily revokes all tokens of family
+func (r *RefreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID types.ID) error {
+	r.log.Dbg().Ctx(ctx).Values("familyID", familyID).Msg("RevokeRefreshTokenFamily")
+
+	req := execRequest{
+		query:      revokeRefreshTokenFamily,
+		entityName: entityNameRefreshToken,
+		args:       []any{familyID},
+		anyRows:    true,
+	}
+
+	return exec(ctx, r, req)
+}
+
+// RevokeRefreshTokenFamilyByHash revokes all tokens of the family the token with hash belongs to
+func (r *RefreshTokenRepository) RevokeRefreshTokenFamilyByHash(ctx context.Context, hash string) error {
+	r.log.Dbg().Ctx(ctx).Msg("RevokeRefreshTokenFamilyByHash")
+
+	req := execRequest{
+		query:      revokeRefreshTokenFamilyByHash,
+		entityName: entityNameRefreshToken,
+		args:       []any{hash},
+		anyRows:    true,
+	}
+
+	return exec(ctx, r, req)
+}
+
+// RevokeRefreshTokens revokes all tokens of user
+func (r *RefreshTokenRepository) RevokeRefreshTokens(ctx context.Context, userID types.UserID) error {
+	r.log.Dbg().Ctx(ctx).Values("userID", userID).Msg("RevokeRefreshTokens")
+
+	req := execRequest{
+		query:      revokeRefreshTokensByUser,
+		entityName: entityNameRefreshToken,
+		args:       []any{userID},
+		anyRows:    true,
+	}
+
+	return exec(ctx, r, req)
+}
@@ -2,8 +2,13 @@ package repository
 
 // Repositories is an interface for repositories
 type Repositories struct {
-	BookRepository     *BookRepository
-	AuthorRepository   *AuthorRepository
-	PropertyRepository *PropertyRepository
-	UserRepository     *UserRepository
+	BookRepository         *BookRepository
+	AuthorRepository       *AuthorRepository
+	PropertyRepository     *PropertyRepository
+	UserRepository         *UserRepository
+	SearchRepository       *SearchRepository
+	RefreshTokenRepository *RefreshTokenRepository
+	AuditRepository        *AuditRepository
+	OutboxRepository       *OutboxRepository
+	TxManager              *TxManager
 }
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"github.com/vlaship/book-catalog-go/internal/database"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+)
+
+// SearchRepository is an interface for full-text search repository
+type SearchRepository struct {
+	pool database.ConnPool
+	log  logger.Logger
+}
+
+// NewSearchRepository creates new search repository
+func NewSearchRepository(pool database.ConnPool, log logger.Logger) *SearchRepository {
+	return &SearchRepository{
+		pool: pool,
+		log:  log.New("SearchRepository"),
+	}
+}
+
+func (r *SearchRepository) l() logger.Logger {
+	return r.log
+}
+
+func (r *SearchRepository) p() database.ConnPool {
+	return r.pool
+}
+
+const entityNameSearchHit = "search hit"
+
+// snippets are HTML, the source text is escaped so that the <mark> tags of ts_headline are the only markup
+const (
+	search = `
+	WITH q AS (SELECT websearch_to_tsquery('english', $1) AS query)
+	SELECT 'book', b.book_id, b.book_title,
+		ts_headline('english', replace(replace(replace(replace(replace(b.book_title || ' ' || b.book_desc,
+			'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;'),
+			q.query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2'),
+		ts_rank(b.book_tsv, q.query)
+	FROM catalog.books b, q
+	WHERE b.deleted = FALSE AND b.book_tsv @@ q.query
+	UNION ALL
+	SELECT 'author', a.author_id, a.author_name,
+		ts_headline('english', replace(replace(replace(replace(replace(a.author_name,
+			'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;'),
+			q.query, 'StartSel=<mark>, StopSel=</mark>'),
+		ts_rank(a.author_tsv, q.query)
+	FROM catalog.authors a, q
+	WHERE a.deleted = FALSE AND a.author_tsv @@ q.query
+	ORDER BY 5 DESC, 2
+	LIMIT $2;
+`
+)
+
+// Search returns books and authors matching the query ordered by rank
+func (r *SearchRepository) Search(ctx context.Context, query string, limit int) ([]model.SearchHit, error) {
+	r.log.Dbg().Ctx(ctx).Values("query", query, "limit", limit).Msg("Search")
+
+	req := entity[model.SearchHit]{
+		query:      search,
+		entityName: entityNameSearchHit,
+		args:       []any{query, limit},
+		destinations: func(hit *model.SearchHit) []any {
+			return []any{
+				&hit.Kind,
+				&hit.ID,
+				&hit.Title,
+				&hit.Snippet,
+				&hit.Rank,
+			}
+		},
+	}
+
+	return getAll(ctx, r, req)
+}
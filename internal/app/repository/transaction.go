@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/vlaship/book-catalog-go/internal/database"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+)
+
+type txKey struct{}
+
+// TxManager runs several repository calls in one database transaction
+type TxManager struct {
+	pool database.ConnPool
+	log  logger.Logger
+}
+
+// NewTxManager creates new transaction manager
+func NewTxManager(pool database.ConnPool, log logger.Logger) *TxManager {
+	return &TxManager{
+		pool: pool,
+		log:  log.New("TxManager"),
+	}
+}
+
+func (m *TxManager) l() logger.Logger {
+	return m.log
+}
+
+func (m *TxManager) p() database.ConnPool {
+	return m.pool
+}
+
+// InTx calls fn with a context carrying a transaction, repository calls made with that context
+// run inside it, the transaction is committed if fn returns no error and rolled back otherwise
+func (m *TxManager) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := begin(ctx, m)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		m.log.Err(err).Ctx(ctx).Msg(database.FailedCommitTransaction)
+		return database.GetErrorByCode(err)
+	}
+
+	return nil
+}
+
+// begin starts a transaction, or a savepoint if the context already carries one
+func begin(ctx context.Context, r Repo) (pgx.Tx, error) {
+	var (
+		tx  pgx.Tx
+		err error
+	)
+	if outer, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		tx, err = outer.Begin(ctx)
+	} else {
+		tx, err = r.p().Begin(ctx)
+	}
+	if err != nil {
+		r.l().Err(err).Ctx(ctx).Msg(database.FailedBeginTransaction)
+		return nil, database.GetErrorByCode(err)
+	}
+
+	return tx, nil
+}
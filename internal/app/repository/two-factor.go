@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+)
+
+const (
+	twoFactorGet = `
+	SELECT user_id, totp_secret, totp_enabled, totp_recovery_codes, totp_last_step FROM catalog.users
+	WHERE user_id = $1 AND deleted = FALSE;
+`
+	twoFactorSet = `
+	UPDATE catalog.users SET totp_secret = $1, totp_recovery_codes = $2, totp_last_step = 0
+	WHERE user_id = $3 AND deleted = FALSE AND totp_enabled = FALSE;
+`
+	twoFactorEnable = `
+	UPDATE catalog.users SET totp_enabled = TRUE, totp_last_step = $1
+	WHERE user_id = $2 AND deleted = FALSE AND totp_enabled = FALSE AND totp_secret IS NOT NULL;
+`
+	twoFactorDisable = `
+	UPDATE catalog.users SET totp_secret = NULL, totp_enabled = FALSE, totp_recovery_codes = '{}', totp_last_step = 0
+	WHERE user_id = $1 AND deleted = FALSE;
+`
+	twoFactorUseStep = `
+	UPDATE catalog.users SET totp_last_step = $1
+	WHERE user_id = $2 AND deleted = FALSE AND totp_enabled = TRUE AND totp_last_step < $1;
+`
+	twoFactorUseRecoveryCode = `
+	UPDATE catalog.users SET totp_recovery_codes = array_remove(totp_recovery_codes, $1)
+	WHERE user_id = $2 AND deleted = FALSE AND totp_enabled = TRUE AND $1 = ANY(totp_recovery_codes);
+`
+)
+
+// GetTwoFactor returns two-factor authentication of user
+func (r *UserRepository) GetTwoFactor(ctx context.Context, userID types.UserID) (*model.TwoFactor, error) {
+	r.log.Dbg().Ctx(ctx).Values("userID", userID).Msg("GetTwoFactor")
+
+	req := entity[model.TwoFactor]{
+		query:      twoFactorGet,
+		entityName: entityNameUser,
+		args:       []any{userID},
+		destinations: func(out *model.TwoFactor) []any {
+			return []any{
+				&out.UserID,
+				&out.Secret,
+				&out.Enabled,
+				&out.RecoveryCodes,
+				&out.LastStep,
+			}
+		},
+	}
+
+	return getOne(ctx, r, req)
+}
+
+// SetTwoFactor stores the pending enrollment of user, it replaces a previous pending one
+func (r *UserRepository) SetTwoFactor(ctx context.Context, userID types.UserID, secret []byte, recoveryCodes []string) error {
+	r.log.Dbg().Ctx(ctx).Values("userID", userID).Msg("SetTwoFactor")
+
+	req := execRequest{
+		query:      twoFactorSet,
+		entityName: entityNameUser,
+		args:       []any{secret, recoveryCodes, userID},
+	}
+
+	return exec(ctx, r, req)
+}
+
+// EnableTwoFactor enables the pending enrollment of user, step is the time step of the confirming code
+func (r *UserRepository) EnableTwoFactor(ctx context.Context, userID types.UserID, step int64) error {
+	r.log.Dbg().Ctx(ctx).Values("userID", userID).Msg("EnableTwoFactor")
+
+	req := execRequest{
+		query:      twoFactorEnable,
+		entityName: entityNameUser,
+		args:       []any{step, userID},
+	}
+
+	return exec(ctx, r, req)
+}
+
+// DisableTwoFactor disables two-factor authentication of user and drops the secret and the recovery codes
+func (r *UserRepository) DisableTwoFactor(ctx context.Context, userID types.UserID) error {
+	r.log.Dbg().Ctx(ctx).Values("userID", userID).Msg("DisableTwoFactor")
+
+	req := execRequest{
+		query:      twoFactorDisable,
+		entityName: entityNameUser,
+		args:       []any{userID},
+	}
+
+	return exec(ctx, r, req)
+}
+
+// UseTwoFactorStep records the time step of an accepted code, it fails with not found
+// when a code of the same or a later step has been accepted already
+func (r *UserRepository) UseTwoFactorStep(ctx context.Context, userID types.UserID, step int64) error {
+	r.log.Dbg().Ctx(ctx).Values("userID", userID).Msg("UseTwoFactorStep")
+
+	req := execRequest{
+		query:      twoFactorUseStep,
+		entityName: entityNameUser,
+		args:       []any{step, userID},
+	}
+
+	return exec(ctx, r, req)
+}
+
+// UseRecoveryCode consumes the recovery code of user by its hash, it fails with not found
+// when the code is unknown or used already
+func (r *UserRepository) UseRecoveryCode(ctx context.Context, userID types.UserID, hash string) error {
+	r.log.Dbg().Ctx(ctx).Values("userID", userID).Msg("UseRecoveryCode")
+
+	req := execRequest{
+		query:      twoFactorUseRecoveryCode,
+		entityName: entityNameUser,
+		args:       []any{hash, userID},
+	}
+
+	return exec(ctx, r, req)
+}
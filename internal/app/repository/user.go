@@ -8,6 +8,7 @@ import (
 	"github.com/vlaship/book-catalog-go/internal/logger"
 	"github.com/vlaship/go-mask"
 	"strings"
+	"time"
 )
 
 // UserRepository is an interface for user repository
@@ -34,11 +35,11 @@ func (r *UserRepository) p() database.ConnPool {
 
 const (
 	userGetByUsername = `
-	SELECT user_id, username, password, user_data FROM catalog.users
+	SELECT user_id, username, password, user_data, totp_enabled, locked_until FROM catalog.users
 	WHERE username = $1 AND deleted = FALSE;
 `
 	userGetByID = `
-	SELECT user_id, username, password, user_data FROM catalog.users
+	SELECT user_id, username, password, user_data, created_at, totp_enabled, locked_until FROM catalog.users
 	WHERE user_id = $1 AND deleted = FALSE;
 `
 	userCreate = `
@@ -46,16 +47,43 @@ const (
 	VALUES ($1, $2, $3, $4)
 	RETURNING user_id, username, user_data;
 `
-	userUpdateStatus = `
-	UPDATE catalog.users SET user_data = jsonb_set(user_data, '{status}',  to_jsonb($1::text), true)
- 	WHERE username = $2 AND deleted = FALSE;
+	userActivate = `
+	UPDATE catalog.users SET user_data = jsonb_set(user_data, '{status}', to_jsonb($1::text), true)
+	WHERE username = $2 AND deleted = FALSE AND user_data->>'status' = $3;
 `
 	userUpdatePassword = `
-	UPDATE catalog.users SET password = $1 WHERE username = $2 AND deleted = FALSE AND status = '';
+	UPDATE catalog.users SET password = $1
+	WHERE username = $2 AND deleted = FALSE AND COALESCE(user_data->>'status', '') = '';
+`
+	userUpdateUsername = `
+	UPDATE catalog.users SET username = $1, user_data = jsonb_set(user_data, '{email}', to_jsonb($1::text), true)
+	WHERE user_id = $2 AND deleted = FALSE;
+`
+	userAnonymize = `
+	WITH outbox AS (
+		DELETE FROM catalog.outbox WHERE recipient = (SELECT username FROM catalog.users WHERE user_id = $1 AND deleted = FALSE)
+	)
+	UPDATE catalog.users SET username = 'deleted-' || user_id, password = '', user_data = '{}', deleted = TRUE,
+		totp_secret = NULL, totp_enabled = FALSE, totp_recovery_codes = '{}'
+	WHERE user_id = $1 AND deleted = FALSE;
 `
 	userUpdateInfo = `
-	UPDATE catalog.users SET user_data = $1 WHERE user_id = $2 AND deleted = FALSE;
+	UPDATE catalog.users SET user_data = user_data || $1::JSONB WHERE user_id = $2 AND deleted = FALSE;
+`
+	userPatch = `
+	UPDATE catalog.users SET user_data = user_data || $1::JSONB,
+		locked_until = CASE WHEN $3 THEN NULL ELSE locked_until END
+	WHERE user_id = $2 AND deleted = FALSE;
+`
+	userLock = `
+	UPDATE catalog.users SET locked_until = $1 WHERE user_id = $2 AND deleted = FALSE;
 `
+	userDelete = `
+	UPDATE catalog.users SET deleted = TRUE WHERE user_id = $1 AND deleted = FALSE;
+`
+	userGetAll = `
+	SELECT user_id, username, user_data, created_at, locked_until FROM catalog.users
+	WHERE deleted = FALSE`
 )
 
 const (
@@ -76,6 +104,8 @@ func (r *UserRepository) GetUserByUsername(ctx context.Context, username types.U
 				&out.Username,
 				&out.Password,
 				&out.Data,
+				&out.TwoFactor,
+				&out.LockedUntil,
 			}
 		},
 	}
@@ -97,6 +127,9 @@ func (r *UserRepository) GetUserByID(ctx context.Context, userID types.UserID) (
 				&out.Username,
 				&out.Password,
 				&out.Data,
+				&out.CreatedAt,
+				&out.TwoFactor,
+				&out.LockedUntil,
 			}
 		},
 	}
@@ -129,14 +162,14 @@ func (r *UserRepository) Create(ctx context.Context, input model.User) (*model.U
 	return create(ctx, r, req)
 }
 
-// UpdateStatus activate user
-func (r *UserRepository) UpdateStatus(ctx context.Context, user model.User) error {
-	r.log.Dbg().Ctx(ctx).Values("username", mask.String(string(user.Username))).Msg("Activate")
+// Activate activates user that is not activated, suspended and locked users are left as they are
+func (r *UserRepository) Activate(ctx context.Context, username types.Username) error {
+	r.log.Dbg().Ctx(ctx).Values("username", mask.String(string(username))).Msg("Activate")
 
 	req := execRequest{
-		query:      userUpdateStatus,
+		query:      userActivate,
 		entityName: entityNameUser,
-		args:       []any{user.Data.Status, r.lower(user.Username)},
+		args:       []any{model.UserStatusActive, r.lower(username), model.UserStatusNotActivated},
 	}
 
 	return exec(ctx, r, req)
@@ -155,19 +188,136 @@ func (r *UserRepository) UpdatePassword(ctx context.Context, user model.User) er
 	return exec(ctx, r, req)
 }
 
+// UpdateUsername replaces the username of user, the email of user data follows the username
+func (r *UserRepository) UpdateUsername(ctx context.Context, userID types.UserID, username types.Username) error {
+	r.log.Dbg().Ctx(ctx).Values("userID", userID, "username", mask.String(string(username))).Msg("UpdateUsername")
+
+	req := execRequest{
+		query:      userUpdateUsername,
+		entityName: entityNameUser,
+		args:       []any{r.lower(username), userID},
+	}
+
+	return exec(ctx, r, req)
+}
+
+// Anonymize deletes user and erases the personal data of user with the mails queued to user,
+// the username is freed for signing up again
+func (r *UserRepository) Anonymize(ctx context.Context, userID types.UserID) error {
+	r.log.Dbg().Ctx(ctx).Values("userID", userID).Msg("Anonymize")
+
+	req := execRequest{
+		query:      userAnonymize,
+		entityName: entityNameUser,
+		args:       []any{userID},
+	}
+
+	return exec(ctx, r, req)
+}
+
 // UpdateInfo update user
 func (r *UserRepository) UpdateInfo(ctx context.Context, user model.User, userID types.UserID) error {
 	r.log.Dbg().Ctx(ctx).Values("user", user).Msg("UpdateInfo")
 
+	// user data fields are omitted when empty, so the info is built explicitly to be able to clear a field
+	data := map[string]any{
+		"firstname": user.Data.FirstName,
+		"lastname":  user.Data.LastName,
+		"language":  user.Data.Language,
+	}
+
 	req := execRequest{
 		query:      userUpdateInfo,
 		entityName: entityNameUser,
-		args: []any{
-			user.Data,
-			userID,
+		args:       []any{data, userID},
+	}
+
+	return exec(ctx, r, req)
+}
+
+// GetUsers returns users by filter, newest first
+func (r *UserRepository) GetUsers(ctx context.Context, filter model.UserFilter) ([]model.User, error) {
+	r.log.Trc().Ctx(ctx).Values("limit", filter.Limit, "status", filter.Status).Msg("GetUsers")
+
+	q := newQueryBuilder(userGetAll)
+	if filter.Status != nil {
+		// active users have no status
+		q.and("COALESCE(user_data->>'status', '') = " + q.arg(string(*filter.Status)))
+	}
+	if filter.Email != "" {
+		q.and("username LIKE " + q.arg(contains(strings.ToLower(filter.Email))))
+	}
+	if filter.Cursor != nil {
+		q.and("user_id < " + q.arg(filter.Cursor.ID))
+	}
+	q.write(" ORDER BY user_id DESC LIMIT " + q.arg(filter.Limit))
+
+	req := entity[model.User]{
+		query:      q.query(),
+		entityName: entityNameUser,
+		args:       q.args,
+		destinations: func(out *model.User) []any {
+			return []any{
+				&out.ID,
+				&out.Username,
+				&out.Data,
+				&out.CreatedAt,
+				&out.LockedUntil,
+			}
 		},
 	}
 
+	return getAll(ctx, r, req)
+}
+
+// PatchUser sets the status, role and plan of user that are present in patch, setting the status lifts the temporary lock
+func (r *UserRepository) PatchUser(ctx context.Context, userID types.UserID, patch model.UserPatch) error {
+	r.log.Dbg().Ctx(ctx).Values("userID", userID).Msg("PatchUser")
+
+	// user data fields are omitted when empty, so the patch is built explicitly to be able to clear the status
+	data := make(map[string]any, 3)
+	if patch.Status != nil {
+		data["status"] = *patch.Status
+	}
+	if patch.Role != nil {
+		data["role"] = *patch.Role
+	}
+	if patch.Plan != nil {
+		data["user_plan"] = *patch.Plan
+	}
+
+	req := execRequest{
+		query:      userPatch,
+		entityName: entityNameUser,
+		args:       []any{data, userID, patch.Status != nil},
+	}
+
+	return exec(ctx, r, req)
+}
+
+// LockUser temporarily locks user until the time
+func (r *UserRepository) LockUser(ctx context.Context, userID types.UserID, until time.Time) error {
+	r.log.Dbg().Ctx(ctx).Values("userID", userID, "until", until).Msg("LockUser")
+
+	req := execRequest{
+		query:      userLock,
+		entityName: entityNameUser,
+		args:       []any{until, userID},
+	}
+
+	return exec(ctx, r, req)
+}
+
+// DeleteUser soft deletes user
+func (r *UserRepository) DeleteUser(ctx context.Context, userID types.UserID) error {
+	r.log.Dbg().Ctx(ctx).Values("userID", userID).Msg("DeleteUser")
+
+	req := execRequest{
+		query:      userDelete,
+		entityName: entityNameUser,
+		args:       []any{userID},
+	}
+
 	return exec(ctx, r, req)
 }
 
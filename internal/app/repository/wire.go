@@ -17,6 +17,11 @@ func Wire(pool database.ConnPool, log logger.Logger) *Repositories {
 		NewAuthorRepository,
 		NewPropertyRepository,
 		NewUserRepository,
+		NewSearchRepository,
+		NewRefreshTokenRepository,
+		NewAuditRepository,
+		NewOutboxRepository,
+		NewTxManager,
 		wire.Struct(new(Repositories), "*"),
 	)
 	return &Repositories{}
@@ -9,10 +9,12 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/vlaship/book-catalog-go/internal/config"
 	"github.com/vlaship/book-catalog-go/internal/database"
 	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
 )
 
 // Run starts the application
@@ -26,6 +28,20 @@ func Run(cfg *config.Config, log logger.Logger) error {
 		return fmt.Errorf("failed db migration: %w", err)
 	}
 
+	// init tracing
+	log.Trc().Msg("init tracing...")
+	tracer, err := tracing.New(context.Background(), cfg)
+	if err != nil {
+		return fmt.Errorf("failed to init tracing: %w", err)
+	}
+	defer func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.ServerProps.CancelContextTimeout)
+		defer shutdownCancel()
+		if err := tracer.Shutdown(shutdownCtx); err != nil {
+			log.Err(err).Msg("failed to shut down tracing")
+		}
+	}()
+
 	// init app
 	log.Trc().Msg("init app...")
 	app, err := NewApp(cfg, log)
@@ -34,6 +50,21 @@ func Run(cfg *config.Config, log logger.Logger) error {
 	}
 
 	defer app.DB.Close()
+	defer func() {
+		if err := app.Cache.Close(); err != nil {
+			log.Err(err).Msg("failed to close cache")
+		}
+	}()
+	defer func() {
+		if err := app.Limiter.Close(); err != nil {
+			log.Err(err).Msg("failed to close rate limiter")
+		}
+	}()
+	defer func() {
+		if err := app.Migrations.Close(); err != nil {
+			log.Err(err).Msg("failed to close migration checker")
+		}
+	}()
 
 	// Start server with context
 	ctx, cancel := context.WithCancel(context.Background())
@@ -48,6 +79,12 @@ func Run(cfg *config.Config, log logger.Logger) error {
 		BaseContext:  func(_ net.Listener) context.Context { return ctx },
 	}
 
+	metricsServer := &http.Server{
+		Addr:              cfg.MetricsPort,
+		Handler:           metricsMux(app.Metrics),
+		ReadHeaderTimeout: cfg.ServerProps.ReadTimeout,
+	}
+
 	go func() {
 		log.Inf().Msg("starting http-server...")
 		if err = server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
@@ -56,8 +93,29 @@ func Run(cfg *config.Config, log logger.Logger) error {
 		}
 	}()
 
+	go func() {
+		log.Inf().Msg("starting metrics-server...")
+		if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Err(err).Msg("failed to start metrics server")
+			cancel()
+		}
+	}()
+
+	// purge expired trash in background until shutdown
+	go app.Trash.RunRetention(ctx)
+
+	// deliver queued mails in background, the worker drains the outbox once the server is shut down
+	outboxCtx, stopOutbox := context.WithCancel(context.Background())
+	defer stopOutbox()
+	outboxDone := make(chan struct{})
+	go func() {
+		defer close(outboxDone)
+		app.Outbox.Run(outboxCtx)
+	}()
+
 	log.Inf().Msg("Book Catalog is started...")
 	log.Inf().Msg(fmt.Sprintf("Port %v", cfg.ServerProps.Port))
+	log.Inf().Msg(fmt.Sprintf("Metrics port %v", cfg.MetricsPort))
 
 	// wait for interrupt signal to gracefully shut down the server
 	quit := make(chan os.Signal, 1)
@@ -70,6 +128,13 @@ func Run(cfg *config.Config, log logger.Logger) error {
 		log.Inf().Msg("server shutdown requested...")
 	}
 
+	// fail readiness first, so no new traffic is routed while the server drains
+	app.Health.Shutdown()
+	if cfg.Health.ShutdownDelay > 0 {
+		log.Inf().Msg(fmt.Sprintf("waiting %v before shutdown...", cfg.Health.ShutdownDelay))
+		time.Sleep(cfg.Health.ShutdownDelay)
+	}
+
 	// Create shutdown context with timeout
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.ServerProps.CancelContextTimeout)
 	defer shutdownCancel()
@@ -78,6 +143,20 @@ func Run(cfg *config.Config, log logger.Logger) error {
 	if err = server.Shutdown(shutdownCtx); err != nil {
 		return fmt.Errorf("failed to gracefully shut down server: %w", err)
 	}
+	if err = metricsServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to gracefully shut down metrics server: %w", err)
+	}
+
+	// no more mails are queued, wait for the outbox to drain
+	stopOutbox()
+	<-outboxDone
 
 	return nil
 }
+
+// metricsMux serves the metrics on /metrics
+func metricsMux(metrics http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+	return mux
+}
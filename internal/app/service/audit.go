@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/vlaship/book-catalog-go/internal/app/common"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"github.com/vlaship/book-catalog-go/internal/apperr"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/snowflake"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
+	"reflect"
+	"time"
+)
+
+// AuditStore is an interface for audit log storage
+//
+//go:generate mockgen -destination=../../../test/mock/service/mock-audit-store.go -package=mock . AuditStore
+type AuditStore interface {
+	CreateAuditRecord(ctx context.Context, record *model.AuditRecord) (*model.AuditRecord, error)
+	CopyAuditRecords(ctx context.Context, records []model.AuditRecord) error
+	GetAuditRecords(ctx context.Context, filter model.AuditFilter) ([]model.AuditRecord, error)
+}
+
+// AuditRecorder is an interface for recording catalog mutations
+//
+//go:generate mockgen -destination=../../../test/mock/service/mock-audit-recorder.go -package=mock . AuditRecorder
+type AuditRecorder interface {
+	Record(ctx context.Context, action model.AuditAction, entity model.AuditEntity, id types.ID, before, after map[string]any) error
+	RecordCreated(ctx context.Context, entity model.AuditEntity, created map[types.ID]map[string]any) error
+}
+
+// Transactor is an interface for running calls in one transaction
+//
+//go:generate mockgen -destination=../../../test/mock/service/mock-transactor.go -package=mock . Transactor
+type Transactor interface {
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// audit is a snapshot of audited fields of an entity
+type audit = map[string]any
+
+// AuditService is a service for audit log
+type AuditService struct {
+	store AuditStore
+	idGen snowflake.IDGenerator
+	log   logger.Logger
+}
+
+// NewAuditService creates new audit service
+func NewAuditService(store AuditStore, idGen snowflake.IDGenerator, log logger.Logger) *AuditService {
+	return &AuditService{
+		store: store,
+		idGen: idGen,
+		log:   log.New("AuditService"),
+	}
+}
+
+// GetAuditRecords returns page of audit records by filter
+func (s *AuditService) GetAuditRecords(ctx context.Context, filter model.AuditFilter) (*model.Page[model.AuditRecord], error) {
+	ctx, span := tracing.Start(ctx, "AuditService.GetAuditRecords")
+	defer span.End()
+
+	s.log.Trc().Ctx(ctx).Values("filter", filter).Msg("GetAuditRecords")
+
+	limit := filter.Limit
+	filter.Limit++ // fetch one more to know if there is a next page
+
+	records, err := s.store.GetAuditRecords(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewPage(records, limit, model.AuditCursor), nil
+}
+
+// Record writes the changed fields of the entity together with the acting user and request ID,
+// it must be called with the context of the transaction of the change
+func (s *AuditService) Record(
+	ctx context.Context,
+	action model.AuditAction,
+	entity model.AuditEntity,
+	id types.ID,
+	before, after audit,
+) error {
+	ctx, span := tracing.Start(ctx, "AuditService.Record")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("action", action, "entity", entity, "id", id).Msg("Record")
+
+	record, err := s.newRecord(ctx, action, entity, id, before, after)
+	if err != nil {
+		return err
+	}
+
+	if _, err = s.store.CreateAuditRecord(ctx, record); err != nil {
+		s.log.Err(err).Ctx(ctx).Msg("CreateAuditRecord")
+		return apperr.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// RecordCreated writes creation records of many entities at once, it must be called with the context
+// of the transaction of the change
+func (s *AuditService) RecordCreated(ctx context.Context, entity model.AuditEntity, created map[types.ID]audit) error {
+	ctx, span := tracing.Start(ctx, "AuditService.RecordCreated")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("entity", entity, "count", len(created)).Msg("RecordCreated")
+
+	records := make([]model.AuditRecord, 0, len(created))
+	for id, after := range created {
+		record, err := s.newRecord(ctx, model.AuditActionCreate, entity, id, nil, after)
+		if err != nil {
+			return err
+		}
+		records = append(records, *record)
+	}
+
+	if err := s.store.CopyAuditRecords(ctx, records); err != nil {
+		s.log.Err(err).Ctx(ctx).Msg("CopyAuditRecords")
+		return apperr.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// newRecord creates a record of the changed fields with the acting user and request ID
+func (s *AuditService) newRecord(
+	ctx context.Context,
+	action model.AuditAction,
+	entity model.AuditEntity,
+	id types.ID,
+	before, after audit,
+) (*model.AuditRecord, error) {
+	before, after = diff(before, after)
+
+	record := model.AuditRecord{
+		ID:         types.ID(s.idGen.Generate()),
+		Action:     action,
+		EntityType: entity,
+		EntityID:   id,
+	}
+	if user := common.GetUser(ctx); user != nil {
+		record.UserID = &user.ID
+	}
+	if requestID := common.GetRequestID(ctx); requestID != nil {
+		v := fmt.Sprint(requestID)
+		record.RequestID = &v
+	}
+
+	var err error
+	if record.Before, err = marshalAudit(before); err != nil {
+		s.log.Err(err).Ctx(ctx).Msg("marshal before")
+		return nil, apperr.ErrInternalServerError
+	}
+	if record.After, err = marshalAudit(after); err != nil {
+		s.log.Err(err).Ctx(ctx).Msg("marshal after")
+		return nil, apperr.ErrInternalServerError
+	}
+
+	return &record, nil
+}
+
+// diff keeps only the fields that differ when both snapshots are present
+func diff(before, after audit) (audit, audit) {
+	if before == nil || after == nil {
+		return before, after
+	}
+
+	b, a := audit{}, audit{}
+	for k, v := range before {
+		if !reflect.DeepEqual(v, after[k]) {
+			b[k] = v
+			a[k] = after[k]
+		}
+	}
+	for k, v := range after {
+		if _, ok := before[k]; !ok {
+			a[k] = v
+		}
+	}
+
+	return b, a
+}
+
+func marshalAudit(a audit) (json.RawMessage, error) {
+	if a == nil {
+		return nil, nil
+	}
+	return json.Marshal(a)
+}
+
+func auditBook(book *model.Book) audit {
+	if book == nil {
+		return nil
+	}
+	return audit{
+		"title":       book.Title,
+		"description": book.Description,
+		"isbn":        book.ISBN,
+		"author_id":   book.AuthorID,
+		"price":       book.Price.String(),
+	}
+}
+
+func auditAuthor(author *model.Author) audit {
+	if author == nil {
+		return nil
+	}
+	return audit{
+		"name": author.Name,
+		"dob":  author.Dob.Format(time.DateOnly),
+	}
+}
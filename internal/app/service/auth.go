@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/vlaship/book-catalog-go/internal/app/common"
 	"github.com/vlaship/book-catalog-go/internal/app/model"
 	"github.com/vlaship/book-catalog-go/internal/app/types"
 	"github.com/vlaship/book-catalog-go/internal/apperr"
 	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/metrics"
 	"github.com/vlaship/book-catalog-go/internal/snowflake"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
 	"github.com/vlaship/go-mask"
+	"time"
 )
 
 // Authenticator interface
@@ -25,16 +29,65 @@ type Authenticator interface {
 type PasswordHandler interface {
 	Validate(password, hash types.Password) error
 	Hash(password types.Password) (types.Password, error)
+	Check(ctx context.Context, password types.Password, username types.Username) error
+}
+
+// RefreshTokenHandler interface
+//
+//go:generate mockgen -destination=../../../test/mock/service/mock-refresh-token-handler.go -package=mock . RefreshTokenHandler
+type RefreshTokenHandler interface {
+	Issue(ctx context.Context, userID types.UserID) (types.Token, error)
+	Rotate(ctx context.Context, token types.Token) (types.UserID, types.Token, error)
+	Revoke(ctx context.Context, token types.Token) error
+}
+
+// OTPGenerator interface
+//
+//go:generate mockgen -destination=../../../test/mock/service/mock-otp-generator.go -package=mock . OTPGenerator
+type OTPGenerator interface {
+	GenerateOTP(ctx context.Context, purpose types.OTPPurpose, username types.Username) (types.Token, error)
+}
+
+// ActivationMailer interface
+//
+//go:generate mockgen -destination=../../../test/mock/service/mock-activation-mailer.go -package=mock . ActivationMailer
+type ActivationMailer interface {
+	SendActivationMail(ctx context.Context, to types.Username, lang string, otp types.Token) error
+}
+
+// TwoFactorChallenger interface
+//
+//go:generate mockgen -destination=../../../test/mock/service/mock-two-factor-challenger.go -package=mock . TwoFactorChallenger
+type TwoFactorChallenger interface {
+	Challenge(ctx context.Context, userID types.UserID) (types.Token, error)
+	ChallengeUser(ctx context.Context, token types.Token) (types.UserID, error)
+	Verify(ctx context.Context, token types.Token, code string) (types.UserID, error)
+}
+
+// SigninGuard interface
+//
+//go:generate mockgen -destination=../../../test/mock/service/mock-signin-guard.go -package=mock . SigninGuard
+type SigninGuard interface {
+	Check(ctx context.Context, username types.Username, ip string) error
+	Fail(ctx context.Context, username types.Username, ip string, user *model.User) error
+	Succeed(ctx context.Context, username types.Username) error
 }
 
 // AuthService is a service for authentication.
 type AuthService struct {
-	reader UserReader
-	writer UserWriter
-	auth   Authenticator
-	pass   PasswordHandler
-	idGen  snowflake.IDGenerator
-	log    logger.Logger
+	reader    UserReader
+	writer    UserWriter
+	auth      Authenticator
+	pass      PasswordHandler
+	refresh   RefreshTokenHandler
+	twoFactor TwoFactorChallenger
+	guard     SigninGuard
+	otp       OTPGenerator
+	mailer    ActivationMailer
+	tx        Transactor
+	metrics   metrics.Recorder
+	idGen     snowflake.IDGenerator
+	log       logger.Logger
 }
 
 // NewAuthService creates a new AuthService instance.
@@ -43,36 +96,176 @@ func NewAuthService(
 	writer UserWriter,
 	auth Authenticator,
 	pass PasswordHandler,
+	refresh RefreshTokenHandler,
+	twoFactor TwoFactorChallenger,
+	guard SigninGuard,
+	otp OTPGenerator,
+	mailer ActivationMailer,
+	tx Transactor,
+	recorder metrics.Recorder,
 	idGen snowflake.IDGenerator,
 	log logger.Logger,
 ) *AuthService {
 	return &AuthService{
-		reader: reader,
-		writer: writer,
-		auth:   auth,
-		pass:   pass,
-		idGen:  idGen,
-		log:    log.New("AuthService"),
+		reader:    reader,
+		writer:    writer,
+		auth:      auth,
+		pass:      pass,
+		refresh:   refresh,
+		twoFactor: twoFactor,
+		guard:     guard,
+		otp:       otp,
+		mailer:    mailer,
+		tx:        tx,
+		metrics:   recorder,
+		idGen:     idGen,
+		log:       log.New("AuthService"),
 	}
 }
 
-// Signin logging in
+// Signin logging in, failed signins are delayed progressively and lock the user eventually
 func (s *AuthService) Signin(ctx context.Context, req model.User) (*model.Signin, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Signin")
+	defer span.End()
+
 	s.log.Dbg().Ctx(ctx).Values("username", mask.String(string(req.Username))).Msg("Signin")
 
+	ip := common.GetClientIP(ctx)
+	if err := s.guard.Check(ctx, req.Username, ip); err != nil {
+		s.metrics.Event(metrics.EventSigninFailed)
+		return nil, err
+	}
+
 	user, err := s.reader.GetUserByUsername(ctx, req.Username)
 	if err != nil {
 		s.log.Wrn().Err(err).Ctx(ctx).Msg("GetUserByUsername")
-		return nil, apperr.ErrUnauthorized
+		s.metrics.Event(metrics.EventSigninFailed)
+		return nil, s.guard.Fail(ctx, req.Username, ip, nil)
+	}
+	// the password of a locked user is not checked, so guessing goes on only after the lock
+	if user.IsLocked(time.Now()) {
+		s.metrics.Event(metrics.EventSigninFailed)
+		return nil, lockedError(*user.LockedUntil)
 	}
 	if err = s.pass.Validate(req.Password, user.Password); err != nil {
 		s.log.Wrn().Err(err).Ctx(ctx).Msg("validatePassword")
+		s.metrics.Event(metrics.EventSigninFailed)
+		return nil, s.guard.Fail(ctx, req.Username, ip, user)
+	}
+	if user.Data.Status != model.UserStatusActive {
+		return nil, user.GetAppError()
+	}
+	// the failures are forgotten once the second factor is verified too
+	if user.TwoFactor {
+		challenge, err := s.twoFactor.Challenge(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &model.Signin{ChallengeToken: challenge}, nil
+	}
+	if err = s.guard.Succeed(ctx, req.Username); err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := s.refresh.Issue(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.signin(ctx, user, refreshToken)
+}
+
+// SigninTwoFactor completes the signin of a user with two-factor authentication,
+// wrong codes count as failed signins of the user, so they are delayed and lock the user eventually
+func (s *AuthService) SigninTwoFactor(ctx context.Context, challenge types.Token, code string) (*model.Signin, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.SigninTwoFactor")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Msg("SigninTwoFactor")
+
+	userID, err := s.twoFactor.ChallengeUser(ctx, challenge)
+	if err != nil {
+		s.metrics.Event(metrics.EventSigninFailed)
+		return nil, err
+	}
+
+	user, err := s.reader.GetUserByID(ctx, userID)
+	if err != nil {
+		s.log.Wrn().Err(err).Ctx(ctx).Msg("GetUserByID")
+		return nil, apperr.ErrUnauthorized
+	}
+
+	ip := common.GetClientIP(ctx)
+	if err = s.guard.Check(ctx, user.Username, ip); err != nil {
+		s.metrics.Event(metrics.EventSigninFailed)
+		return nil, err
+	}
+	if user.IsLocked(time.Now()) {
+		s.metrics.Event(metrics.EventSigninFailed)
+		return nil, lockedError(*user.LockedUntil)
+	}
+
+	if _, err = s.twoFactor.Verify(ctx, challenge, code); err != nil {
+		s.log.Wrn().Err(err).Ctx(ctx).Msg("Verify")
+		s.metrics.Event(metrics.EventSigninFailed)
+		if errors.Is(err, apperr.ErrInvalidOTP) || errors.Is(err, apperr.ErrTooManyAttempts) {
+			// the failure is reported as the wrong code unless it locks the user
+			if failErr := s.guard.Fail(ctx, user.Username, ip, user); !errors.Is(failErr, apperr.ErrUnauthorized) {
+				return nil, failErr
+			}
+		}
+		return nil, err
+	}
+	if err = s.guard.Succeed(ctx, user.Username); err != nil {
+		return nil, err
+	}
+	if user.Data.Status != model.UserStatusActive {
+		return nil, user.GetAppError()
+	}
+
+	refreshToken, err := s.refresh.Issue(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.signin(ctx, user, refreshToken)
+}
+
+// Refresh exchanges a refresh token for a new access token and a rotated refresh token
+func (s *AuthService) Refresh(ctx context.Context, token types.Token) (*model.Signin, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Refresh")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Msg("Refresh")
+
+	userID, refreshToken, err := s.refresh.Rotate(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.reader.GetUserByID(ctx, userID)
+	if err != nil {
+		s.log.Wrn().Err(err).Ctx(ctx).Msg("GetUserByID")
 		return nil, apperr.ErrUnauthorized
 	}
 	if user.Data.Status != model.UserStatusActive {
 		return nil, user.GetAppError()
 	}
 
+	return s.signin(ctx, user, refreshToken)
+}
+
+// Signout revokes the refresh token with its whole family
+func (s *AuthService) Signout(ctx context.Context, token types.Token) error {
+	ctx, span := tracing.Start(ctx, "AuthService.Signout")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Msg("Signout")
+
+	return s.refresh.Revoke(ctx, token)
+}
+
+func (s *AuthService) signin(ctx context.Context, user *model.User, refreshToken types.Token) (*model.Signin, error) {
 	token, expiresIn, err := s.auth.GenerateAccessToken(user.ID)
 	if err != nil {
 		s.log.Err(err).Ctx(ctx).Msg("GenerateAccessToken")
@@ -80,17 +273,25 @@ func (s *AuthService) Signin(ctx context.Context, req model.User) (*model.Signin
 	}
 
 	out := model.Signin{
-		AccessToken: token,
-		ExpiresIn:   expiresIn,
+		AccessToken:  token,
+		ExpiresIn:    expiresIn,
+		RefreshToken: refreshToken,
 	}
 
 	return &out, nil
 }
 
-// Signup signing up
+// Signup signing up, the user is created and the activation mail is queued in one transaction
 func (s *AuthService) Signup(ctx context.Context, input model.User) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Signup")
+	defer span.End()
+
 	s.log.Dbg().Ctx(ctx).Values("username", mask.String(string(input.Username))).Msg("Signup")
 
+	if err := s.pass.Check(ctx, input.Password, input.Username); err != nil {
+		return nil, err
+	}
+
 	hash, err := s.pass.Hash(input.Password)
 	if err != nil {
 		s.log.Err(err).Ctx(ctx).Msg("Failed to hash password")
@@ -100,7 +301,19 @@ func (s *AuthService) Signup(ctx context.Context, input model.User) (*model.User
 
 	input.ID = types.UserID(s.idGen.Generate())
 
-	user, err := s.writer.Create(ctx, input)
+	var user *model.User
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if user, err = s.writer.Create(ctx, input); err != nil {
+			return err
+		}
+
+		otp, err := s.otp.GenerateOTP(ctx, types.OTPPurposeActivation, user.Username)
+		if err != nil {
+			return err
+		}
+
+		return s.mailer.SendActivationMail(ctx, user.Username, user.Data.Language, otp)
+	})
 	if err != nil {
 		if errors.Is(err, apperr.ErrAlreadyExists) {
 			return nil, apperr.ErrAlreadyExists.WithFunc(
@@ -111,6 +324,7 @@ func (s *AuthService) Signup(ctx context.Context, input model.User) (*model.User
 		s.log.Err(err).Ctx(ctx).Msg("failed to create user")
 		return nil, apperr.ErrInternalServerError
 	}
+	s.metrics.Event(metrics.EventSignup)
 
 	return user, nil
 }
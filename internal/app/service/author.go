@@ -6,6 +6,7 @@ import (
 	"github.com/vlaship/book-catalog-go/internal/app/types"
 	"github.com/vlaship/book-catalog-go/internal/logger"
 	"github.com/vlaship/book-catalog-go/internal/snowflake"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
 )
 
 // AuthorReader is an interface for author reader
@@ -20,15 +21,20 @@ type AuthorReader interface {
 //
 //go:generate mockgen -destination=../../../test/mock/service/mock-author-writer.go -package=mock . AuthorWriter
 type AuthorWriter interface {
+	LockAuthor(ctx context.Context, authorID types.ID) (*model.Author, error)
 	CreateAuthor(ctx context.Context, author *model.Author) (*model.Author, error)
-	UpdateAuthor(ctx context.Context, authorID types.ID, author *model.Author) error
-	DeleteAuthor(ctx context.Context, authorID types.ID) error
+	UpdateAuthor(ctx context.Context, authorID types.ID, author *model.Author, version int64) error
+	DeleteAuthor(ctx context.Context, authorID types.ID, version int64) error
 }
 
 // AuthorService is a service for author
 type AuthorService struct {
 	reader AuthorReader
 	writer AuthorWriter
+	books  BookReader
+	tx     Transactor
+	audit  AuditRecorder
+	cache  *ReadCache
 	idGen  snowflake.IDGenerator
 	log    logger.Logger
 }
@@ -37,12 +43,20 @@ type AuthorService struct {
 func NewAuthorService(
 	reader AuthorReader,
 	writer AuthorWriter,
+	books BookReader,
+	tx Transactor,
+	audit AuditRecorder,
+	cache *ReadCache,
 	idGen snowflake.IDGenerator,
 	log logger.Logger,
 ) *AuthorService {
 	return &AuthorService{
 		reader: reader,
 		writer: writer,
+		books:  books,
+		tx:     tx,
+		audit:  audit,
+		cache:  cache,
 		idGen:  idGen,
 		log:    log.New("AuthorService"),
 	}
@@ -50,37 +64,123 @@ func NewAuthorService(
 
 // GetAuthors returns all authors
 func (s *AuthorService) GetAuthors(ctx context.Context) ([]model.Author, error) {
+	ctx, span := tracing.Start(ctx, "AuthorService.GetAuthors")
+	defer span.End()
+
 	s.log.Trc().Ctx(ctx).Msg("GetAuthors")
 
 	return s.reader.GetAuthors(ctx)
 }
 
-// GetAuthor returns author by id
+// GetAuthor returns author by id, authors are cached
 func (s *AuthorService) GetAuthor(ctx context.Context, authorID types.ID) (*model.Author, error) {
+	ctx, span := tracing.Start(ctx, "AuthorService.GetAuthor")
+	defer span.End()
+
 	s.log.Dbg().Ctx(ctx).Values("authorID", authorID).Msg("GetAuthor")
 
-	return s.reader.GetAuthor(ctx, authorID)
+	var author model.Author
+	if s.cache.Get(ctx, authorKey(authorID), &author) {
+		return &author, nil
+	}
+
+	out, err := s.reader.GetAuthor(ctx, authorID)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Put(ctx, authorKey(authorID), out)
+
+	return out, nil
 }
 
 // CreateAuthor inserts new author
 func (s *AuthorService) CreateAuthor(ctx context.Context, author *model.Author) (*model.Author, error) {
+	ctx, span := tracing.Start(ctx, "AuthorService.CreateAuthor")
+	defer span.End()
+
 	s.log.Dbg().Ctx(ctx).Values("author", author).Msg("CreateAuthorReq")
 
 	author.ID = types.ID(s.idGen.Generate())
 
-	return s.writer.CreateAuthor(ctx, author)
+	var out *model.Author
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		if out, err = s.writer.CreateAuthor(ctx, author); err != nil {
+			return err
+		}
+
+		return s.audit.Record(ctx, model.AuditActionCreate, model.AuditEntityAuthor, author.ID, nil, auditAuthor(author))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
 }
 
-// UpdateAuthor updates author by id
-func (s *AuthorService) UpdateAuthor(ctx context.Context, authorID types.ID, author *model.Author) error {
-	s.log.Dbg().Ctx(ctx).Values("authorID", authorID, "author", author).Msg("UpdateAuthorReq")
+// UpdateAuthor updates author by id, a non-zero version must match the current one
+func (s *AuthorService) UpdateAuthor(ctx context.Context, authorID types.ID, author *model.Author, version int64) error {
+	ctx, span := tracing.Start(ctx, "AuthorService.UpdateAuthor")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("authorID", authorID, "author", author, "version", version).Msg("UpdateAuthorReq")
+
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		before, err := s.writer.LockAuthor(ctx, authorID)
+		if err != nil {
+			return err
+		}
+		if err = checkVersion(before.Version, version); err != nil {
+			return err
+		}
+
+		if err = s.writer.UpdateAuthor(ctx, authorID, author, version); err != nil {
+			return err
+		}
+
+		return s.audit.Record(
+			ctx, model.AuditActionUpdate, model.AuditEntityAuthor, authorID, auditAuthor(before), auditAuthor(author),
+		)
+	})
+	if err != nil {
+		return err
+	}
+	s.cache.Evict(ctx, authorKey(authorID))
 
-	return s.writer.UpdateAuthor(ctx, authorID, author)
+	return nil
 }
 
-// DeleteAuthor deletes author by id
-func (s *AuthorService) DeleteAuthor(ctx context.Context, authorID types.ID) error {
-	s.log.Dbg().Ctx(ctx).Values("authorID", authorID).Msg("DeleteAuthor")
+// DeleteAuthor deletes author by id, a non-zero version must match the current one,
+// it fails with conflict while the author has books that are not deleted
+func (s *AuthorService) DeleteAuthor(ctx context.Context, authorID types.ID, version int64) error {
+	ctx, span := tracing.Start(ctx, "AuthorService.DeleteAuthor")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("authorID", authorID, "version", version).Msg("DeleteAuthor")
+
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		before, err := s.writer.LockAuthor(ctx, authorID)
+		if err != nil {
+			return err
+		}
+		if err = checkVersion(before.Version, version); err != nil {
+			return err
+		}
+
+		if err = checkNoBooks(ctx, s.books, authorID); err != nil {
+			return err
+		}
+
+		if err = s.writer.DeleteAuthor(ctx, authorID, version); err != nil {
+			return err
+		}
+
+		return s.audit.Record(ctx, model.AuditActionDelete, model.AuditEntityAuthor, authorID, auditAuthor(before), nil)
+	})
+	if err != nil {
+		return err
+	}
+	s.cache.Evict(ctx, authorKey(authorID))
 
-	return s.writer.DeleteAuthor(ctx, authorID)
+	return nil
 }
@@ -2,33 +2,43 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/vlaship/book-catalog-go/internal/app/model"
 	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"github.com/vlaship/book-catalog-go/internal/apperr"
 	"github.com/vlaship/book-catalog-go/internal/logger"
 	"github.com/vlaship/book-catalog-go/internal/snowflake"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
 )
 
 // BookReader is an interface for book reader
 //
 //go:generate mockgen -destination=../../../test/mock/service/mock-book-reader.go -package=mock . BookReader
 type BookReader interface {
-	GetBook(ctx context.Context, bookID types.ID) (*model.Book, error)
-	GetBooks(ctx context.Context) ([]model.Book, error)
+	GetBook(ctx context.Context, bookID types.ID, expand model.BookExpand) (*model.Book, error)
+	GetBookByISBN(ctx context.Context, isbn string, expand model.BookExpand) (*model.Book, error)
+	GetBooks(ctx context.Context, filter model.BookFilter) ([]model.Book, error)
+	StreamBooks(ctx context.Context, filter model.BookFilter, fn func(book *model.Book) error) error
 }
 
 // BookWriter is an interface for book writer
 //
 //go:generate mockgen -destination=../../../test/mock/service/mock-book-writer.go -package=mock . BookWriter
 type BookWriter interface {
+	LockBook(ctx context.Context, bookID types.ID) (*model.Book, error)
 	CreateBook(ctx context.Context, book *model.Book) (*model.Book, error)
-	UpdateBook(ctx context.Context, bookID types.ID, book *model.Book) error
-	DeleteBook(ctx context.Context, bookID types.ID) error
+	UpdateBook(ctx context.Context, bookID types.ID, book *model.Book, version int64) error
+	DeleteBook(ctx context.Context, bookID types.ID, version int64) error
 }
 
 // BookService is a service for book
 type BookService struct {
 	reader BookReader
 	writer BookWriter
+	tx     Transactor
+	audit  AuditRecorder
+	cache  *ReadCache
 	idGen  snowflake.IDGenerator
 	log    logger.Logger
 }
@@ -37,50 +47,180 @@ type BookService struct {
 func NewBookService(
 	reader BookReader,
 	writer BookWriter,
+	tx Transactor,
+	audit AuditRecorder,
+	cache *ReadCache,
 	idGen snowflake.IDGenerator,
 	log logger.Logger,
 ) *BookService {
 	return &BookService{
 		reader: reader,
 		writer: writer,
+		tx:     tx,
+		audit:  audit,
+		cache:  cache,
 		idGen:  idGen,
 		log:    log.New("BookService"),
 	}
 }
 
-// GetBook returns book by id
-func (s *BookService) GetBook(ctx context.Context, bookID types.ID) (*model.Book, error) {
-	s.log.Dbg().Ctx(ctx).Values("bookID", bookID).Msg("GetBook")
+// GetBook returns book by id, books without expanded relations are cached
+func (s *BookService) GetBook(ctx context.Context, bookID types.ID, expand model.BookExpand) (*model.Book, error) {
+	ctx, span := tracing.Start(ctx, "BookService.GetBook")
+	defer span.End()
 
-	return s.reader.GetBook(ctx, bookID)
+	s.log.Dbg().Ctx(ctx).Values("bookID", bookID, "expand", expand).Msg("GetBook")
+
+	if expand.Author {
+		return s.reader.GetBook(ctx, bookID, expand)
+	}
+
+	var book model.Book
+	if s.cache.Get(ctx, bookKey(bookID), &book) {
+		return &book, nil
+	}
+
+	out, err := s.reader.GetBook(ctx, bookID, expand)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Put(ctx, bookKey(bookID), out)
+
+	return out, nil
+}
+
+// GetBookByISBN returns book by normalized ISBN
+func (s *BookService) GetBookByISBN(ctx context.Context, isbn string, expand model.BookExpand) (*model.Book, error) {
+	ctx, span := tracing.Start(ctx, "BookService.GetBookByISBN")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("isbn", isbn, "expand", expand).Msg("GetBookByISBN")
+
+	return s.reader.GetBookByISBN(ctx, isbn, expand)
+}
+
+// GetBooks returns page of books by filter
+func (s *BookService) GetBooks(ctx context.Context, filter model.BookFilter) (*model.Page[model.Book], error) {
+	ctx, span := tracing.Start(ctx, "BookService.GetBooks")
+	defer span.End()
+
+	s.log.Trc().Ctx(ctx).Values("filter", filter).Msg("GetBooks")
+
+	limit := filter.Limit
+	filter.Limit++ // fetch one more to know if there is a next page
+
+	books, err := s.reader.GetBooks(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewPage(books, limit, filter.Sort.Cursor), nil
 }
 
-// GetBooks returns all books
-func (s *BookService) GetBooks(ctx context.Context) ([]model.Book, error) {
-	s.log.Trc().Ctx(ctx).Msg("GetBooks")
+// ExportBooks calls fn for every book by filter with its author embedded
+func (s *BookService) ExportBooks(ctx context.Context, filter model.BookFilter, fn func(book *model.Book) error) error {
+	ctx, span := tracing.Start(ctx, "BookService.ExportBooks")
+	defer span.End()
 
-	return s.reader.GetBooks(ctx)
+	s.log.Dbg().Ctx(ctx).Values("filter", filter).Msg("ExportBooks")
+
+	filter.Expand.Author = true
+
+	return s.reader.StreamBooks(ctx, filter, fn)
 }
 
 // CreateBook creates new book
 func (s *BookService) CreateBook(ctx context.Context, book *model.Book) (*model.Book, error) {
+	ctx, span := tracing.Start(ctx, "BookService.CreateBook")
+	defer span.End()
+
 	s.log.Dbg().Ctx(ctx).Values("book", book).Msg("CreateBook")
 
 	book.ID = types.ID(s.idGen.Generate())
 
-	return s.writer.CreateBook(ctx, book)
+	var out *model.Book
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		if out, err = s.writer.CreateBook(ctx, book); err != nil {
+			return duplicateISBN(err, book.ISBN)
+		}
+
+		return s.audit.Record(ctx, model.AuditActionCreate, model.AuditEntityBook, book.ID, nil, auditBook(book))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
 }
 
-// UpdateBook updates book
-func (s *BookService) UpdateBook(ctx context.Context, bookID types.ID, book *model.Book) error {
-	s.log.Dbg().Ctx(ctx).Values("bookID", bookID, "book", book).Msg("UpdateBook")
+// UpdateBook updates book, a non-zero version must match the current one
+func (s *BookService) UpdateBook(ctx context.Context, bookID types.ID, book *model.Book, version int64) error {
+	ctx, span := tracing.Start(ctx, "BookService.UpdateBook")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("bookID", bookID, "book", book, "version", version).Msg("UpdateBook")
+
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		before, err := s.writer.LockBook(ctx, bookID)
+		if err != nil {
+			return err
+		}
+		if err = checkVersion(before.Version, version); err != nil {
+			return err
+		}
+
+		if err = s.writer.UpdateBook(ctx, bookID, book, version); err != nil {
+			return duplicateISBN(err, book.ISBN)
+		}
+
+		return s.audit.Record(ctx, model.AuditActionUpdate, model.AuditEntityBook, bookID, auditBook(before), auditBook(book))
+	})
+	if err != nil {
+		return err
+	}
+	s.cache.Evict(ctx, bookKey(bookID))
 
-	return s.writer.UpdateBook(ctx, bookID, book)
+	return nil
 }
 
-// DeleteBook deletes book
-func (s *BookService) DeleteBook(ctx context.Context, bookID types.ID) error {
-	s.log.Dbg().Ctx(ctx).Values("bookID", bookID).Msg("DeleteBook")
+// DeleteBook deletes book, a non-zero version must match the current one
+func (s *BookService) DeleteBook(ctx context.Context, bookID types.ID, version int64) error {
+	ctx, span := tracing.Start(ctx, "BookService.DeleteBook")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("bookID", bookID, "version", version).Msg("DeleteBook")
+
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		before, err := s.writer.LockBook(ctx, bookID)
+		if err != nil {
+			return err
+		}
+		if err = checkVersion(before.Version, version); err != nil {
+			return err
+		}
+
+		if err = s.writer.DeleteBook(ctx, bookID, version); err != nil {
+			return err
+		}
+
+		return s.audit.Record(ctx, model.AuditActionDelete, model.AuditEntityBook, bookID, auditBook(before), nil)
+	})
+	if err != nil {
+		return err
+	}
+	s.cache.Evict(ctx, bookKey(bookID))
 
-	return s.writer.DeleteBook(ctx, bookID)
+	return nil
+}
+
+// duplicateISBN reports the violation of the unique ISBN of books that are not deleted
+func duplicateISBN(err error, isbn string) error {
+	if errors.Is(err, apperr.ErrAlreadyExists) {
+		return apperr.ErrAlreadyExists.WithFunc(
+			apperr.WithDetail(fmt.Sprintf("Book with ISBN [%s] already exists", isbn)),
+			apperr.WithTitle("Book already exists"),
+		)
+	}
+	return err
 }
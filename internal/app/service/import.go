@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"github.com/vlaship/book-catalog-go/internal/apperr"
+	"github.com/vlaship/book-catalog-go/internal/database"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/snowflake"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
+	"strings"
+)
+
+// ImportAuthorStore is an interface for author lookup and bulk insert
+//
+//go:generate mockgen -destination=../../../test/mock/service/mock-import-author-store.go -package=mock . ImportAuthorStore
+type ImportAuthorStore interface {
+	GetAuthorsByIDs(ctx context.Context, authorIDs []types.ID) ([]model.Author, error)
+	GetAuthorsByNames(ctx context.Context, names []string) ([]model.Author, error)
+	CopyAuthors(ctx context.Context, authors []model.Author) error
+}
+
+// ImportBookStore is an interface for book lookup and bulk insert
+//
+//go:generate mockgen -destination=../../../test/mock/service/mock-import-book-store.go -package=mock . ImportBookStore
+type ImportBookStore interface {
+	GetBooksByISBNs(ctx context.Context, isbns []string) ([]model.Book, error)
+	CopyBooks(ctx context.Context, books []model.Book) error
+}
+
+// ImportService is a service for bulk import of books and authors
+type ImportService struct {
+	authors ImportAuthorStore
+	books   ImportBookStore
+	tx      Transactor
+	audit   AuditRecorder
+	idGen   snowflake.IDGenerator
+	log     logger.Logger
+}
+
+// NewImportService creates new import service
+func NewImportService(
+	authors ImportAuthorStore,
+	books ImportBookStore,
+	tx Transactor,
+	audit AuditRecorder,
+	idGen snowflake.IDGenerator,
+	log logger.Logger,
+) *ImportService {
+	return &ImportService{
+		authors: authors,
+		books:   books,
+		tx:      tx,
+		audit:   audit,
+		idGen:   idGen,
+		log:     log.New("ImportService"),
+	}
+}
+
+// ImportBooks inserts a batch of books in one transaction, authors referenced by name are looked up
+// and created when missing, rows that can not be imported are reported and skipped,
+// nothing is written in dry run mode. A batch rejected by the database because of its data is reported
+// on every row, other failures are returned.
+func (s *ImportService) ImportBooks(ctx context.Context, rows []model.ImportBook, dryRun bool) (*model.ImportResult, error) {
+	ctx, span := tracing.Start(ctx, "ImportService.ImportBooks")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("rows", len(rows), "dryRun", dryRun).Msg("ImportBooks")
+
+	var result *model.ImportResult
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		books, authors, errs, err := s.resolve(ctx, rows)
+		if err != nil {
+			return err
+		}
+
+		result = &model.ImportResult{
+			Imported:       len(books),
+			AuthorsCreated: len(authors),
+			Errors:         errs,
+		}
+		if dryRun {
+			return nil
+		}
+
+		return s.write(ctx, books, authors)
+	})
+	if err != nil && database.IsDataError(err) {
+		// the whole batch is rolled back, e.g. by a constraint violation
+		s.log.Wrn().Err(err).Ctx(ctx).Msg("failed to import batch")
+		return failedBatch(rows), nil
+	}
+	if err != nil {
+		s.log.Err(err).Ctx(ctx).Msg("failed to import batch")
+		return nil, apperr.ErrInternalServerError
+	}
+
+	return result, nil
+}
+
+// resolve assigns IDs and authors to books, it returns new authors and errors of rejected rows,
+// a row is rejected when its ISBN is taken by a book or by an earlier row
+func (s *ImportService) resolve(
+	ctx context.Context,
+	rows []model.ImportBook,
+) ([]model.Book, []model.Author, []model.ImportError, error) {
+	known, err := s.knownAuthorIDs(ctx, rows)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	byName, err := s.authorsByName(ctx, rows)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	isbns, err := s.knownISBNs(ctx, rows)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var (
+		books   = make([]model.Book, 0, len(rows))
+		authors []model.Author
+		errs    []model.ImportError
+	)
+	for i := range rows {
+		row := &rows[i]
+		book := row.Book
+
+		if _, ok := isbns[book.ISBN]; ok {
+			errs = append(errs, model.ImportError{Line: row.Line, Error: fmt.Sprintf("book with isbn %s already exists", book.ISBN)})
+			continue
+		}
+
+		if row.Author == nil {
+			if _, ok := known[book.AuthorID]; !ok {
+				errs = append(errs, model.ImportError{Line: row.Line, Error: fmt.Sprintf("author %d not found", book.AuthorID)})
+				continue
+			}
+		} else {
+			key := strings.ToLower(row.Author.Name)
+			author, ok := byName[key]
+			if !ok {
+				if row.Author.Dob.IsZero() {
+					errs = append(errs, model.ImportError{
+						Line:  row.Line,
+						Error: fmt.Sprintf("author %q not found, author_dob is required to create it", row.Author.Name),
+					})
+					continue
+				}
+				author = *row.Author
+				author.ID = types.ID(s.idGen.Generate())
+				byName[key] = author
+				authors = append(authors, author)
+			}
+			book.AuthorID = author.ID
+		}
+
+		book.ID = types.ID(s.idGen.Generate())
+		isbns[book.ISBN] = struct{}{}
+		books = append(books, book)
+	}
+
+	return books, authors, errs, nil
+}
+
+// knownAuthorIDs returns the set of existing authors referenced by ID
+func (s *ImportService) knownAuthorIDs(ctx context.Context, rows []model.ImportBook) (map[types.ID]struct{}, error) {
+	seen := map[types.ID]struct{}{}
+	var ids []types.ID
+	for i := range rows {
+		if rows[i].Author != nil {
+			continue
+		}
+		if _, ok := seen[rows[i].Book.AuthorID]; !ok {
+			seen[rows[i].Book.AuthorID] = struct{}{}
+			ids = append(ids, rows[i].Book.AuthorID)
+		}
+	}
+
+	known := map[types.ID]struct{}{}
+	if len(ids) == 0 {
+		return known, nil
+	}
+
+	authors, err := s.authors.GetAuthorsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range authors {
+		known[authors[i].ID] = struct{}{}
+	}
+
+	return known, nil
+}
+
+// knownISBNs returns the set of ISBNs of the rows that are taken by books
+func (s *ImportService) knownISBNs(ctx context.Context, rows []model.ImportBook) (map[string]struct{}, error) {
+	isbns := make([]string, len(rows))
+	for i := range rows {
+		isbns[i] = rows[i].Book.ISBN
+	}
+
+	known := map[string]struct{}{}
+	if len(isbns) == 0 {
+		return known, nil
+	}
+
+	books, err := s.books.GetBooksByISBNs(ctx, isbns)
+	if err != nil {
+		return nil, err
+	}
+	for i := range books {
+		known[books[i].ISBN] = struct{}{}
+	}
+
+	return known, nil
+}
+
+// authorsByName returns existing authors referenced by name keyed by lower case name,
+// the oldest author wins when the name is not unique
+func (s *ImportService) authorsByName(ctx context.Context, rows []model.ImportBook) (map[string]model.Author, error) {
+	seen := map[string]struct{}{}
+	var names []string
+	for i := range rows {
+		if rows[i].Author == nil {
+			continue
+		}
+		key := strings.ToLower(rows[i].Author.Name)
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			names = append(names, key)
+		}
+	}
+
+	byName := map[string]model.Author{}
+	if len(names) == 0 {
+		return byName, nil
+	}
+
+	authors, err := s.authors.GetAuthorsByNames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	for i := range authors {
+		key := strings.ToLower(authors[i].Name)
+		if _, ok := byName[key]; !ok {
+			byName[key] = authors[i]
+		}
+	}
+
+	return byName, nil
+}
+
+// write inserts new authors and books and records their creation
+func (s *ImportService) write(ctx context.Context, books []model.Book, authors []model.Author) error {
+	if len(authors) > 0 {
+		if err := s.authors.CopyAuthors(ctx, authors); err != nil {
+			return err
+		}
+
+		created := make(map[types.ID]audit, len(authors))
+		for i := range authors {
+			created[authors[i].ID] = auditAuthor(&authors[i])
+		}
+		if err := s.audit.RecordCreated(ctx, model.AuditEntityAuthor, created); err != nil {
+			return err
+		}
+	}
+
+	if len(books) > 0 {
+		if err := s.books.CopyBooks(ctx, books); err != nil {
+			return err
+		}
+
+		created := make(map[types.ID]audit, len(books))
+		for i := range books {
+			created[books[i].ID] = auditBook(&books[i])
+		}
+		if err := s.audit.RecordCreated(ctx, model.AuditEntityBook, created); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// failedBatch reports the failure on every row of the batch, the cause is logged only
+func failedBatch(rows []model.ImportBook) *model.ImportResult {
+	errs := make([]model.ImportError, len(rows))
+	for i := range rows {
+		errs[i] = model.ImportError{Line: rows[i].Line, Error: "batch is not imported, it could not be written to the catalog"}
+	}
+
+	return &model.ImportResult{Errors: errs}
+}
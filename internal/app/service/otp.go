@@ -2,75 +2,164 @@ package service
 
 import (
 	"context"
+	"crypto/subtle"
+	"fmt"
 	"github.com/vlaship/book-catalog-go/internal/app/types"
 	"github.com/vlaship/book-catalog-go/internal/apperr"
 	"github.com/vlaship/book-catalog-go/internal/cache"
+	"github.com/vlaship/book-catalog-go/internal/config"
 	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
 	"github.com/vlaship/go-mask"
 	"github.com/vlaship/go-otp"
 	"time"
 )
 
-const (
-	ttlActivation = 48 * time.Hour
-	ttlReset      = 1 * time.Hour
-)
+// otpRecord is the live otp of a user for a purpose
+type otpRecord struct {
+	OTP      types.Token      `json:"otp"`
+	Purpose  types.OTPPurpose `json:"purpose"`
+	Username types.Username   `json:"username"`
+}
 
 // OTPService is a service for token.
+// A user has at most one live otp per purpose, and failed validations of a purpose
+// are counted per user, reaching the max attempts locks the purpose out until the lockout expires.
 type OTPService struct {
-	cacher cache.Cache
-	log    logger.Logger
+	cacher      cache.Cache
+	ttls        map[types.OTPPurpose]time.Duration
+	maxAttempts int64
+	lockout     time.Duration
+	log         logger.Logger
 }
 
 // NewOTPService creates a new OTPService instance.
 func NewOTPService(
+	cfg *config.Config,
 	cacher cache.Cache,
 	log logger.Logger,
 ) *OTPService {
 	return &OTPService{
 		cacher: cacher,
-		log:    log.New("OTPService"),
+		ttls: map[types.OTPPurpose]time.Duration{
+			types.OTPPurposeActivation:    cfg.Cache.Activate,
+			types.OTPPurposeResetPassword: cfg.Cache.ResetPass,
+			types.OTPPurposeChangeEmail:   cfg.Cache.ChangeEmail,
+		},
+		maxAttempts: cfg.OTP.MaxAttempts,
+		lockout:     cfg.OTP.Lockout,
+		log:         log.New("OTPService"),
 	}
 }
 
-// GenerateActivationOTP generates activation otp
-func (s *OTPService) GenerateActivationOTP(ctx context.Context, username types.Username) types.Token {
-	s.log.Trc().Ctx(ctx).Values("username", mask.String(string(username))).Msg("GenerateActivationOTP")
-
-	o := otp.Generate()
-	s.cacher.Put(o, username, ttlActivation)
+func otpKey(purpose types.OTPPurpose, username types.Username) string {
+	return fmt.Sprintf("otp:%s:%s", purpose, username)
+}
 
-	return types.Token(o)
+func otpAttemptsKey(purpose types.OTPPurpose, username types.Username) string {
+	return fmt.Sprintf("otp-attempts:%s:%s", purpose, username)
 }
 
-// ValidateActivationOTP validates activation otp
-func (s *OTPService) ValidateActivationOTP(ctx context.Context, otp types.Token) (types.Username, error) {
-	s.log.Trc().Ctx(ctx).Values("otp", otp).Msg("ValidateActivationOTP")
+// GenerateOTP generates otp of the user for the purpose, it replaces the previous otp of the purpose
+func (s *OTPService) GenerateOTP(ctx context.Context, purpose types.OTPPurpose, username types.Username) (types.Token, error) {
+	ctx, span := tracing.Start(ctx, "OTPService.GenerateOTP")
+	defer span.End()
 
-	username, ok := s.cacher.GetDel(string(otp))
+	s.log.Trc().Ctx(ctx).Values("purpose", purpose, "username", mask.String(string(username))).Msg("GenerateOTP")
+
+	ttl, ok := s.ttls[purpose]
 	if !ok {
-		return "", apperr.ErrInvalidOTP
+		return "", fmt.Errorf("unknown otp purpose: %s", purpose)
+	}
+
+	record := otpRecord{
+		OTP:      types.Token(otp.Generate()),
+		Purpose:  purpose,
+		Username: username,
+	}
+	if err := s.cacher.Put(ctx, otpKey(purpose, username), record, ttl); err != nil {
+		return "", err
+	}
+
+	return record.OTP, nil
+}
+
+// ValidateOTP validates otp of the user for the purpose, a valid otp is consumed
+func (s *OTPService) ValidateOTP(
+	ctx context.Context,
+	purpose types.OTPPurpose,
+	username types.Username,
+	token types.Token,
+) error {
+	ctx, span := tracing.Start(ctx, "OTPService.ValidateOTP")
+	defer span.End()
+
+	s.log.Trc().Ctx(ctx).Values("purpose", purpose, "username", mask.String(string(username))).Msg("ValidateOTP")
+
+	if err := s.checkAttempts(ctx, purpose, username); err != nil {
+		return err
+	}
+
+	var record otpRecord
+	ok, err := s.cacher.Get(ctx, otpKey(purpose, username), &record)
+	if err != nil {
+		return err
+	}
+	if !ok || !record.matches(purpose, token) {
+		return s.fail(ctx, purpose, username)
+	}
+
+	// consume the otp, a concurrent validation or a resend may have taken it since
+	ok, err = s.cacher.GetDel(ctx, otpKey(purpose, username), &record)
+	if err != nil {
+		return err
+	}
+	if !ok || !record.matches(purpose, token) {
+		return apperr.ErrInvalidOTP
 	}
 
-	return username.(types.Username), nil
+	return s.cacher.Del(ctx, otpAttemptsKey(purpose, username))
 }
 
-func (s *OTPService) GenerateResetPasswordOTP(ctx context.Context, username types.Username) types.Token {
-	s.log.Trc().Ctx(ctx).Values("username", mask.String(string(username))).Msg("GenerateResetPasswordOTP")
+// checkAttempts fails while the purpose of the user is locked out
+func (s *OTPService) checkAttempts(ctx context.Context, purpose types.OTPPurpose, username types.Username) error {
+	if s.maxAttempts <= 0 {
+		return nil
+	}
 
-	o := otp.Generate()
-	s.cacher.Put(o, username, ttlReset)
+	var attempts int64
+	if _, err := s.cacher.Get(ctx, otpAttemptsKey(purpose, username), &attempts); err != nil {
+		return err
+	}
+	if attempts >= s.maxAttempts {
+		return apperr.ErrTooManyAttempts
+	}
 
-	return types.Token(o)
+	return nil
 }
 
-func (s *OTPService) ValidateResetPasswordOTP(ctx context.Context, otp types.Token) (types.Username, error) {
-	s.log.Trc().Ctx(ctx).Values("otp", otp).Msg("ValidateResetPasswordOTP")
+// fail counts the failed attempt, the otp is dropped once the max attempts is reached
+func (s *OTPService) fail(ctx context.Context, purpose types.OTPPurpose, username types.Username) error {
+	if s.maxAttempts <= 0 {
+		return apperr.ErrInvalidOTP
+	}
 
-	username, ok := s.cacher.GetDel(string(otp))
-	if !ok {
-		return "", apperr.ErrInvalidOTP
+	attempts, err := s.cacher.Incr(ctx, otpAttemptsKey(purpose, username), s.lockout)
+	if err != nil {
+		return err
 	}
+	if attempts < s.maxAttempts {
+		return apperr.ErrInvalidOTP
+	}
+
+	s.log.Wrn().Ctx(ctx).Values("purpose", purpose, "username", mask.String(string(username))).Msg("otp locked out")
+	if err = s.cacher.Del(ctx, otpKey(purpose, username)); err != nil {
+		return err
+	}
+
+	return apperr.ErrTooManyAttempts
+}
 
-	return username.(types.Username), nil
+func (r *otpRecord) matches(purpose types.OTPPurpose, token types.Token) bool {
+	return r.Purpose == purpose && subtle.ConstantTimeCompare([]byte(r.OTP), []byte(token)) == 1
 }
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"github.com/vlaship/book-catalog-go/internal/config"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/metrics"
+	"github.com/vlaship/book-catalog-go/internal/snowflake"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
+	"github.com/vlaship/go-mask"
+	"time"
+)
+
+// OutboxStore is an interface for outbox storage
+//
+//go:generate mockgen -destination=../../../test/mock/service/mock-outbox-store.go -package=mock . OutboxStore
+type OutboxStore interface {
+	CreateOutboxMessage(ctx context.Context, msg *model.OutboxMessage) (*model.OutboxMessage, error)
+	ClaimOutboxMessages(ctx context.Context, now time.Time, lease time.Time, limit int) ([]model.OutboxMessage, error)
+	MarkOutboxMessageSent(ctx context.Context, id types.ID) error
+	MarkOutboxMessageFailed(
+		ctx context.Context,
+		id types.ID,
+		status model.OutboxStatus,
+		lastError string,
+		next time.Time,
+	) error
+}
+
+// MailDeliverer is an interface for synchronous mail delivery
+//
+//go:generate mockgen -destination=../../../test/mock/service/mock-mail-deliverer.go -package=mock . MailDeliverer
+type MailDeliverer interface {
+	SendActivationMail(ctx context.Context, to types.Username, lang string, otp types.Token) error
+	SendResetPasswordMail(ctx context.Context, to types.Username, lang string, otp types.Token) error
+	SendChangeEmailMail(ctx context.Context, to types.Username, lang string, otp types.Token) error
+	SendUserLockedMail(ctx context.Context, to types.Username, lang string, until time.Time, ip string) error
+}
+
+// OutboxService queues outgoing mails in the outbox and delivers them in background.
+// Mails are queued in the transaction carried by ctx, so they are sent only if the change that
+// triggers them is committed. A failed delivery is retried with exponential backoff, and a mail
+// that runs out of attempts is dead-lettered.
+type OutboxService struct {
+	store       OutboxStore
+	deliverer   MailDeliverer
+	idGen       snowflake.IDGenerator
+	metrics     metrics.Recorder
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	lease       time.Duration
+	sendTimeout time.Duration
+	drain       time.Duration
+	log         logger.Logger
+}
+
+// NewOutboxService creates new outbox service
+func NewOutboxService(
+	cfg *config.Config,
+	store OutboxStore,
+	deliverer MailDeliverer,
+	idGen snowflake.IDGenerator,
+	recorder metrics.Recorder,
+	log logger.Logger,
+) *OutboxService {
+	return &OutboxService{
+		store:       store,
+		deliverer:   deliverer,
+		idGen:       idGen,
+		metrics:     recorder,
+		interval:    cfg.Outbox.PollInterval,
+		batchSize:   cfg.Outbox.BatchSize,
+		maxAttempts: cfg.Outbox.MaxAttempts,
+		backoff:     cfg.Outbox.Backoff,
+		maxBackoff:  cfg.Outbox.MaxBackoff,
+		lease:       cfg.Outbox.Lease,
+		sendTimeout: cfg.SMTP.Timeout,
+		drain:       cfg.ServerProps.CancelContextTimeout,
+		log:         log.New("OutboxService"),
+	}
+}
+
+// SendActivationMail queues activation mail
+func (s *OutboxService) SendActivationMail(ctx context.Context, to types.Username, lang string, otp types.Token) error {
+	ctx, span := tracing.Start(ctx, "OutboxService.SendActivationMail")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("to", mask.String(string(to))).Msg("SendActivationMail")
+
+	return s.enqueue(ctx, model.OutboxKindActivationMail, to, model.MailPayload{OTP: otp, Language: lang})
+}
+
+// SendResetPasswordMail queues reset password mail
+func (s *OutboxService) SendResetPasswordMail(
+	ctx context.Context,
+	to types.Username,
+	lang string,
+	otp types.Token,
+) error {
+	ctx, span := tracing.Start(ctx, "OutboxService.SendResetPasswordMail")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("to", mask.String(string(to))).Msg("SendResetPasswordMail")
+
+	return s.enqueue(ctx, model.OutboxKindResetPasswordMail, to, model.MailPayload{OTP: otp, Language: lang})
+}
+
+// SendChangeEmailMail queues change email mail, it goes to the new email
+func (s *OutboxService) SendChangeEmailMail(
+	ctx context.Context,
+	to types.Username,
+	lang string,
+	otp types.Token,
+) error {
+	ctx, span := tracing.Start(ctx, "OutboxService.SendChangeEmailMail")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("to", mask.String(string(to))).Msg("SendChangeEmailMail")
+
+	return s.enqueue(ctx, model.OutboxKindChangeEmailMail, to, model.MailPayload{OTP: otp, Language: lang})
+}
+
+// SendUserLockedMail queues security notification of the user being locked after failed signins
+func (s *OutboxService) SendUserLockedMail(
+	ctx context.Context,
+	to types.Username,
+	lang string,
+	until time.Time,
+	ip string,
+) error {
+	ctx, span := tracing.Start(ctx, "OutboxService.SendUserLockedMail")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("to", mask.String(string(to))).Msg("SendUserLockedMail")
+
+	payload := model.MailPayload{Language: lang, LockedUntil: &until, IP: ip}
+	return s.enqueue(ctx, model.OutboxKindUserLockedMail, to, payload)
+}
+
+func (s *OutboxService) enqueue(
+	ctx context.Context,
+	kind model.OutboxKind,
+	to types.Username,
+	mail model.MailPayload,
+) error {
+	payload, err := json.Marshal(mail)
+	if err != nil {
+		return err
+	}
+
+	msg := model.OutboxMessage{
+		ID:        types.ID(s.idGen.Generate()),
+		Kind:      kind,
+		Recipient: to,
+		Payload:   payload,
+	}
+	_, err = s.store.CreateOutboxMessage(ctx, &msg)
+
+	return err
+}
+
+// Run delivers due mails every poll interval until ctx is done, then it keeps delivering
+// the due mails for up to the shutdown timeout, so queued mails are not left behind
+func (s *OutboxService) Run(ctx context.Context) {
+	s.log.Inf().Values("interval", s.interval, "batch", s.batchSize).Msg("outbox worker is started")
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.deliverDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			drainCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.drain)
+			s.deliverDue(drainCtx)
+			cancel()
+
+			s.log.Inf().Msg("outbox worker is stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliverDue delivers batches of due mails while full batches are claimed and ctx is not done
+func (s *OutboxService) deliverDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		claimed, err := s.deliverBatch(ctx)
+		if err != nil {
+			s.log.Err(err).Ctx(ctx).Msg("failed to deliver outbox")
+			return
+		}
+		if claimed < s.batchSize {
+			return
+		}
+	}
+}
+
+// deliverBatch claims a batch of due mails and delivers them, a claimed batch is delivered
+// even if ctx is done meanwhile, every delivery is bounded by the SMTP timeout.
+// A mail is sent only if its delivery ends within the lease, so another worker cannot claim
+// and send it again meanwhile, the mails left over are claimed again once the lease expires.
+func (s *OutboxService) deliverBatch(ctx context.Context) (int, error) {
+	now := time.Now()
+	leaseEnd := now.Add(s.lease)
+	msgs, err := s.store.ClaimOutboxMessages(ctx, now, leaseEnd, s.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	for i := range msgs {
+		if time.Now().Add(s.sendTimeout).After(leaseEnd) {
+			s.log.Wrn().Ctx(ctx).Values("left", len(msgs)-i, "lease", s.lease).
+				Msg("outbox lease is running out, the mails left are delivered after it expires")
+			return i, nil
+		}
+		s.deliver(ctx, &msgs[i])
+	}
+
+	return len(msgs), nil
+}
+
+func (s *OutboxService) deliver(ctx context.Context, msg *model.OutboxMessage) {
+	ctx, span := tracing.Start(ctx, "OutboxService.deliver")
+	defer span.End()
+
+	err := s.send(ctx, msg)
+	if err == nil {
+		if err = s.store.MarkOutboxMessageSent(ctx, msg.ID); err != nil {
+			s.log.Err(err).Ctx(ctx).Values("outboxID", msg.ID).Msg("failed to mark outbox message sent")
+		}
+		return
+	}
+
+	attempts := msg.Attempts + 1
+	status := model.OutboxStatusPending
+	if attempts >= s.maxAttempts {
+		status = model.OutboxStatusDead
+		s.metrics.Event(metrics.EventMailDead)
+		s.log.Err(err).Ctx(ctx).Values("outboxID", msg.ID, "kind", msg.Kind, "attempts", attempts).
+			Msg("outbox message is dead-lettered")
+	} else {
+		s.log.Wrn().Err(err).Ctx(ctx).Values("outboxID", msg.ID, "kind", msg.Kind, "attempts", attempts).
+			Msg("failed to deliver outbox message")
+	}
+
+	next := time.Now().Add(backoff(s.backoff, s.maxBackoff, attempts))
+	if err = s.store.MarkOutboxMessageFailed(ctx, msg.ID, status, err.Error(), next); err != nil {
+		s.log.Err(err).Ctx(ctx).Values("outboxID", msg.ID).Msg("failed to mark outbox message failed")
+	}
+}
+
+func (s *OutboxService) send(ctx context.Context, msg *model.OutboxMessage) error {
+	var payload model.MailPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		return fmt.Errorf("invalid payload: %w", err)
+	}
+
+	switch msg.Kind {
+	case model.OutboxKindActivationMail:
+		return s.deliverer.SendActivationMail(ctx, msg.Recipient, payload.Language, payload.OTP)
+	case model.OutboxKindResetPasswordMail:
+		return s.deliverer.SendResetPasswordMail(ctx, msg.Recipient, payload.Language, payload.OTP)
+	case model.OutboxKindChangeEmailMail:
+		return s.deliverer.SendChangeEmailMail(ctx, msg.Recipient, payload.Language, payload.OTP)
+	case model.OutboxKindUserLockedMail:
+		if payload.LockedUntil == nil {
+			return fmt.Errorf("invalid payload: missing locked until")
+		}
+		return s.deliverer.SendUserLockedMail(ctx, msg.Recipient, payload.Language, *payload.LockedUntil, payload.IP)
+	default:
+		return fmt.Errorf("unknown outbox message kind: %s", msg.Kind)
+	}
+}
+
+// backoff returns the delay before the next attempt, it doubles with every attempt up to the max
+func backoff(base, limit time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < limit; i++ {
+		delay *= 2
+	}
+
+	return min(delay, limit)
+}
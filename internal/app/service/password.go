@@ -1,19 +1,28 @@
 package service
 
 import (
+	"context"
 	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"github.com/vlaship/book-catalog-go/internal/apperr"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/password"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // PasswordService is a service for password.
 type PasswordService struct {
-	cost int
+	cost   int
+	policy password.Policy
+	log    logger.Logger
 }
 
 // NewPasswordService creates a new PasswordService instance.
-func NewPasswordService() *PasswordService {
+func NewPasswordService(policy password.Policy, log logger.Logger) *PasswordService {
 	return &PasswordService{
-		cost: bcrypt.DefaultCost,
+		cost:   bcrypt.DefaultCost,
+		policy: policy,
+		log:    log.New("PasswordService"),
 	}
 }
 
@@ -34,3 +43,25 @@ func (s *PasswordService) Hash(password types.Password) (types.Password, error)
 
 	return types.Password(hash), nil
 }
+
+// Check checks the new password of the user against the password policy, the failed rules are listed in the error
+func (s *PasswordService) Check(ctx context.Context, pass types.Password, username types.Username) error {
+	ctx, span := tracing.Start(ctx, "PasswordService.Check")
+	defer span.End()
+
+	violations, err := s.policy.Check(ctx, string(pass), string(username))
+	if err != nil {
+		s.log.Err(err).Ctx(ctx).Msg("failed to check password policy")
+		return apperr.ErrInternalServerError
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+
+	out := make([]apperr.Violation, 0, len(violations))
+	for _, v := range violations {
+		out = append(out, apperr.Violation{Rule: v.Rule, Detail: v.Detail})
+	}
+
+	return apperr.ErrWeakPassword.WithFunc(apperr.WithViolations(out))
+}
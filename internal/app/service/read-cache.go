@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"github.com/vlaship/book-catalog-go/internal/cache"
+	"github.com/vlaship/book-catalog-go/internal/config"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+	"time"
+)
+
+// ReadCache caches reads of books and authors by id,
+// failures of the cache are logged and the reads fall back to the database
+type ReadCache struct {
+	cacher cache.Cache
+	ttl    time.Duration
+	log    logger.Logger
+}
+
+// NewReadCache creates new read cache, a zero ttl disables it
+func NewReadCache(cfg *config.Config, cacher cache.Cache, log logger.Logger) *ReadCache {
+	return &ReadCache{
+		cacher: cacher,
+		ttl:    cfg.Cache.ReadTTL,
+		log:    log.New("ReadCache"),
+	}
+}
+
+func bookKey(bookID types.ID) string {
+	return fmt.Sprintf("book:%d", bookID)
+}
+
+func authorKey(authorID types.ID) string {
+	return fmt.Sprintf("author:%d", authorID)
+}
+
+// Get decodes the cached value of key into dest and reports whether it was found
+func (c *ReadCache) Get(ctx context.Context, key string, dest any) bool {
+	if c.ttl <= 0 {
+		return false
+	}
+
+	ok, err := c.cacher.Get(ctx, key, dest)
+	if err != nil {
+		c.log.Wrn().Ctx(ctx).Err(err).Values("key", key).Msg("failed to get cached value")
+		return false
+	}
+
+	return ok
+}
+
+// Put caches the value of key
+func (c *ReadCache) Put(ctx context.Context, key string, value any) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	if err := c.cacher.Put(ctx, key, value, c.ttl); err != nil {
+		c.log.Wrn().Ctx(ctx).Err(err).Values("key", key).Msg("failed to cache value")
+	}
+}
+
+// Evict removes the cached values of keys, it is called after the write is committed
+// so the next read loads the committed value
+func (c *ReadCache) Evict(ctx context.Context, keys ...string) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	if err := c.cacher.Del(ctx, keys...); err != nil {
+		c.log.Wrn().Ctx(ctx).Err(err).Values("keys", keys).Msg("failed to evict cached values")
+	}
+}
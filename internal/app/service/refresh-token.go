@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"github.com/vlaship/book-catalog-go/internal/apperr"
+	"github.com/vlaship/book-catalog-go/internal/config"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/snowflake"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
+	"time"
+)
+
+const refreshTokenBytes = 32
+
+// RefreshTokenStore is an interface for refresh token storage
+//
+//go:generate mockgen -destination=../../../test/mock/service/mock-refresh-token-store.go -package=mock . RefreshTokenStore
+type RefreshTokenStore interface {
+	CreateRefreshToken(ctx context.Context, token *model.RefreshToken) (*model.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, hash string, next *model.RefreshToken) (*model.RefreshToken, error)
+	GetRefreshToken(ctx context.Context, hash string) (*model.RefreshToken, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID types.ID) error
+	RevokeRefreshTokenFamilyByHash(ctx context.Context, hash string) error
+	RevokeRefreshTokens(ctx context.Context, userID types.UserID) error
+}
+
+// RefreshTokenService is a service for opaque refresh tokens with rotation and reuse detection.
+type RefreshTokenService struct {
+	store    RefreshTokenStore
+	idGen    snowflake.IDGenerator
+	duration time.Duration
+	log      logger.Logger
+}
+
+// NewRefreshTokenService creates a new RefreshTokenService instance.
+func NewRefreshTokenService(
+	store RefreshTokenStore,
+	idGen snowflake.IDGenerator,
+	cfg *config.Config,
+	log logger.Logger,
+) *RefreshTokenService {
+	return &RefreshTokenService{
+		store:    store,
+		idGen:    idGen,
+		duration: cfg.RefreshToken.Duration,
+		log:      log.New("RefreshTokenService"),
+	}
+}
+
+// Issue issues a refresh token starting a new family
+func (s *RefreshTokenService) Issue(ctx context.Context, userID types.UserID) (types.Token, error) {
+	ctx, span := tracing.Start(ctx, "RefreshTokenService.Issue")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("userID", userID).Msg("Issue")
+
+	token, next, err := s.next()
+	if err != nil {
+		return "", err
+	}
+	next.FamilyID = next.ID
+	next.UserID = userID
+
+	if _, err = s.store.CreateRefreshToken(ctx, next); err != nil {
+		s.log.Err(err).Ctx(ctx).Msg("CreateRefreshToken")
+		return "", apperr.ErrInternalServerError
+	}
+
+	return token, nil
+}
+
+// Rotate exchanges a live refresh token for a new one of the same family.
+// Presenting an already rotated token means it was stolen, so the whole family is revoked.
+func (s *RefreshTokenService) Rotate(ctx context.Context, token types.Token) (types.UserID, types.Token, error) {
+	ctx, span := tracing.Start(ctx, "RefreshTokenService.Rotate")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Msg("Rotate")
+
+	hash := hashToken(token)
+
+	newToken, next, err := s.next()
+	if err != nil {
+		return 0, "", err
+	}
+
+	rotated, err := s.store.RotateRefreshToken(ctx, hash, next)
+	if err == nil {
+		return rotated.UserID, newToken, nil
+	}
+	if !errors.Is(err, apperr.ErrNotFound) {
+		s.log.Err(err).Ctx(ctx).Msg("RotateRefreshToken")
+		return 0, "", apperr.ErrInternalServerError
+	}
+
+	s.detectReuse(ctx, hash)
+
+	return 0, "", apperr.ErrInvalidToken
+}
+
+// Revoke revokes the family of the refresh token
+func (s *RefreshTokenService) Revoke(ctx context.Context, token types.Token) error {
+	ctx, span := tracing.Start(ctx, "RefreshTokenService.Revoke")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Msg("Revoke")
+
+	if err := s.store.RevokeRefreshTokenFamilyByHash(ctx, hashToken(token)); err != nil {
+		s.log.Err(err).Ctx(ctx).Msg("RevokeRefreshTokenFamilyByHash")
+		return apperr.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// RevokeAll revokes all refresh tokens of user
+func (s *RefreshTokenService) RevokeAll(ctx context.Context, userID types.UserID) error {
+	ctx, span := tracing.Start(ctx, "RefreshTokenService.RevokeAll")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("userID", userID).Msg("RevokeAll")
+
+	if err := s.store.RevokeRefreshTokens(ctx, userID); err != nil {
+		s.log.Err(err).Ctx(ctx).Msg("RevokeRefreshTokens")
+		return apperr.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (s *RefreshTokenService) detectReuse(ctx context.Context, hash string) {
+	old, err := s.store.GetRefreshToken(ctx, hash)
+	if err != nil || old.UsedAt == nil || old.RevokedAt != nil {
+		return
+	}
+
+	s.log.Wrn().Ctx(ctx).Values("familyID", old.FamilyID, "userID", old.UserID).Msg("refresh token reuse detected")
+	if err = s.store.RevokeRefreshTokenFamily(ctx, old.FamilyID); err != nil {
+		s.log.Err(err).Ctx(ctx).Msg("RevokeRefreshTokenFamily")
+	}
+}
+
+// next generates a new opaque token and its record without family and user
+func (s *RefreshTokenService) next() (types.Token, *model.RefreshToken, error) {
+	b := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		s.log.Err(err).Msg("failed to generate refresh token")
+		return "", nil, apperr.ErrInternalServerError
+	}
+	token := types.Token(base64.RawURLEncoding.EncodeToString(b))
+
+	return token, &model.RefreshToken{
+		ID:        types.ID(s.idGen.Generate()),
+		Hash:      hashToken(token),
+		ExpiresAt: time.Now().Add(s.duration),
+	}, nil
+}
+
+// hashToken returns the hash opaque tokens are stored by
+func hashToken(token types.Token) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
+)
+
+// SearchReader is an interface for full-text search reader
+//
+//go:generate mockgen -destination=../../../test/mock/service/mock-search-reader.go -package=mock . SearchReader
+type SearchReader interface {
+	Search(ctx context.Context, query string, limit int) ([]model.SearchHit, error)
+}
+
+// SearchService is a service for full-text search
+type SearchService struct {
+	reader SearchReader
+	log    logger.Logger
+}
+
+// NewSearchService creates new search service
+func NewSearchService(reader SearchReader, log logger.Logger) *SearchService {
+	return &SearchService{
+		reader: reader,
+		log:    log.New("SearchService"),
+	}
+}
+
+// Search returns books and authors matching the query
+func (s *SearchService) Search(ctx context.Context, query string, limit int) ([]model.SearchHit, error) {
+	ctx, span := tracing.Start(ctx, "SearchService.Search")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("query", query, "limit", limit).Msg("Search")
+
+	return s.reader.Search(ctx, query, limit)
+}
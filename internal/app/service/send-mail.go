@@ -1,26 +1,26 @@
 package service
 
 import (
+	"context"
 	"github.com/vlaship/book-catalog-go/internal/app/types"
-	"github.com/vlaship/book-catalog-go/internal/apperr"
 	"github.com/vlaship/book-catalog-go/internal/config"
 	"github.com/vlaship/book-catalog-go/internal/email"
 	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/metrics"
 	"github.com/vlaship/book-catalog-go/internal/template"
 	"github.com/vlaship/go-mask"
+	"time"
 )
 
-const (
-	subjActivationMail    = "Subject: Activate Your Account\n"
-	subjResetPasswordMail = "Subject: Reset Password\n" //nolint:gosec // Subject line is safe
-)
-
-// SendMailService is an interface for send mail service
+// SendMailService is an interface for send mail service, it delivers mails synchronously
 type SendMailService struct {
 	sender            email.Sender
 	templates         template.Templates
 	userActivationURL string
 	resetPasswordURL  string
+	changeEmailURL    string
+	ttls              map[types.OTPPurpose]time.Duration
+	metrics           metrics.Recorder
 	log               logger.Logger
 }
 
@@ -29,51 +29,139 @@ func NewSendMailService(
 	sender email.Sender,
 	templates template.Templates,
 	cfg *config.Config,
+	recorder metrics.Recorder,
 	log logger.Logger,
 ) *SendMailService {
 	return &SendMailService{
 		sender:            sender,
 		templates:         templates,
 		userActivationURL: cfg.Domain + "/auth/activate",
-		log:               log.New("SendMailService"),
+		changeEmailURL:    cfg.Domain + "/user/email/confirm",
+		ttls: map[types.OTPPurpose]time.Duration{
+			types.OTPPurposeActivation:    cfg.Cache.Activate,
+			types.OTPPurposeResetPassword: cfg.Cache.ResetPass,
+			types.OTPPurposeChangeEmail:   cfg.Cache.ChangeEmail,
+		},
+		metrics: recorder,
+		log:     log.New("SendMailService"),
 	}
 }
 
 type tmpl struct {
-	URL string
-	OTP string
+	URL      string
+	OTP      string
+	Username string
+	Until    string
+	IP       string
+	// Expires is the ttl of the otp
+	Expires time.Duration
 }
 
-// SendActivationMail sends activation mail
-func (s *SendMailService) SendActivationMail(to types.Username, otp types.Token) error {
-	s.log.Dbg().Values("to", mask.String(string(to))).Msg("SendActivationMail")
+// SendActivationMail sends activation mail in the preferred language of the user
+func (s *SendMailService) SendActivationMail(ctx context.Context, to types.Username, lang string, otp types.Token) error {
+	s.log.Dbg().Ctx(ctx).Values("to", mask.String(string(to)), "lang", lang).Msg("SendActivationMail")
 
 	t := tmpl{
-		URL: s.userActivationURL,
-		OTP: string(otp),
+		URL:      s.userActivationURL,
+		OTP:      string(otp),
+		Username: string(to),
+		Expires:  s.ttls[types.OTPPurposeActivation],
 	}
-	err := s.sender.Send([]string{string(to)}, subjActivationMail, s.templates.Activation(), t)
-	if err != nil {
-		s.log.Err(err).Msg("SendActivationMail")
-		return apperr.ErrSendMail
+	if err := s.send(ctx, to, s.templates.Activation(lang), t); err != nil {
+		s.log.Err(err).Ctx(ctx).Msg("SendActivationMail")
+		return err
+	}
+
+	return nil
+}
+
+// SendResetPasswordMail sends reset password mail in the preferred language of the user
+func (s *SendMailService) SendResetPasswordMail(
+	ctx context.Context,
+	to types.Username,
+	lang string,
+	otp types.Token,
+) error {
+	s.log.Dbg().Ctx(ctx).Values("to", mask.String(string(to)), "lang", lang).Msg("SendResetPasswordMail")
+
+	t := tmpl{
+		URL:      s.resetPasswordURL,
+		OTP:      string(otp),
+		Username: string(to),
+		Expires:  s.ttls[types.OTPPurposeResetPassword],
+	}
+	if err := s.send(ctx, to, s.templates.ResetPassword(lang), t); err != nil {
+		s.log.Err(err).Ctx(ctx).Msg("SendResetPasswordMail")
+		return err
 	}
 
 	return nil
 }
 
-// SendResetPasswordMail sends reset password mail
-func (s *SendMailService) SendResetPasswordMail(to types.Username, otp types.Token) error {
-	s.log.Dbg().Values("to", mask.String(string(to))).Msg("SendResetPasswordMail")
+// SendChangeEmailMail sends change email mail to the new email in the preferred language of the user
+func (s *SendMailService) SendChangeEmailMail(
+	ctx context.Context,
+	to types.Username,
+	lang string,
+	otp types.Token,
+) error {
+	s.log.Dbg().Ctx(ctx).Values("to", mask.String(string(to)), "lang", lang).Msg("SendChangeEmailMail")
 
 	t := tmpl{
-		URL: s.resetPasswordURL,
-		OTP: string(otp),
+		URL:      s.changeEmailURL,
+		OTP:      string(otp),
+		Username: string(to),
+		Expires:  s.ttls[types.OTPPurposeChangeEmail],
 	}
-	err := s.sender.Send([]string{string(to)}, subjResetPasswordMail, s.templates.ResetPassword(), t)
+	if err := s.send(ctx, to, s.templates.ChangeEmail(lang), t); err != nil {
+		s.log.Err(err).Ctx(ctx).Msg("SendChangeEmailMail")
+		return err
+	}
+
+	return nil
+}
+
+// SendUserLockedMail sends security notification of the user being locked in the preferred language of the user
+func (s *SendMailService) SendUserLockedMail(
+	ctx context.Context,
+	to types.Username,
+	lang string,
+	until time.Time,
+	ip string,
+) error {
+	s.log.Dbg().Ctx(ctx).Values("to", mask.String(string(to)), "lang", lang).Msg("SendUserLockedMail")
+
+	t := tmpl{
+		Username: string(to),
+		Until:    until.UTC().Format("2006-01-02 15:04 MST"),
+		IP:       ip,
+	}
+	if err := s.send(ctx, to, s.templates.UserLocked(lang), t); err != nil {
+		s.log.Err(err).Ctx(ctx).Msg("SendUserLockedMail")
+		return err
+	}
+
+	return nil
+}
+
+func (s *SendMailService) send(ctx context.Context, to types.Username, mail *template.Mail, t tmpl) error {
+	content, err := mail.Render(t)
 	if err != nil {
-		s.log.Err(err).Msg("SendResetPasswordMail")
-		return apperr.ErrSendMail
+		s.metrics.Event(metrics.EventMailFailed)
+		return err
+	}
+
+	msg := email.Message{
+		To:      []string{string(to)},
+		Subject: content.Subject,
+		Text:    content.Text,
+		HTML:    content.HTML,
+	}
+	if err = s.sender.Send(ctx, &msg); err != nil {
+		s.metrics.Event(metrics.EventMailFailed)
+		return err
 	}
+	s.metrics.Event(metrics.EventMailSent)
 
 	return nil
 }
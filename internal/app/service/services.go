@@ -10,4 +10,12 @@ type Services struct {
 	OTPService      *OTPService
 	PasswordService *PasswordService
 	TosService      *TosService
+	SearchService   *SearchService
+
+	RefreshTokenService *RefreshTokenService
+	AuditService        *AuditService
+	TrashService        *TrashService
+	ImportService       *ImportService
+	OutboxService       *OutboxService
+	TwoFactorService    *TwoFactorService
 }
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"github.com/vlaship/book-catalog-go/internal/apperr"
+	"github.com/vlaship/book-catalog-go/internal/cache"
+	"github.com/vlaship/book-catalog-go/internal/config"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/metrics"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
+	"github.com/vlaship/go-mask"
+	"math"
+	"strings"
+	"time"
+)
+
+// UserLocker is an interface for temporary locking of users
+//
+//go:generate mockgen -destination=../../../test/mock/service/mock-user-locker.go -package=mock . UserLocker
+type UserLocker interface {
+	LockUser(ctx context.Context, userID types.UserID, until time.Time) error
+}
+
+// LockMailer interface
+//
+//go:generate mockgen -destination=../../../test/mock/service/mock-lock-mailer.go -package=mock . LockMailer
+type LockMailer interface {
+	SendUserLockedMail(ctx context.Context, to types.Username, lang string, until time.Time, ip string) error
+}
+
+// SigninGuardService protects signin against brute-force.
+// Failed signins are counted per username and per IP within the window, past the free attempts every
+// failure delays the next attempt of the username or the IP, the delay doubles up to the max delay.
+// A username reaching the lock attempts is locked for the lock duration and the user is notified by mail,
+// the lock is kept for unknown usernames too, so the responses do not tell whether the user exists.
+type SigninGuardService struct {
+	cacher         cache.Cache
+	locker         UserLocker
+	mailer         LockMailer
+	tx             Transactor
+	metrics        metrics.Recorder
+	window         time.Duration
+	freeAttempts   int64
+	ipFreeAttempts int64
+	delay          time.Duration
+	maxDelay       time.Duration
+	lockAttempts   int64
+	lockDuration   time.Duration
+	log            logger.Logger
+}
+
+// NewSigninGuardService creates a new SigninGuardService instance.
+func NewSigninGuardService(
+	cfg *config.Config,
+	cacher cache.Cache,
+	locker UserLocker,
+	mailer LockMailer,
+	tx Transactor,
+	recorder metrics.Recorder,
+	log logger.Logger,
+) *SigninGuardService {
+	return &SigninGuardService{
+		cacher:         cacher,
+		locker:         locker,
+		mailer:         mailer,
+		tx:             tx,
+		metrics:        recorder,
+		window:         cfg.Signin.Window,
+		freeAttempts:   cfg.Signin.FreeAttempts,
+		ipFreeAttempts: cfg.Signin.IPFreeAttempts,
+		delay:          cfg.Signin.Delay,
+		maxDelay:       cfg.Signin.MaxDelay,
+		lockAttempts:   cfg.Signin.LockAttempts,
+		lockDuration:   cfg.Signin.LockDuration,
+		log:            log.New("SigninGuardService"),
+	}
+}
+
+func signinFailuresKey(subject string) string {
+	return "signin-failures:" + subject
+}
+
+func signinDelayKey(subject string) string {
+	return "signin-delay:" + subject
+}
+
+func signinLockedKey(subject string) string {
+	return "signin-locked:" + subject
+}
+
+func signinUserSubject(username types.Username) string {
+	return "user:" + strings.ToLower(string(username))
+}
+
+func signinIPSubject(ip string) string {
+	return "ip:" + ip
+}
+
+// Check fails while the username is locked or the next attempt of the username or of the IP is delayed
+func (s *SigninGuardService) Check(ctx context.Context, username types.Username, ip string) error {
+	ctx, span := tracing.Start(ctx, "SigninGuardService.Check")
+	defer span.End()
+
+	s.log.Trc().Ctx(ctx).Values("username", mask.String(string(username)), "ip", ip).Msg("Check")
+
+	var locked int64
+	ok, err := s.cacher.Get(ctx, signinLockedKey(signinUserSubject(username)), &locked)
+	if err != nil {
+		return err
+	}
+	if until := time.Unix(0, locked); ok && time.Now().Before(until) {
+		return lockedError(until)
+	}
+
+	subjects := []string{signinUserSubject(username)}
+	if ip != "" {
+		subjects = append(subjects, signinIPSubject(ip))
+	}
+
+	for _, subject := range subjects {
+		var until int64
+		ok, err := s.cacher.Get(ctx, signinDelayKey(subject), &until)
+		if err != nil {
+			return err
+		}
+		if wait := time.Until(time.Unix(0, until)); ok && wait > 0 {
+			return apperr.ErrTooManyAttempts.WithFunc(apperr.WithDetail(fmt.Sprintf(
+				"too many failed signin attempts, try again in %d seconds", int64(math.Ceil(wait.Seconds())),
+			)))
+		}
+	}
+
+	return nil
+}
+
+// Fail counts the failed signin and returns the error to respond with,
+// user is nil when the username is unknown, unknown usernames are locked as well
+func (s *SigninGuardService) Fail(ctx context.Context, username types.Username, ip string, user *model.User) error {
+	ctx, span := tracing.Start(ctx, "SigninGuardService.Fail")
+	defer span.End()
+
+	s.log.Trc().Ctx(ctx).Values("username", mask.String(string(username)), "ip", ip).Msg("Fail")
+
+	subject := signinUserSubject(username)
+	attempts, err := s.count(ctx, subject, s.freeAttempts)
+	if err != nil {
+		return err
+	}
+	if ip != "" {
+		if _, err = s.count(ctx, signinIPSubject(ip), s.ipFreeAttempts); err != nil {
+			return err
+		}
+	}
+
+	if s.lockAttempts <= 0 || attempts < s.lockAttempts {
+		return apperr.ErrUnauthorized
+	}
+
+	// the failures of the username start over once it is locked
+	if err = s.cacher.Del(ctx, signinFailuresKey(subject), signinDelayKey(subject)); err != nil {
+		return err
+	}
+
+	until := time.Now().Add(s.lockDuration)
+	if err = s.cacher.Put(ctx, signinLockedKey(subject), until.UnixNano(), s.lockDuration); err != nil {
+		return err
+	}
+	if user != nil {
+		if err = s.lock(ctx, user, until, ip); err != nil {
+			s.log.Err(err).Ctx(ctx).Values("userID", user.ID).Msg("failed to lock user")
+			return apperr.ErrInternalServerError
+		}
+	}
+
+	return lockedError(until)
+}
+
+// Succeed forgets the failed signins of the username, the failures of the IP are kept
+func (s *SigninGuardService) Succeed(ctx context.Context, username types.Username) error {
+	ctx, span := tracing.Start(ctx, "SigninGuardService.Succeed")
+	defer span.End()
+
+	s.log.Trc().Ctx(ctx).Values("username", mask.String(string(username))).Msg("Succeed")
+
+	subject := signinUserSubject(username)
+
+	return s.cacher.Del(ctx, signinFailuresKey(subject), signinDelayKey(subject))
+}
+
+// Unlock forgets the lock and the failed signins of the username
+func (s *SigninGuardService) Unlock(ctx context.Context, username types.Username) error {
+	ctx, span := tracing.Start(ctx, "SigninGuardService.Unlock")
+	defer span.End()
+
+	s.log.Trc().Ctx(ctx).Values("username", mask.String(string(username))).Msg("Unlock")
+
+	subject := signinUserSubject(username)
+
+	return s.cacher.Del(ctx, signinLockedKey(subject), signinFailuresKey(subject), signinDelayKey(subject))
+}
+
+// count counts the failure of the subject and delays its next attempt past the free attempts
+func (s *SigninGuardService) count(ctx context.Context, subject string, free int64) (int64, error) {
+	attempts, err := s.cacher.Incr(ctx, signinFailuresKey(subject), s.window)
+	if err != nil {
+		return 0, err
+	}
+	if attempts <= free {
+		return attempts, nil
+	}
+
+	delay := backoff(s.delay, s.maxDelay, int(attempts-free))
+	if delay <= 0 {
+		return attempts, nil
+	}
+	until := time.Now().Add(delay).UnixNano()
+	if err = s.cacher.Put(ctx, signinDelayKey(subject), until, delay); err != nil {
+		return 0, err
+	}
+
+	return attempts, nil
+}
+
+// lock locks the user and queues the security notification in one transaction
+func (s *SigninGuardService) lock(ctx context.Context, user *model.User, until time.Time, ip string) error {
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.locker.LockUser(ctx, user.ID, until); err != nil {
+			return err
+		}
+
+		return s.mailer.SendUserLockedMail(ctx, user.Username, user.Data.Language, until, ip)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.log.Wrn().Ctx(ctx).Values("userID", user.ID, "until", until, "ip", ip).Msg("user locked after failed signins")
+	s.metrics.Event(metrics.EventUserLocked)
+
+	return nil
+}
+
+// lockedError returns the error of a user locked until the time
+func lockedError(until time.Time) error {
+	return apperr.ErrUserTemporarilyLocked.WithFunc(apperr.WithDetail(fmt.Sprintf(
+		"user is temporarily locked after too many failed signin attempts until %s", until.UTC().Format(time.RFC3339),
+	)))
+}
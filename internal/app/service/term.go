@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/vlaship/book-catalog-go/internal/app/model"
 	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
 )
 
 // TosReader is an interface for term of service reader
@@ -29,6 +30,9 @@ func NewTosService(reader TosReader, log logger.Logger) *TosService {
 
 // GetTos get term of service
 func (s *TosService) GetTos(ctx context.Context) (*model.TermOfService, error) {
+	ctx, span := tracing.Start(ctx, "TosService.GetTos")
+	defer span.End()
+
 	s.log.Trc().Ctx(ctx).Msg("GetTos")
 
 	return s.reader.GetTos(ctx)
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"github.com/vlaship/book-catalog-go/internal/apperr"
+	"github.com/vlaship/book-catalog-go/internal/config"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
+	"time"
+)
+
+// BookTrash is an interface for soft deleted books storage
+//
+//go:generate mockgen -destination=../../../test/mock/service/mock-book-trash.go -package=mock . BookTrash
+type BookTrash interface {
+	GetTrashedBooks(ctx context.Context, filter model.TrashFilter) ([]model.Book, error)
+	LockTrashedBook(ctx context.Context, bookID types.ID) (*model.Book, error)
+	RestoreBook(ctx context.Context, bookID types.ID) error
+	PurgeBook(ctx context.Context, bookID types.ID) error
+	PurgeAuthorBooks(ctx context.Context, authorID types.ID) ([]model.Book, error)
+	PurgeExpiredBooks(ctx context.Context, before time.Time) ([]model.Book, error)
+}
+
+// AuthorTrash is an interface for soft deleted authors storage
+//
+//go:generate mockgen -destination=../../../test/mock/service/mock-author-trash.go -package=mock . AuthorTrash
+type AuthorTrash interface {
+	GetTrashedAuthors(ctx context.Context, filter model.TrashFilter) ([]model.Author, error)
+	LockTrashedAuthor(ctx context.Context, authorID types.ID) (*model.Author, error)
+	RestoreAuthor(ctx context.Context, authorID types.ID) error
+	PurgeAuthor(ctx context.Context, authorID types.ID) error
+	PurgeExpiredAuthors(ctx context.Context, before time.Time) ([]model.Author, error)
+}
+
+// TrashService is a service for listing, restoring and purging soft deleted books and authors
+type TrashService struct {
+	books     BookTrash
+	authors   AuthorTrash
+	bookRead  BookReader
+	authRead  AuthorReader
+	tx        Transactor
+	audit     AuditRecorder
+	retention time.Duration
+	interval  time.Duration
+	log       logger.Logger
+}
+
+// NewTrashService creates new trash service
+func NewTrashService(
+	cfg *config.Config,
+	books BookTrash,
+	authors AuthorTrash,
+	bookRead BookReader,
+	authRead AuthorReader,
+	tx Transactor,
+	audit AuditRecorder,
+	log logger.Logger,
+) *TrashService {
+	return &TrashService{
+		books:     books,
+		authors:   authors,
+		bookRead:  bookRead,
+		authRead:  authRead,
+		tx:        tx,
+		audit:     audit,
+		retention: cfg.Trash.Retention,
+		interval:  cfg.Trash.PurgeInterval,
+		log:       log.New("TrashService"),
+	}
+}
+
+// GetTrashedBooks returns page of soft deleted books
+func (s *TrashService) GetTrashedBooks(ctx context.Context, filter model.TrashFilter) (*model.Page[model.Book], error) {
+	ctx, span := tracing.Start(ctx, "TrashService.GetTrashedBooks")
+	defer span.End()
+
+	s.log.Trc().Ctx(ctx).Values("filter", filter).Msg("GetTrashedBooks")
+
+	limit := filter.Limit
+	filter.Limit++ // fetch one more to know if there is a next page
+
+	books, err := s.books.GetTrashedBooks(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewPage(books, limit, model.BookTrashCursor), nil
+}
+
+// RestoreBook restores soft deleted book, the author of the book must not be deleted
+// and no other book may have taken its ISBN
+func (s *TrashService) RestoreBook(ctx context.Context, bookID types.ID) error {
+	ctx, span := tracing.Start(ctx, "TrashService.RestoreBook")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("bookID", bookID).Msg("RestoreBook")
+
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		book, err := s.books.LockTrashedBook(ctx, bookID)
+		if err != nil {
+			return err
+		}
+
+		if _, err = s.authRead.GetAuthor(ctx, book.AuthorID); err != nil {
+			if errors.Is(err, apperr.ErrNotFound) {
+				return apperr.ErrConflict.WithFunc(apperr.WithDetail("author of the book is deleted, restore it first"))
+			}
+			return err
+		}
+
+		if err = s.books.RestoreBook(ctx, bookID); err != nil {
+			return duplicateISBN(err, book.ISBN)
+		}
+
+		return s.audit.Record(ctx, model.AuditActionRestore, model.AuditEntityBook, bookID, nil, auditBook(book))
+	})
+}
+
+// PurgeBook permanently deletes soft deleted book
+func (s *TrashService) PurgeBook(ctx context.Context, bookID types.ID) error {
+	ctx, span := tracing.Start(ctx, "TrashService.PurgeBook")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("bookID", bookID).Msg("PurgeBook")
+
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		book, err := s.books.LockTrashedBook(ctx, bookID)
+		if err != nil {
+			return err
+		}
+
+		if err = s.books.PurgeBook(ctx, bookID); err != nil {
+			return err
+		}
+
+		return s.audit.Record(ctx, model.AuditActionPurge, model.AuditEntityBook, bookID, auditBook(book), nil)
+	})
+}
+
+// GetTrashedAuthors returns page of soft deleted authors
+func (s *TrashService) GetTrashedAuthors(ctx context.Context, filter model.TrashFilter) (*model.Page[model.Author], error) {
+	ctx, span := tracing.Start(ctx, "TrashService.GetTrashedAuthors")
+	defer span.End()
+
+	s.log.Trc().Ctx(ctx).Values("filter", filter).Msg("GetTrashedAuthors")
+
+	limit := filter.Limit
+	filter.Limit++ // fetch one more to know if there is a next page
+
+	authors, err := s.authors.GetTrashedAuthors(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewPage(authors, limit, model.AuthorTrashCursor), nil
+}
+
+// RestoreAuthor restores soft deleted author, the books of the author stay in the trash
+func (s *TrashService) RestoreAuthor(ctx context.Context, authorID types.ID) error {
+	ctx, span := tracing.Start(ctx, "TrashService.RestoreAuthor")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("authorID", authorID).Msg("RestoreAuthor")
+
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		author, err := s.authors.LockTrashedAuthor(ctx, authorID)
+		if err != nil {
+			return err
+		}
+
+		if err = s.authors.RestoreAuthor(ctx, authorID); err != nil {
+			return err
+		}
+
+		return s.audit.Record(ctx, model.AuditActionRestore, model.AuditEntityAuthor, authorID, nil, auditAuthor(author))
+	})
+}
+
+// PurgeAuthor permanently deletes soft deleted author together with the deleted books of the author,
+// it fails with conflict while the author has books that are not deleted
+func (s *TrashService) PurgeAuthor(ctx context.Context, authorID types.ID) error {
+	ctx, span := tracing.Start(ctx, "TrashService.PurgeAuthor")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("authorID", authorID).Msg("PurgeAuthor")
+
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		author, err := s.authors.LockTrashedAuthor(ctx, authorID)
+		if err != nil {
+			return err
+		}
+
+		if err = checkNoBooks(ctx, s.bookRead, authorID); err != nil {
+			return err
+		}
+
+		books, err := s.books.PurgeAuthorBooks(ctx, authorID)
+		if err != nil {
+			return err
+		}
+		for i := range books {
+			book := &books[i]
+			if err = s.audit.Record(ctx, model.AuditActionPurge, model.AuditEntityBook, book.ID, auditBook(book), nil); err != nil {
+				return err
+			}
+		}
+
+		if err = s.authors.PurgeAuthor(ctx, authorID); err != nil {
+			return err
+		}
+
+		return s.audit.Record(ctx, model.AuditActionPurge, model.AuditEntityAuthor, authorID, auditAuthor(author), nil)
+	})
+}
+
+// PurgeExpired permanently deletes books and authors soft deleted before the time,
+// authors that still have books are kept until their books are purged
+func (s *TrashService) PurgeExpired(ctx context.Context, before time.Time) (int, error) {
+	ctx, span := tracing.Start(ctx, "TrashService.PurgeExpired")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("before", before).Msg("PurgeExpired")
+
+	var purged int
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		books, err := s.books.PurgeExpiredBooks(ctx, before)
+		if err != nil {
+			return err
+		}
+		for i := range books {
+			book := &books[i]
+			if err = s.audit.Record(ctx, model.AuditActionPurge, model.AuditEntityBook, book.ID, auditBook(book), nil); err != nil {
+				return err
+			}
+		}
+
+		authors, err := s.authors.PurgeExpiredAuthors(ctx, before)
+		if err != nil {
+			return err
+		}
+		for i := range authors {
+			author := &authors[i]
+			if err = s.audit.Record(ctx, model.AuditActionPurge, model.AuditEntityAuthor, author.ID, auditAuthor(author), nil); err != nil {
+				return err
+			}
+		}
+
+		purged = len(books) + len(authors)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+// RunRetention purges expired rows every purge interval until ctx is done, it returns at once
+// when the retention is disabled
+func (s *TrashService) RunRetention(ctx context.Context) {
+	if s.retention <= 0 || s.interval <= 0 {
+		s.log.Inf().Msg("trash retention is disabled")
+		return
+	}
+
+	s.log.Inf().Values("retention", s.retention, "interval", s.interval).Msg("trash retention is started")
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := s.PurgeExpired(ctx, time.Now().Add(-s.retention))
+		if err != nil {
+			s.log.Err(err).Ctx(ctx).Msg("failed to purge expired trash")
+		} else if purged > 0 {
+			s.log.Inf().Ctx(ctx).Values("purged", purged).Msg("expired trash purged")
+		}
+
+		select {
+		case <-ctx.Done():
+			s.log.Inf().Msg("trash retention is stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkNoBooks fails with conflict when the author has books that are not deleted
+func checkNoBooks(ctx context.Context, reader BookReader, authorID types.ID) error {
+	books, err := reader.GetBooks(ctx, model.BookFilter{AuthorID: &authorID, Limit: 1, Sort: model.BookSortID})
+	if err != nil {
+		return err
+	}
+	if len(books) > 0 {
+		return apperr.ErrConflict.WithFunc(apperr.WithDetail("author has books, delete them first"))
+	}
+
+	return nil
+}
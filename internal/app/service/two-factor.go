@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"github.com/vlaship/book-catalog-go/internal/app/common"
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"github.com/vlaship/book-catalog-go/internal/apperr"
+	"github.com/vlaship/book-catalog-go/internal/cache"
+	"github.com/vlaship/book-catalog-go/internal/config"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/totp"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
+	"time"
+)
+
+const (
+	challengeTokenBytes = 32
+	// totpSkew accepts the codes of the previous and the next time step to tolerate clock drift
+	totpSkew = 1
+)
+
+// TwoFactorStore is an interface for two-factor authentication storage
+//
+//go:generate mockgen -destination=../../../test/mock/service/mock-two-factor-store.go -package=mock . TwoFactorStore
+type TwoFactorStore interface {
+	GetTwoFactor(ctx context.Context, userID types.UserID) (*model.TwoFactor, error)
+	SetTwoFactor(ctx context.Context, userID types.UserID, secret []byte, recoveryCodes []string) error
+	EnableTwoFactor(ctx context.Context, userID types.UserID, step int64) error
+	DisableTwoFactor(ctx context.Context, userID types.UserID) error
+	UseTwoFactorStep(ctx context.Context, userID types.UserID, step int64) error
+	UseRecoveryCode(ctx context.Context, userID types.UserID, hash string) error
+}
+
+// TwoFactorService is a service for TOTP two-factor authentication.
+// Users with two-factor authentication get a challenge token on signin, the challenge is exchanged
+// for the access token with a code of the authenticator app or a recovery code. A challenge is
+// single use, and it is dropped once the max attempts of wrong codes is reached.
+type TwoFactorService struct {
+	store         TwoFactorStore
+	reader        UserReader
+	pass          PasswordHandler
+	cipher        totp.Cipher
+	cacher        cache.Cache
+	issuer        string
+	challengeTTL  time.Duration
+	recoveryCodes int
+	maxAttempts   int64
+	log           logger.Logger
+}
+
+// NewTwoFactorService creates a new TwoFactorService instance.
+func NewTwoFactorService(
+	cfg *config.Config,
+	store TwoFactorStore,
+	reader UserReader,
+	pass PasswordHandler,
+	cipher totp.Cipher,
+	cacher cache.Cache,
+	log logger.Logger,
+) *TwoFactorService {
+	return &TwoFactorService{
+		store:         store,
+		reader:        reader,
+		pass:          pass,
+		cipher:        cipher,
+		cacher:        cacher,
+		issuer:        cfg.TOTP.Issuer,
+		challengeTTL:  cfg.TOTP.ChallengeTTL,
+		recoveryCodes: cfg.TOTP.RecoveryCodes,
+		maxAttempts:   cfg.OTP.MaxAttempts,
+		log:           log.New("TwoFactorService"),
+	}
+}
+
+// challengeRecord is the pending second step of a signin
+type challengeRecord struct {
+	UserID types.UserID `json:"user_id"`
+}
+
+func challengeKey(token types.Token) string {
+	return "2fa-challenge:" + hashToken(token)
+}
+
+func challengeAttemptsKey(token types.Token) string {
+	return "2fa-challenge-attempts:" + hashToken(token)
+}
+
+// Enroll starts the enrollment of the caller, the secret and the recovery codes are returned once,
+// two-factor authentication is enabled when the enrollment is confirmed with a code
+func (s *TwoFactorService) Enroll(ctx context.Context) (*model.TwoFactorEnrollment, error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.Enroll")
+	defer span.End()
+
+	user := common.GetUser(ctx)
+	s.log.Dbg().Ctx(ctx).Values("userID", user.ID).Msg("Enroll")
+
+	if user.TwoFactor {
+		return nil, apperr.ErrConflict.WithFunc(apperr.WithDetail("two-factor authentication is already enabled"))
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		s.log.Err(err).Ctx(ctx).Msg("GenerateSecret")
+		return nil, apperr.ErrInternalServerError
+	}
+	sealed, err := s.cipher.Encrypt([]byte(secret))
+	if err != nil {
+		s.log.Err(err).Ctx(ctx).Msg("Encrypt")
+		return nil, apperr.ErrInternalServerError
+	}
+	codes, err := totp.GenerateRecoveryCodes(s.recoveryCodes)
+	if err != nil {
+		s.log.Err(err).Ctx(ctx).Msg("GenerateRecoveryCodes")
+		return nil, apperr.ErrInternalServerError
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = totp.HashRecoveryCode(code)
+	}
+
+	if err = s.store.SetTwoFactor(ctx, user.ID, sealed, hashes); err != nil {
+		return nil, err
+	}
+
+	return &model.TwoFactorEnrollment{
+		URI:           totp.URI(s.issuer, string(user.Username), secret),
+		Secret:        secret,
+		RecoveryCodes: codes,
+	}, nil
+}
+
+// Confirm enables two-factor authentication of the caller once the code matches the enrolled secret
+func (s *TwoFactorService) Confirm(ctx context.Context, code string) error {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.Confirm")
+	defer span.End()
+
+	userID := common.GetUser(ctx).ID
+	s.log.Dbg().Ctx(ctx).Values("userID", userID).Msg("Confirm")
+
+	tf, err := s.store.GetTwoFactor(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if tf.Enabled {
+		return apperr.ErrConflict.WithFunc(apperr.WithDetail("two-factor authentication is already enabled"))
+	}
+	if tf.Secret == nil {
+		return apperr.ErrConflict.WithFunc(apperr.WithDetail("two-factor authentication is not enrolled"))
+	}
+
+	step, ok, err := s.validateCode(ctx, tf, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return apperr.ErrInvalidOTP
+	}
+
+	return s.store.EnableTwoFactor(ctx, userID, step)
+}
+
+// Disable disables two-factor authentication of the caller after checking the password
+func (s *TwoFactorService) Disable(ctx context.Context, password types.Password) error {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.Disable")
+	defer span.End()
+
+	userID := common.GetUser(ctx).ID
+	s.log.Dbg().Ctx(ctx).Values("userID", userID).Msg("Disable")
+
+	user, err := s.reader.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err = s.pass.Validate(password, user.Password); err != nil {
+		s.log.Wrn().Err(err).Ctx(ctx).Msg("validatePassword")
+		return apperr.ErrInvalidPassword
+	}
+
+	return s.store.DisableTwoFactor(ctx, userID)
+}
+
+// Challenge issues the challenge token of the second signin step of user
+func (s *TwoFactorService) Challenge(ctx context.Context, userID types.UserID) (types.Token, error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.Challenge")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("userID", userID).Msg("Challenge")
+
+	b := make([]byte, challengeTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		s.log.Err(err).Ctx(ctx).Msg("failed to generate challenge token")
+		return "", apperr.ErrInternalServerError
+	}
+	token := types.Token(base64.RawURLEncoding.EncodeToString(b))
+
+	if err := s.cacher.Put(ctx, challengeKey(token), challengeRecord{UserID: userID}, s.challengeTTL); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// ChallengeUser returns the user of the challenge without consuming it
+func (s *TwoFactorService) ChallengeUser(ctx context.Context, token types.Token) (types.UserID, error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.ChallengeUser")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Msg("ChallengeUser")
+
+	var record challengeRecord
+	ok, err := s.cacher.Get(ctx, challengeKey(token), &record)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, apperr.ErrUnauthorized
+	}
+
+	return record.UserID, nil
+}
+
+// Verify consumes the challenge once the code of the authenticator app or a recovery code is accepted,
+// it returns the user of the challenge
+func (s *TwoFactorService) Verify(ctx context.Context, token types.Token, code string) (types.UserID, error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.Verify")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Msg("Verify")
+
+	var record challengeRecord
+	ok, err := s.cacher.Get(ctx, challengeKey(token), &record)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, apperr.ErrUnauthorized
+	}
+
+	tf, err := s.store.GetTwoFactor(ctx, record.UserID)
+	if err != nil {
+		if errors.Is(err, apperr.ErrNotFound) {
+			return 0, apperr.ErrUnauthorized
+		}
+		return 0, err
+	}
+	if !tf.Enabled {
+		return 0, apperr.ErrUnauthorized
+	}
+
+	if ok, err = s.accept(ctx, tf, code); err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, s.fail(ctx, token)
+	}
+
+	// consume the challenge, a concurrent verification may have taken it since
+	if ok, err = s.cacher.GetDel(ctx, challengeKey(token), &record); err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, apperr.ErrUnauthorized
+	}
+	if err = s.cacher.Del(ctx, challengeAttemptsKey(token)); err != nil {
+		return 0, err
+	}
+
+	return record.UserID, nil
+}
+
+// accept accepts a code of the authenticator app once, or consumes a recovery code
+func (s *TwoFactorService) accept(ctx context.Context, tf *model.TwoFactor, code string) (bool, error) {
+	if len(code) == totp.Digits {
+		step, ok, err := s.validateCode(ctx, tf, code)
+		if err != nil || !ok {
+			return false, err
+		}
+		return s.used(s.store.UseTwoFactorStep(ctx, tf.UserID, step))
+	}
+
+	return s.used(s.store.UseRecoveryCode(ctx, tf.UserID, totp.HashRecoveryCode(code)))
+}
+
+// used treats not found as the code being replayed or unknown
+func (s *TwoFactorService) used(err error) (bool, error) {
+	if errors.Is(err, apperr.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *TwoFactorService) validateCode(ctx context.Context, tf *model.TwoFactor, code string) (int64, bool, error) {
+	secret, err := s.cipher.Decrypt(tf.Secret)
+	if err != nil {
+		s.log.Err(err).Ctx(ctx).Values("userID", tf.UserID).Msg("Decrypt")
+		return 0, false, apperr.ErrInternalServerError
+	}
+
+	step, ok := totp.Validate(string(secret), code, time.Now(), totpSkew)
+
+	return step, ok, nil
+}
+
+// fail counts the wrong code, the challenge is dropped once the max attempts is reached
+func (s *TwoFactorService) fail(ctx context.Context, token types.Token) error {
+	if s.maxAttempts <= 0 {
+		return apperr.ErrInvalidOTP
+	}
+
+	attempts, err := s.cacher.Incr(ctx, challengeAttemptsKey(token), s.challengeTTL)
+	if err != nil {
+		return err
+	}
+	if attempts < s.maxAttempts {
+		return apperr.ErrInvalidOTP
+	}
+
+	s.log.Wrn().Ctx(ctx).Msg("two-factor challenge locked out")
+	if err = s.cacher.Del(ctx, challengeKey(token)); err != nil {
+		return err
+	}
+
+	return apperr.ErrTooManyAttempts
+}
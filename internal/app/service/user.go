@@ -2,11 +2,14 @@ package service
 
 import (
 	"context"
+	"errors"
 	"github.com/vlaship/book-catalog-go/internal/app/common"
 	"github.com/vlaship/book-catalog-go/internal/app/model"
 	"github.com/vlaship/book-catalog-go/internal/app/types"
 	"github.com/vlaship/book-catalog-go/internal/apperr"
 	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/metrics"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
 	"github.com/vlaship/go-mask"
 )
 
@@ -24,35 +27,88 @@ type UserReader interface {
 type UserWriter interface {
 	Create(ctx context.Context, user model.User) (*model.User, error)
 	UpdateInfo(ctx context.Context, user model.User, userID types.UserID) error
-	UpdateStatus(ctx context.Context, user model.User) error
+	Activate(ctx context.Context, username types.Username) error
 	UpdatePassword(ctx context.Context, user model.User) error
+	UpdateUsername(ctx context.Context, userID types.UserID, username types.Username) error
+	Anonymize(ctx context.Context, userID types.UserID) error
+}
+
+// UserAdminStore interface
+//
+//go:generate mockgen -destination=../../../test/mock/service/mock-user_admin_store.go -package=mock . UserAdminStore
+type UserAdminStore interface {
+	GetUsers(ctx context.Context, filter model.UserFilter) ([]model.User, error)
+	PatchUser(ctx context.Context, userID types.UserID, patch model.UserPatch) error
+	DeleteUser(ctx context.Context, userID types.UserID) error
+}
+
+// SessionRevoker interface
+//
+//go:generate mockgen -destination=../../../test/mock/service/mock-session-revoker.go -package=mock . SessionRevoker
+type SessionRevoker interface {
+	RevokeAll(ctx context.Context, userID types.UserID) error
+}
+
+// SigninUnlocker interface
+//
+//go:generate mockgen -destination=../../../test/mock/service/mock-signin-unlocker.go -package=mock . SigninUnlocker
+type SigninUnlocker interface {
+	Unlock(ctx context.Context, username types.Username) error
+}
+
+// OTPValidator interface
+//
+//go:generate mockgen -destination=../../../test/mock/service/mock-otp-validator.go -package=mock . OTPValidator
+type OTPValidator interface {
+	ValidateOTP(ctx context.Context, purpose types.OTPPurpose, username types.Username, otp types.Token) error
 }
 
 // UserService is a service for user.
 type UserService struct {
-	reader UserReader
-	writer UserWriter
-	pass   PasswordHandler
-	log    logger.Logger
+	reader   UserReader
+	writer   UserWriter
+	admin    UserAdminStore
+	sessions SessionRevoker
+	unlocker SigninUnlocker
+	tx       Transactor
+	pass     PasswordHandler
+	otp      OTPValidator
+	metrics  metrics.Recorder
+	log      logger.Logger
 }
 
 // NewUserService creates a new UserService instance.
 func NewUserService(
 	reader UserReader,
 	writer UserWriter,
+	admin UserAdminStore,
+	sessions SessionRevoker,
+	unlocker SigninUnlocker,
+	tx Transactor,
 	pass PasswordHandler,
+	otp OTPValidator,
+	recorder metrics.Recorder,
 	log logger.Logger,
 ) *UserService {
 	return &UserService{
-		reader: reader,
-		writer: writer,
-		pass:   pass,
-		log:    log.New("UserService"),
+		reader:   reader,
+		writer:   writer,
+		admin:    admin,
+		sessions: sessions,
+		unlocker: unlocker,
+		tx:       tx,
+		pass:     pass,
+		otp:      otp,
+		metrics:  recorder,
+		log:      log.New("UserService"),
 	}
 }
 
 // GetUserByID gets user by ID.
 func (s *UserService) GetUserByID(ctx context.Context, userID types.UserID) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUserByID")
+	defer span.End()
+
 	s.log.Dbg().Ctx(ctx).Values("userID", userID).Msg("GetUserByID")
 
 	return s.reader.GetUserByID(ctx, userID)
@@ -60,6 +116,9 @@ func (s *UserService) GetUserByID(ctx context.Context, userID types.UserID) (*mo
 
 // GetUserByUsername gets user by username.
 func (s *UserService) GetUserByUsername(ctx context.Context, username types.Username) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUserByUsername")
+	defer span.End()
+
 	s.log.Dbg().Ctx(ctx).Values("username", mask.String(string(username))).Msg("GetUserByUsername")
 
 	return s.reader.GetUserByUsername(ctx, username)
@@ -67,20 +126,38 @@ func (s *UserService) GetUserByUsername(ctx context.Context, username types.User
 
 // Activate activating user
 func (s *UserService) Activate(ctx context.Context, user model.User) error {
+	ctx, span := tracing.Start(ctx, "UserService.Activate")
+	defer span.End()
+
 	s.log.Dbg().Ctx(ctx).Values("username", mask.String(string(user.Username))).Msg("Activate")
 
-	err := s.writer.UpdateStatus(ctx, user)
+	err := s.writer.Activate(ctx, user.Username)
+	if errors.Is(err, apperr.ErrNotFound) {
+		return apperr.ErrConflict.WithFunc(apperr.WithDetail("user is not awaiting activation"))
+	}
 	if err != nil {
-		s.log.Wrn().Err(err).Ctx(ctx).Msg("UpdateStatus")
+		s.log.Wrn().Err(err).Ctx(ctx).Msg("Activate")
 		return apperr.ErrInternalServerError
 	}
+	s.metrics.Event(metrics.EventActivation)
 
 	return nil
 }
 
-// UpdatePassword replaces user password.
-func (s *UserService) UpdatePassword(ctx context.Context, user model.User) error {
-	s.log.Dbg().Ctx(ctx).Values("username", mask.String(string(user.Username))).Msg("UpdatePassword")
+// ReplacePassword replaces user password by the reset password otp, the password is checked
+// before the otp is consumed so a weak password can be retried with the same otp
+func (s *UserService) ReplacePassword(ctx context.Context, user model.User, otp types.Token) error {
+	ctx, span := tracing.Start(ctx, "UserService.ReplacePassword")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("username", mask.String(string(user.Username))).Msg("ReplacePassword")
+
+	if err := s.pass.Check(ctx, user.Password, user.Username); err != nil {
+		return err
+	}
+	if err := s.otp.ValidateOTP(ctx, types.OTPPurposeResetPassword, user.Username, otp); err != nil {
+		return err
+	}
 
 	hash, err := s.pass.Hash(user.Password)
 	if err != nil {
@@ -94,8 +171,175 @@ func (s *UserService) UpdatePassword(ctx context.Context, user model.User) error
 
 // UpdateInfo updates user info.
 func (s *UserService) UpdateInfo(ctx context.Context, user model.User) error {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateInfo")
+	defer span.End()
+
 	userID := common.GetUser(ctx).ID
 	s.log.Dbg().Ctx(ctx).Values("user", user, "userID", userID).Msg("UpdateInfo")
 
 	return s.writer.UpdateInfo(ctx, user, userID)
 }
+
+// ChangePassword replaces the password of the caller after checking the current one,
+// the caller is signed out of every session
+func (s *UserService) ChangePassword(ctx context.Context, current, password types.Password) error {
+	ctx, span := tracing.Start(ctx, "UserService.ChangePassword")
+	defer span.End()
+
+	caller := common.GetUser(ctx)
+	s.log.Dbg().Ctx(ctx).Values("userID", caller.ID).Msg("ChangePassword")
+
+	user, err := s.reader.GetUserByID(ctx, caller.ID)
+	if err != nil {
+		return err
+	}
+	if err = s.pass.Validate(current, user.Password); err != nil {
+		s.log.Wrn().Err(err).Ctx(ctx).Msg("validatePassword")
+		return apperr.ErrInvalidPassword
+	}
+	if err = s.pass.Check(ctx, password, user.Username); err != nil {
+		return err
+	}
+
+	hash, err := s.pass.Hash(password)
+	if err != nil {
+		s.log.Wrn().Err(err).Ctx(ctx).Msg("Hash")
+		return apperr.ErrInternalServerError
+	}
+	user.Password = hash
+
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.writer.UpdatePassword(ctx, *user); err != nil {
+			return err
+		}
+
+		return s.sessions.RevokeAll(ctx, user.ID)
+	})
+}
+
+// ChangeEmail replaces the username of the caller by the confirmed email
+func (s *UserService) ChangeEmail(ctx context.Context, email types.Username) error {
+	ctx, span := tracing.Start(ctx, "UserService.ChangeEmail")
+	defer span.End()
+
+	userID := common.GetUser(ctx).ID
+	s.log.Dbg().Ctx(ctx).Values("userID", userID, "email", mask.String(string(email))).Msg("ChangeEmail")
+
+	return s.writer.UpdateUsername(ctx, userID, email)
+}
+
+// DeleteAccount deletes the account of the caller, erases the personal data and signs the caller out
+func (s *UserService) DeleteAccount(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "UserService.DeleteAccount")
+	defer span.End()
+
+	userID := common.GetUser(ctx).ID
+	s.log.Dbg().Ctx(ctx).Values("userID", userID).Msg("DeleteAccount")
+
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.writer.Anonymize(ctx, userID); err != nil {
+			return err
+		}
+
+		return s.sessions.RevokeAll(ctx, userID)
+	})
+}
+
+// GetUsers returns page of users by filter
+func (s *UserService) GetUsers(ctx context.Context, filter model.UserFilter) (*model.Page[model.User], error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUsers")
+	defer span.End()
+
+	s.log.Trc().Ctx(ctx).Values("limit", filter.Limit).Msg("GetUsers")
+
+	limit := filter.Limit
+	filter.Limit++ // fetch one more to know if there is a next page
+
+	users, err := s.admin.GetUsers(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewPage(users, limit, model.UserCursor), nil
+}
+
+// UpdateUser changes the status, role or plan of the user on behalf of an admin.
+// Admins cannot change their own status or role, and a user that is no longer active is signed out.
+// A change of the status unlocks the user.
+func (s *UserService) UpdateUser(ctx context.Context, userID types.UserID, patch model.UserPatch) error {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateUser")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("userID", userID, "status", patch.Status, "role", patch.Role).Msg("UpdateUser")
+
+	if (patch.Status != nil || patch.Role != nil) && isCaller(ctx, userID) {
+		return apperr.ErrConflict.WithFunc(apperr.WithDetail("admins cannot change their own status or role"))
+	}
+
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.admin.PatchUser(ctx, userID, patch); err != nil {
+			return err
+		}
+		if patch.Status != nil && *patch.Status != model.UserStatusActive {
+			return s.sessions.RevokeAll(ctx, userID)
+		}
+
+		return nil
+	})
+	if err != nil || patch.Status == nil {
+		return err
+	}
+
+	user, err := s.reader.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.unlocker.Unlock(ctx, user.Username)
+}
+
+// DeleteUser soft deletes the user on behalf of an admin and signs the user out
+func (s *UserService) DeleteUser(ctx context.Context, userID types.UserID) error {
+	ctx, span := tracing.Start(ctx, "UserService.DeleteUser")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("userID", userID).Msg("DeleteUser")
+
+	if isCaller(ctx, userID) {
+		return apperr.ErrConflict.WithFunc(apperr.WithDetail("admins cannot delete their own account"))
+	}
+
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.admin.DeleteUser(ctx, userID); err != nil {
+			return err
+		}
+
+		return s.sessions.RevokeAll(ctx, userID)
+	})
+}
+
+// InvalidatePassword erases the password of the user so that the user can not sign in until
+// the password is replaced, the user is signed out of every session
+func (s *UserService) InvalidatePassword(ctx context.Context, user model.User) error {
+	ctx, span := tracing.Start(ctx, "UserService.InvalidatePassword")
+	defer span.End()
+
+	s.log.Dbg().Ctx(ctx).Values("userID", user.ID).Msg("InvalidatePassword")
+
+	// an empty hash matches no password
+	user.Password = ""
+
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.writer.UpdatePassword(ctx, user); err != nil {
+			return err
+		}
+
+		return s.sessions.RevokeAll(ctx, user.ID)
+	})
+}
+
+// isCaller reports whether the user is the authenticated caller
+func isCaller(ctx context.Context, userID types.UserID) bool {
+	caller := common.GetUser(ctx)
+	return caller != nil && caller.ID == userID
+}
@@ -0,0 +1,14 @@
+package service
+
+import (
+	"github.com/vlaship/book-catalog-go/internal/apperr"
+)
+
+// checkVersion fails if a non-zero expected version differs from the current one
+func checkVersion(current, expected int64) error {
+	if expected != 0 && current != expected {
+		return apperr.ErrPreconditionFailed
+	}
+
+	return nil
+}
@@ -10,8 +10,11 @@ import (
 	"github.com/vlaship/book-catalog-go/internal/config"
 	"github.com/vlaship/book-catalog-go/internal/email"
 	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/metrics"
+	"github.com/vlaship/book-catalog-go/internal/password"
 	"github.com/vlaship/book-catalog-go/internal/snowflake"
 	"github.com/vlaship/book-catalog-go/internal/template"
+	"github.com/vlaship/book-catalog-go/internal/totp"
 )
 
 func Wire(
@@ -21,7 +24,10 @@ func Wire(
 	templates template.Templates,
 	sender email.Sender,
 	cacher cache.Cache,
+	recorder metrics.Recorder,
 	idGen snowflake.IDGenerator,
+	cipher totp.Cipher,
+	policy password.Policy,
 	log logger.Logger,
 ) *Services {
 	wire.Build(
@@ -33,24 +39,56 @@ func Wire(
 		NewUserService,
 		NewOTPService,
 		NewPasswordService,
+		NewSearchService,
+		NewRefreshTokenService,
+		NewAuditService,
+		NewTrashService,
+		NewImportService,
+		NewReadCache,
+		NewOutboxService,
+		NewTwoFactorService,
+		NewSigninGuardService,
 
 		BookReaderProvider,
 		BookWriterProvider,
 		AuthorReaderProvider,
 		AuthorWriterProvider,
+		BookTrashProvider,
+		AuthorTrashProvider,
+		ImportAuthorStoreProvider,
+		ImportBookStoreProvider,
 
 		UserWriterProvider,
 		UserReaderProvider,
 		TosReaderProvider,
+		SearchReaderProvider,
 		PasswordHandlerProvider,
+		RefreshTokenStoreProvider,
+		RefreshTokenHandlerProvider,
+		AuditStoreProvider,
+		AuditRecorderProvider,
+		TransactorProvider,
+		OutboxStoreProvider,
+		MailDelivererProvider,
+		ActivationMailerProvider,
+		OTPGeneratorProvider,
+		OTPValidatorProvider,
+		UserAdminStoreProvider,
+		SessionRevokerProvider,
+		TwoFactorStoreProvider,
+		TwoFactorChallengerProvider,
+		SigninGuardProvider,
+		SigninUnlockerProvider,
+		UserLockerProvider,
+		LockMailerProvider,
 		wire.Struct(new(Services), "*"),
 	)
 	return &Services{}
 }
 
 // PasswordHandlerProvider is a provider for PasswordHandler
-func PasswordHandlerProvider() PasswordHandler {
-	return NewPasswordService()
+func PasswordHandlerProvider(s *PasswordService) PasswordHandler {
+	return s
 }
 
 // UserReaderProvider is a provider for UserReader
@@ -63,6 +101,101 @@ func TosReaderProvider(repos *repository.Repositories) TosReader {
 	return repos.PropertyRepository
 }
 
+// SearchReaderProvider is a provider for SearchReader
+func SearchReaderProvider(repos *repository.Repositories) SearchReader {
+	return repos.SearchRepository
+}
+
+// RefreshTokenStoreProvider is a provider for RefreshTokenStore
+func RefreshTokenStoreProvider(repos *repository.Repositories) RefreshTokenStore {
+	return repos.RefreshTokenRepository
+}
+
+// RefreshTokenHandlerProvider is a provider for RefreshTokenHandler
+func RefreshTokenHandlerProvider(s *RefreshTokenService) RefreshTokenHandler {
+	return s
+}
+
+// AuditStoreProvider is a provider for AuditStore
+func AuditStoreProvider(repos *repository.Repositories) AuditStore {
+	return repos.AuditRepository
+}
+
+// AuditRecorderProvider is a provider for AuditRecorder
+func AuditRecorderProvider(s *AuditService) AuditRecorder {
+	return s
+}
+
+// TransactorProvider is a provider for Transactor
+func TransactorProvider(repos *repository.Repositories) Transactor {
+	return repos.TxManager
+}
+
+// OutboxStoreProvider is a provider for OutboxStore
+func OutboxStoreProvider(repos *repository.Repositories) OutboxStore {
+	return repos.OutboxRepository
+}
+
+// MailDelivererProvider is a provider for MailDeliverer
+func MailDelivererProvider(s *SendMailService) MailDeliverer {
+	return s
+}
+
+// ActivationMailerProvider is a provider for ActivationMailer
+func ActivationMailerProvider(s *OutboxService) ActivationMailer {
+	return s
+}
+
+// OTPValidatorProvider is a provider for OTPValidator
+func OTPValidatorProvider(s *OTPService) OTPValidator {
+	return s
+}
+
+// OTPGeneratorProvider is a provider for OTPGenerator
+func OTPGeneratorProvider(s *OTPService) OTPGenerator {
+	return s
+}
+
+// UserAdminStoreProvider is a provider for UserAdminStore
+func UserAdminStoreProvider(repos *repository.Repositories) UserAdminStore {
+	return repos.UserRepository
+}
+
+// SessionRevokerProvider is a provider for SessionRevoker
+func SessionRevokerProvider(s *RefreshTokenService) SessionRevoker {
+	return s
+}
+
+// TwoFactorStoreProvider is a provider for TwoFactorStore
+func TwoFactorStoreProvider(repos *repository.Repositories) TwoFactorStore {
+	return repos.UserRepository
+}
+
+// TwoFactorChallengerProvider is a provider for TwoFactorChallenger
+func TwoFactorChallengerProvider(s *TwoFactorService) TwoFactorChallenger {
+	return s
+}
+
+// SigninGuardProvider is a provider for SigninGuard
+func SigninGuardProvider(s *SigninGuardService) SigninGuard {
+	return s
+}
+
+// SigninUnlockerProvider is a provider for SigninUnlocker
+func SigninUnlockerProvider(s *SigninGuardService) SigninUnlocker {
+	return s
+}
+
+// UserLockerProvider is a provider for UserLocker
+func UserLockerProvider(repos *repository.Repositories) UserLocker {
+	return repos.UserRepository
+}
+
+// LockMailerProvider is a provider for LockMailer
+func LockMailerProvider(s *OutboxService) LockMailer {
+	return s
+}
+
 // UserWriterProvider is a provider for UserWriter
 func UserWriterProvider(repos *repository.Repositories) UserWriter {
 	return repos.UserRepository
@@ -87,3 +220,23 @@ func AuthorReaderProvider(repos *repository.Repositories) AuthorReader {
 func AuthorWriterProvider(repos *repository.Repositories) AuthorWriter {
 	return repos.AuthorRepository
 }
+
+// BookTrashProvider is a provider for BookTrash
+func BookTrashProvider(repos *repository.Repositories) BookTrash {
+	return repos.BookRepository
+}
+
+// AuthorTrashProvider is a provider for AuthorTrash
+func AuthorTrashProvider(repos *repository.Repositories) AuthorTrash {
+	return repos.AuthorRepository
+}
+
+// ImportAuthorStoreProvider is a provider for ImportAuthorStore
+func ImportAuthorStoreProvider(repos *repository.Repositories) ImportAuthorStore {
+	return repos.AuthorRepository
+}
+
+// ImportBookStoreProvider is a provider for ImportBookStore
+func ImportBookStoreProvider(repos *repository.Repositories) ImportBookStore {
+	return repos.BookRepository
+}
@@ -4,3 +4,6 @@ type contextKey string
 
 // UserContextKey is a key for user context
 const UserContextKey contextKey = "user"
+
+// ClientIPContextKey is a key for client IP context
+const ClientIPContextKey contextKey = "client-ip"
@@ -43,6 +43,16 @@ func NewID(id string) (ID, error) {
 // Token is a custom type for a token
 type Token string
 
+// OTPPurpose is a custom type for the purpose an OTP is issued for
+type OTPPurpose string
+
+// OTPPurpose values
+const (
+	OTPPurposeActivation    OTPPurpose = "activation"
+	OTPPurposeResetPassword OTPPurpose = "reset-password"
+	OTPPurposeChangeEmail   OTPPurpose = "change-email"
+)
+
 // Username is a custom type for a username
 type Username string
 
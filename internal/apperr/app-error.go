@@ -6,6 +6,14 @@ type AppError struct {
 	Title  string
 	Code   string
 	Detail string
+	// Violations lists the failed rules, the pointer keeps AppError comparable
+	Violations *[]Violation
+}
+
+// Violation is a failed rule reported by an AppError
+type Violation struct {
+	Rule   string
+	Detail string
 }
 
 // WithFunc applies a list of functions to an AppError and returns the modified AppError
@@ -33,6 +41,14 @@ func WithDetail(detail string) func(p AppError) AppError {
 	}
 }
 
+// WithViolations func
+func WithViolations(violations []Violation) func(p AppError) AppError {
+	return func(p AppError) AppError {
+		p.Violations = &violations
+		return p
+	}
+}
+
 // Error implement error interface
 func (p AppError) Error() string {
 	return p.Detail
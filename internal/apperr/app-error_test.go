@@ -19,3 +19,12 @@ func TestWithFunc(t *testing.T) {
 	assert.Equal(t, given.Code, result.Code)
 	assert.ErrorIs(t, result.Err, given)
 }
+
+func TestWithViolations(t *testing.T) {
+	violations := []Violation{{Rule: "rule", Detail: "detail"}}
+	result := ErrBadRequest.WithFunc(WithViolations(violations))
+
+	assert.ErrorIs(t, result, ErrBadRequest)
+	assert.Equal(t, violations, *result.Violations)
+	assert.Nil(t, ErrBadRequest.Violations)
+}
@@ -89,4 +89,50 @@ var (
 		Detail: "already exists",
 		Err:    ErrBadRequest,
 	}
+	ErrPreconditionFailed = AppError{
+		Code:   "ERR-018",
+		Title:  http.StatusText(http.StatusPreconditionFailed),
+		Detail: "resource has been modified",
+	}
+	ErrConflict = AppError{
+		Code:   "ERR-019",
+		Title:  http.StatusText(http.StatusConflict),
+		Detail: "resource is in conflicting state",
+	}
+	ErrTooManyAttempts = AppError{
+		Code:   "ERR-020",
+		Title:  http.StatusText(http.StatusTooManyRequests),
+		Detail: "too many attempts, try again later",
+	}
+	ErrRateLimited = AppError{
+		Code:   "ERR-021",
+		Title:  http.StatusText(http.StatusTooManyRequests),
+		Detail: "rate limit exceeded, try again later",
+	}
+	ErrUserSuspended = AppError{
+		Code:   "ERR-022",
+		Detail: "user is suspended",
+		Err:    ErrForbidden,
+	}
+	ErrUserLocked = AppError{
+		Code:   "ERR-023",
+		Detail: "user is locked",
+		Err:    ErrForbidden,
+	}
+	ErrInvalidPassword = AppError{
+		Code:   "ERR-024",
+		Detail: "invalid password",
+		Err:    ErrForbidden,
+	}
+	ErrUserTemporarilyLocked = AppError{
+		Code:   "ERR-025",
+		Detail: "user is temporarily locked after too many failed signin attempts",
+		Err:    ErrForbidden,
+	}
+	ErrWeakPassword = AppError{
+		Code:   "ERR-026",
+		Title:  "Password does not meet the password policy",
+		Detail: "password does not meet the password policy",
+		Err:    ErrBadRequest,
+	}
 )
@@ -11,4 +11,5 @@ import (
 type Authenticator interface {
 	GenerateAccessToken(userID types.UserID) (accessToken types.Token, expiresIn int64, err error)
 	GetUserID(r *http.Request) (types.UserID, error)
+	JWKS() JWKS
 }
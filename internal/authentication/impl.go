@@ -1,6 +1,8 @@
 package authentication
 
 import (
+	"crypto"
+	"errors"
 	"fmt"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/vlaship/book-catalog-go/internal/app/types"
@@ -17,20 +19,144 @@ const (
 	authHeader = "Authorization"
 )
 
+// hmacKeyID is the kid of tokens signed with the shared secret when JWT_KEY_ID is not set.
+const hmacKeyID = "hs256"
+
 // AuthenticatorImpl is an implementation of the Authenticator interface.
 type AuthenticatorImpl struct {
-	secret   []byte
 	duration time.Duration
 	method   jwt.SigningMethod
+	kid      string
+	signKey  any
+	keys     map[string]verificationKey
+	jwks     JWKS
 }
 
 // New creates a new auth instance.
-func New(cfg *config.Config) Authenticator {
-	return &AuthenticatorImpl{
-		secret:   cfg.JWT.Secret,
+// Tokens are signed with the shared secret (HS256) or the private key (RS256, ES256, EdDSA),
+// and verified with the key matching their kid, so previous public keys can be kept during rotation.
+func New(cfg *config.Config) (Authenticator, error) {
+	alg := cfg.JWT.Algorithm
+	if alg == "" {
+		alg = jwt.SigningMethodHS256.Alg()
+	}
+	method, err := signingMethod(alg)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &AuthenticatorImpl{
 		duration: cfg.JWT.Duration,
-		method:   jwt.SigningMethodHS256,
+		method:   method,
+		keys:     make(map[string]verificationKey),
+		jwks:     JWKS{Keys: []JWK{}},
+	}
+
+	if _, ok := method.(*jwt.SigningMethodHMAC); ok {
+		err = j.initSecret(cfg)
+	} else {
+		err = j.initPrivateKey(cfg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ref := range cfg.JWT.PublicKeyFiles {
+		if err = j.addPublicKey(ref); err != nil {
+			return nil, err
+		}
+	}
+
+	return j, nil
+}
+
+func (j *AuthenticatorImpl) initSecret(cfg *config.Config) error {
+	if len(cfg.JWT.Secret) == 0 {
+		return errors.New("JWT secret is required for HS256")
+	}
+
+	j.kid = cfg.JWT.KeyID
+	if j.kid == "" {
+		j.kid = hmacKeyID
+	}
+	j.signKey = cfg.JWT.Secret
+
+	// the secret is never published, tokens issued before kid was introduced have no kid
+	key := verificationKey{kid: j.kid, method: j.method, key: cfg.JWT.Secret}
+	j.keys[j.kid] = key
+	j.keys[""] = key
+
+	return nil
+}
+
+func (j *AuthenticatorImpl) initPrivateKey(cfg *config.Config) error {
+	if cfg.JWT.PrivateKeyFile == "" {
+		return fmt.Errorf("JWT private key file is required for %s", j.method.Alg())
+	}
+
+	signer, err := loadPrivateKey(cfg.JWT.PrivateKeyFile)
+	if err != nil {
+		return err
+	}
+
+	method, err := methodForKey(signer.Public())
+	if err != nil {
+		return err
+	}
+	if method.Alg() != j.method.Alg() {
+		return fmt.Errorf("JWT private key does not match algorithm %s", j.method.Alg())
+	}
+
+	j.kid = cfg.JWT.KeyID
+	if j.kid == "" {
+		if j.kid, err = thumbprint(signer.Public()); err != nil {
+			return err
+		}
+	}
+	j.signKey = signer
+
+	return j.register(j.kid, method, signer.Public())
+}
+
+func (j *AuthenticatorImpl) addPublicKey(ref string) error {
+	kid, path := parseKeyRef(ref)
+	if path == "" {
+		return nil
+	}
+
+	key, err := loadPublicKey(path)
+	if err != nil {
+		return err
+	}
+
+	method, err := methodForKey(key)
+	if err != nil {
+		return err
+	}
+
+	if kid == "" {
+		if kid, err = thumbprint(key); err != nil {
+			return err
+		}
+	}
+
+	return j.register(kid, method, key)
+}
+
+func (j *AuthenticatorImpl) register(kid string, method jwt.SigningMethod, key crypto.PublicKey) error {
+	if _, ok := j.keys[kid]; ok {
+		return fmt.Errorf("duplicate JWT key id: %s", kid)
 	}
+
+	jwk, err := newJWK(kid, method.Alg(), key)
+	if err != nil {
+		return err
+	}
+
+	j.keys[kid] = verificationKey{kid: kid, method: method, key: key}
+	j.jwks.Keys = append(j.jwks.Keys, jwk)
+
+	return nil
 }
 
 // GenerateAccessToken creates a new access token.
@@ -38,12 +164,17 @@ func (j *AuthenticatorImpl) GenerateAccessToken(userID types.UserID) (accessToke
 	token := jwt.NewWithClaims(j.method, jwt.RegisteredClaims{
 		Subject:   userID.String(),
 		ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.duration)),
-		// * add more claims
 	})
-	t, err := token.SignedString(j.secret)
+	token.Header["kid"] = j.kid
+	t, err := token.SignedString(j.signKey)
 	return types.Token(t), j.expiresIn(), err
 }
 
+// JWKS returns the public keys accepted for token verification.
+func (j *AuthenticatorImpl) JWKS() JWKS {
+	return j.jwks
+}
+
 // GetUserID returns the user id from the request.
 func (j *AuthenticatorImpl) GetUserID(r *http.Request) (types.UserID, error) {
 	userID, err := j.getUserID(r)
@@ -81,16 +212,7 @@ func (j *AuthenticatorImpl) extractToken(r *http.Request) (string, error) {
 }
 
 func (j *AuthenticatorImpl) extractClaims(token string) (jwt.Claims, error) {
-	t, err := jwt.Parse(token, func(token *jwt.Token) (any, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		if m := token.Method.Alg(); m != j.method.Alg() {
-			return nil, fmt.Errorf("unexpected signing method: %v", m)
-		}
-
-		return j.secret, nil
-	})
+	t, err := jwt.Parse(token, j.keyFunc)
 	if err != nil {
 		return nil, err
 	}
@@ -101,6 +223,19 @@ func (j *AuthenticatorImpl) extractClaims(token string) (jwt.Claims, error) {
 	return nil, err
 }
 
+func (j *AuthenticatorImpl) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := j.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id: %v", token.Header["kid"])
+	}
+	if token.Method == nil || token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.key, nil
+}
+
 func (j *AuthenticatorImpl) extractUserID(claims jwt.Claims) (types.UserID, error) {
 	exp, err := claims.GetExpirationTime()
 	if err != nil || exp == nil {
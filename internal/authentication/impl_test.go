@@ -1,27 +1,44 @@
 package authentication
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/vlaship/book-catalog-go/internal/app/types"
 	"github.com/vlaship/book-catalog-go/internal/config"
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
 
+func newConfig(secret string, duration time.Duration) *config.Config {
+	cfg := &config.Config{}
+	cfg.JWT.Secret = []byte(secret)
+	cfg.JWT.Duration = duration
+	return cfg
+}
+
+func mustNew(t *testing.T, cfg *config.Config) Authenticator {
+	t.Helper()
+	auth, err := New(cfg)
+	require.NoError(t, err)
+	return auth
+}
+
 func TestGenerateAccessToken(t *testing.T) {
 	// given
-	cfg := &config.Config{
-		JWT: struct {
-			Secret   []byte
-			Duration time.Duration
-		}{
-			Secret:   []byte("secret"),
-			Duration: 1 * time.Hour,
-		},
-	}
-	auth := New(cfg)
+	cfg := newConfig("secret", 1*time.Hour)
+	auth := mustNew(t, cfg)
 
 	// when
 	token, _, err := auth.GenerateAccessToken(types.UserID(1))
@@ -31,18 +48,29 @@ func TestGenerateAccessToken(t *testing.T) {
 	assert.NotEmpty(t, token)
 }
 
+func TestGenerateAccessTokenClaims(t *testing.T) {
+	// given
+	cfg := newConfig("secret", 1*time.Hour)
+	auth := mustNew(t, cfg)
+
+	// when
+	token, _, err := auth.GenerateAccessToken(types.UserID(1))
+	claims := jwt.MapClaims{}
+	_, parseErr := jwt.ParseWithClaims(string(token), claims, func(*jwt.Token) (any, error) {
+		return cfg.JWT.Secret, nil
+	})
+
+	// then
+	assert.NoError(t, err)
+	assert.NoError(t, parseErr)
+	assert.Equal(t, "1", claims["sub"])
+	assert.NotContains(t, claims, "role")
+}
+
 func TestGetUserIDSuccess(t *testing.T) {
 	// given
-	cfg := &config.Config{
-		JWT: struct {
-			Secret   []byte
-			Duration time.Duration
-		}{
-			Secret:   []byte("secret"),
-			Duration: 1 * time.Hour,
-		},
-	}
-	auth := New(cfg)
+	cfg := newConfig("secret", 1*time.Hour)
+	auth := mustNew(t, cfg)
 	expected := types.UserID(1)
 
 	// when
@@ -60,16 +88,8 @@ func TestGetUserIDSuccess(t *testing.T) {
 
 func TestGetUserIDFailNoHeader(t *testing.T) {
 	// given
-	cfg := &config.Config{
-		JWT: struct {
-			Secret   []byte
-			Duration time.Duration
-		}{
-			Secret:   []byte("secret"),
-			Duration: 1 * time.Hour,
-		},
-	}
-	auth := New(cfg)
+	cfg := newConfig("secret", 1*time.Hour)
+	auth := mustNew(t, cfg)
 
 	// when
 	_, err := auth.GetUserID(&http.Request{
@@ -83,16 +103,8 @@ func TestGetUserIDFailNoHeader(t *testing.T) {
 
 func TestGetUserIDFailNoBearer(t *testing.T) {
 	// given
-	cfg := &config.Config{
-		JWT: struct {
-			Secret   []byte
-			Duration time.Duration
-		}{
-			Secret:   []byte("secret"),
-			Duration: 1 * time.Hour,
-		},
-	}
-	auth := New(cfg)
+	cfg := newConfig("secret", 1*time.Hour)
+	auth := mustNew(t, cfg)
 
 	// when
 	_, err := auth.GetUserID(&http.Request{
@@ -107,16 +119,8 @@ func TestGetUserIDFailNoBearer(t *testing.T) {
 }
 
 func TestGetUserIDFailInvalidTokenWithSuffix(t *testing.T) { // given
-	cfg := &config.Config{
-		JWT: struct {
-			Secret   []byte
-			Duration time.Duration
-		}{
-			Secret:   []byte("secret"),
-			Duration: 1 * time.Hour,
-		},
-	}
-	auth := New(cfg)
+	cfg := newConfig("secret", 1*time.Hour)
+	auth := mustNew(t, cfg)
 	token, _, _ := auth.GenerateAccessToken(types.UserID(1))
 
 	// when
@@ -133,24 +137,8 @@ func TestGetUserIDFailInvalidTokenWithSuffix(t *testing.T) { // given
 
 func TestGetUserIDFailInvalidTokenAnotherSecret(t *testing.T) {
 	// given
-	auth1 := New(&config.Config{
-		JWT: struct {
-			Secret   []byte
-			Duration time.Duration
-		}{
-			Secret:   []byte("secret"),
-			Duration: 1 * time.Hour,
-		},
-	})
-	auth2 := New(&config.Config{
-		JWT: struct {
-			Secret   []byte
-			Duration time.Duration
-		}{
-			Secret:   []byte("invalid"),
-			Duration: 1 * time.Hour,
-		},
-	})
+	auth1 := mustNew(t, newConfig("secret", 1*time.Hour))
+	auth2 := mustNew(t, newConfig("invalid", 1*time.Hour))
 	expected := types.UserID(1)
 	token, _, _ := auth1.GenerateAccessToken(expected)
 
@@ -168,16 +156,8 @@ func TestGetUserIDFailInvalidTokenAnotherSecret(t *testing.T) {
 
 func TestGetUserIDFailInvalidTokenExpired(t *testing.T) {
 	// given
-	cfg := &config.Config{
-		JWT: struct {
-			Secret   []byte
-			Duration time.Duration
-		}{
-			Secret:   []byte("secret"),
-			Duration: -1 * time.Hour,
-		},
-	}
-	auth := New(cfg)
+	cfg := newConfig("secret", -1*time.Hour)
+	auth := mustNew(t, cfg)
 	expected := types.UserID(1)
 	token, _, _ := auth.GenerateAccessToken(expected)
 
@@ -195,16 +175,8 @@ func TestGetUserIDFailInvalidTokenExpired(t *testing.T) {
 
 func TestGetUserIDFailInvalidTokenNoSubject(t *testing.T) {
 	// given
-	cfg := &config.Config{
-		JWT: struct {
-			Secret   []byte
-			Duration time.Duration
-		}{
-			Secret:   []byte("secret"),
-			Duration: 1 * time.Hour,
-		},
-	}
-	auth := New(cfg)
+	cfg := newConfig("secret", 1*time.Hour)
+	auth := mustNew(t, cfg)
 	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
 		ExpiresAt: jwt.NewNumericDate(time.Now().Add(cfg.JWT.Duration)),
 	}).SignedString(cfg.JWT.Secret)
@@ -223,16 +195,8 @@ func TestGetUserIDFailInvalidTokenNoSubject(t *testing.T) {
 
 func TestGetUserIDFailInvalidTokenNoExpirationTime(t *testing.T) {
 	// given
-	cfg := &config.Config{
-		JWT: struct {
-			Secret   []byte
-			Duration time.Duration
-		}{
-			Secret:   []byte("secret"),
-			Duration: 1 * time.Hour,
-		},
-	}
-	auth := New(cfg)
+	cfg := newConfig("secret", 1*time.Hour)
+	auth := mustNew(t, cfg)
 	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
 		Subject: "1",
 	}).SignedString(cfg.JWT.Secret)
@@ -251,16 +215,8 @@ func TestGetUserIDFailInvalidTokenNoExpirationTime(t *testing.T) {
 
 func TestGetUserIDFailInvalidTokenNoSigningMethod(t *testing.T) {
 	// given
-	cfg := &config.Config{
-		JWT: struct {
-			Secret   []byte
-			Duration time.Duration
-		}{
-			Secret:   []byte("secret"),
-			Duration: 1 * time.Hour,
-		},
-	}
-	auth := New(cfg)
+	cfg := newConfig("secret", 1*time.Hour)
+	auth := mustNew(t, cfg)
 	token, _ := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.RegisteredClaims{
 		Subject:   "1",
 		ExpiresAt: jwt.NewNumericDate(time.Now().Add(1 * time.Hour)),
@@ -280,16 +236,8 @@ func TestGetUserIDFailInvalidTokenNoSigningMethod(t *testing.T) {
 
 func TestGetUserIDFailInvalidTokenInvalidSigningMethod(t *testing.T) {
 	// given
-	cfg := &config.Config{
-		JWT: struct {
-			Secret   []byte
-			Duration time.Duration
-		}{
-			Secret:   []byte("secret"),
-			Duration: 1 * time.Hour,
-		},
-	}
-	auth := New(cfg)
+	cfg := newConfig("secret", 1*time.Hour)
+	auth := mustNew(t, cfg)
 	token, _ := jwt.NewWithClaims(jwt.SigningMethodES384, jwt.RegisteredClaims{
 		Subject:   "1",
 		ExpiresAt: jwt.NewNumericDate(time.Now().Add(1 * time.Hour)),
@@ -306,3 +254,145 @@ func TestGetUserIDFailInvalidTokenInvalidSigningMethod(t *testing.T) {
 	assert.Error(t, err)
 	assert.Error(t, err, "token is malformed: token contains an invalid number of segments")
 }
+
+func writeKeyPair(t *testing.T, signer crypto.Signer) (privateFile, publicFile string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	private, err := x509.MarshalPKCS8PrivateKey(signer)
+	require.NoError(t, err)
+	public, err := x509.MarshalPKIXPublicKey(signer.Public())
+	require.NoError(t, err)
+
+	privateFile = filepath.Join(dir, "private.pem")
+	publicFile = filepath.Join(dir, "public.pem")
+	require.NoError(t, os.WriteFile(privateFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: private}), 0o600))
+	require.NoError(t, os.WriteFile(publicFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public}), 0o600))
+
+	return privateFile, publicFile
+}
+
+func newKeyConfig(alg, privateFile string, publicFiles ...string) *config.Config {
+	cfg := newConfig("", 1*time.Hour)
+	cfg.JWT.Algorithm = alg
+	cfg.JWT.PrivateKeyFile = privateFile
+	cfg.JWT.PublicKeyFiles = publicFiles
+	return cfg
+}
+
+func TestAsymmetricSigning(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		alg    string
+		signer crypto.Signer
+		kty    string
+	}{
+		{alg: "RS256", signer: rsaKey, kty: "RSA"},
+		{alg: "ES256", signer: ecKey, kty: "EC"},
+		{alg: "EdDSA", signer: edKey, kty: "OKP"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alg, func(t *testing.T) {
+			// given
+			privateFile, _ := writeKeyPair(t, tt.signer)
+			auth := mustNew(t, newKeyConfig(tt.alg, privateFile))
+			expected := types.UserID(1)
+
+			// when
+			token, _, err := auth.GenerateAccessToken(expected)
+			require.NoError(t, err)
+			parsed, _, parseErr := jwt.NewParser().ParseUnverified(string(token), &jwt.RegisteredClaims{})
+			result, userErr := auth.GetUserID(&http.Request{
+				Header: map[string][]string{
+					"Authorization": {bearer + string(token)},
+				},
+			})
+			jwks := auth.JWKS()
+
+			// then
+			assert.NoError(t, parseErr)
+			assert.NoError(t, userErr)
+			assert.Equal(t, expected, result)
+			assert.Equal(t, tt.alg, parsed.Method.Alg())
+			require.Len(t, jwks.Keys, 1)
+			assert.Equal(t, parsed.Header["kid"], jwks.Keys[0].Kid)
+			assert.Equal(t, tt.kty, jwks.Keys[0].Kty)
+			assert.Equal(t, tt.alg, jwks.Keys[0].Alg)
+		})
+	}
+}
+
+func TestKeyRotationAcceptsPreviousKey(t *testing.T) {
+	// given
+	_, oldKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, newKey, _ := ed25519.GenerateKey(rand.Reader)
+	oldPrivate, oldPublic := writeKeyPair(t, oldKey)
+	newPrivate, _ := writeKeyPair(t, newKey)
+
+	oldAuth := mustNew(t, newKeyConfig("EdDSA", oldPrivate))
+	newAuth := mustNew(t, newKeyConfig("EdDSA", newPrivate, oldPublic))
+	token, _, _ := oldAuth.GenerateAccessToken(types.UserID(1))
+
+	// when
+	result, err := newAuth.GetUserID(&http.Request{
+		Header: map[string][]string{
+			"Authorization": {bearer + string(token)},
+		},
+	})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, types.UserID(1), result)
+	assert.Len(t, newAuth.JWKS().Keys, 2)
+}
+
+func TestGetUserIDFailUnknownKeyID(t *testing.T) {
+	// given
+	_, key1, _ := ed25519.GenerateKey(rand.Reader)
+	_, key2, _ := ed25519.GenerateKey(rand.Reader)
+	private1, _ := writeKeyPair(t, key1)
+	private2, _ := writeKeyPair(t, key2)
+	token, _, _ := mustNew(t, newKeyConfig("EdDSA", private1)).GenerateAccessToken(types.UserID(1))
+
+	// when
+	_, err := mustNew(t, newKeyConfig("EdDSA", private2)).GetUserID(&http.Request{
+		Header: map[string][]string{
+			"Authorization": {bearer + string(token)},
+		},
+	})
+
+	// then
+	assert.ErrorContains(t, err, "unknown key id")
+}
+
+func TestNewFailAlgorithmKeyMismatch(t *testing.T) {
+	// given
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	privateFile, _ := writeKeyPair(t, key)
+
+	// when
+	_, err := New(newKeyConfig("RS256", privateFile))
+
+	// then
+	assert.Error(t, err)
+}
+
+func TestHMACDoesNotPublishKeys(t *testing.T) {
+	// given
+	auth := mustNew(t, newConfig("secret", 1*time.Hour))
+
+	// when
+	token, _, _ := auth.GenerateAccessToken(types.UserID(1))
+	parsed, _, err := jwt.NewParser().ParseUnverified(string(token), &jwt.RegisteredClaims{})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, hmacKeyID, parsed.Header["kid"])
+	assert.Empty(t, auth.JWKS().Keys)
+}
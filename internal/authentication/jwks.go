@@ -0,0 +1,58 @@
+package authentication
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"fmt"
+	"math/big"
+)
+
+// JWK is a public JSON Web Key (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func newJWK(kid, alg string, key crypto.PublicKey) (JWK, error) {
+	jwk := JWK{Use: "sig", Alg: alg, Kid: kid}
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = b64(k.N.Bytes())
+		jwk.E = b64(big.NewInt(int64(k.E)).Bytes())
+	case *ecdsa.PublicKey:
+		pub, err := k.ECDH()
+		if err != nil {
+			return JWK{}, err
+		}
+		// uncompressed point: 0x04 || X || Y
+		point := pub.Bytes()[1:]
+		jwk.Kty = "EC"
+		jwk.Crv = k.Curve.Params().Name
+		jwk.X = b64(point[:len(point)/2])
+		jwk.Y = b64(point[len(point)/2:])
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = b64(k)
+	default:
+		return JWK{}, fmt.Errorf("%w: %T", errUnsupportedKey, key)
+	}
+
+	return jwk, nil
+}
@@ -0,0 +1,149 @@
+package authentication
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"os"
+	"strings"
+)
+
+var errUnsupportedKey = errors.New("unsupported key type")
+
+// verificationKey is a public key accepted for token verification.
+type verificationKey struct {
+	kid    string
+	method jwt.SigningMethod
+	key    crypto.PublicKey
+}
+
+// signingMethod returns the signing method by algorithm name.
+func signingMethod(alg string) (jwt.SigningMethod, error) {
+	switch alg {
+	case jwt.SigningMethodHS256.Alg(),
+		jwt.SigningMethodRS256.Alg(),
+		jwt.SigningMethodES256.Alg(),
+		jwt.SigningMethodEdDSA.Alg():
+		return jwt.GetSigningMethod(alg), nil
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", alg)
+	}
+}
+
+// methodForKey returns the signing method matching the public key type.
+func methodForKey(key crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%w: ecdsa curve %s", errUnsupportedKey, k.Curve.Params().Name)
+		}
+		return jwt.SigningMethodES256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("%w: %T", errUnsupportedKey, key)
+	}
+}
+
+// loadPrivateKey loads a PKCS#8, PKCS#1 or SEC 1 private key from the PEM file.
+func loadPrivateKey(path string) (crypto.Signer, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("%w: %T", errUnsupportedKey, key)
+		}
+		return signer, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("failed to parse private key %s", path)
+}
+
+// loadPublicKey loads a PKIX or PKCS#1 public key or a certificate from the PEM file.
+func loadPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+		return cert.PublicKey, nil
+	}
+
+	return nil, fmt.Errorf("failed to parse public key %s", path)
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", path)
+	}
+
+	return block, nil
+}
+
+// parseKeyRef parses a "kid=path" or "path" reference to a public key file.
+func parseKeyRef(ref string) (kid, path string) {
+	if i := strings.Index(ref, "="); i > -1 {
+		return strings.TrimSpace(ref[:i]), strings.TrimSpace(ref[i+1:])
+	}
+	return "", strings.TrimSpace(ref)
+}
+
+// thumbprint returns the RFC 7638 JWK thumbprint of the public key, it is used as a default kid.
+func thumbprint(key crypto.PublicKey) (string, error) {
+	jwk, err := newJWK("", "", key)
+	if err != nil {
+		return "", err
+	}
+
+	// members in lexicographic order as required by RFC 7638
+	var s string
+	switch jwk.Kty {
+	case "RSA":
+		s = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, jwk.E, jwk.N)
+	case "EC":
+		s = fmt.Sprintf(`{"crv":"%s","kty":"EC","x":"%s","y":"%s"}`, jwk.Crv, jwk.X, jwk.Y)
+	case "OKP":
+		s = fmt.Sprintf(`{"crv":"%s","kty":"OKP","x":"%s"}`, jwk.Crv, jwk.X)
+	}
+
+	sum := sha256.Sum256([]byte(s))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -1,12 +1,41 @@
 package cache
 
-import "time"
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vlaship/book-catalog-go/internal/config"
+)
+
+// Backend values of CACHE_BACKEND.
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
 
 // Cache is a cache interface.
+// Values are stored encoded, Get and GetDel decode them into dest, which must be a pointer.
+// Incr increments a counter atomically, the ttl is set when the counter is created.
 //
 //go:generate mockgen -destination=../../test/mock/cache/mock-cache.go -package=mock . Cache
 type Cache interface {
-	Get(key string) (any, bool)
-	GetDel(key string) (any, bool)
-	Put(key string, value any, ttl time.Duration)
+	Get(ctx context.Context, key string, dest any) (bool, error)
+	GetDel(ctx context.Context, key string, dest any) (bool, error)
+	Put(ctx context.Context, key string, value any, ttl time.Duration) error
+	Del(ctx context.Context, keys ...string) error
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	Close() error
+}
+
+// New creates a cache instance of the configured backend.
+func New(cfg *config.Config) (Cache, error) {
+	switch cfg.Cache.Backend {
+	case "", BackendMemory:
+		return NewInMem(), nil
+	case BackendRedis:
+		return NewRedis(cfg.Cache.RedisURL)
+	default:
+		return nil, fmt.Errorf("unsupported cache backend: %s", cfg.Cache.Backend)
+	}
 }
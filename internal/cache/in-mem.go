@@ -1,38 +1,107 @@
 package cache
 
 import (
-	"github.com/patrickmn/go-cache"
+	"context"
+	"encoding/json"
+	"sync"
 	"time"
+
+	"github.com/patrickmn/go-cache"
 )
 
-// InMemImpl is a cache implementation.
+// InMemImpl is a cache implementation local to the process.
 type InMemImpl struct {
+	mu    sync.Mutex
 	cache *cache.Cache
 }
 
 // GetDel pools a value from the cache.
-func (p *InMemImpl) GetDel(key string) (any, bool) {
-	value, ok := p.Get(key)
+func (p *InMemImpl) GetDel(_ context.Context, key string, dest any) (bool, error) {
+	p.mu.Lock()
+	value, ok := p.cache.Get(key)
+	if ok {
+		p.cache.Delete(key)
+	}
+	p.mu.Unlock()
+
 	if !ok {
-		return nil, false
+		return false, nil
 	}
-	p.cache.Delete(key)
-	return value, true
+	return true, json.Unmarshal(value.([]byte), dest)
 }
 
 // Get gets a value from the cache.
-func (p *InMemImpl) Get(key string) (value any, ok bool) {
-	return p.cache.Get(key)
+func (p *InMemImpl) Get(_ context.Context, key string, dest any) (bool, error) {
+	value, ok := p.cache.Get(key)
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(value.([]byte), dest)
 }
 
 // Put sets a value in the cache.
-func (p *InMemImpl) Put(key string, value any, ttl time.Duration) {
-	p.cache.Set(key, value, ttl)
+func (p *InMemImpl) Put(_ context.Context, key string, value any, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.cache.Set(key, data, ttl)
+	p.mu.Unlock()
+
+	return nil
+}
+
+// Del deletes values from the cache.
+func (p *InMemImpl) Del(_ context.Context, keys ...string) error {
+	p.mu.Lock()
+	for _, key := range keys {
+		p.cache.Delete(key)
+	}
+	p.mu.Unlock()
+
+	return nil
+}
+
+// Incr increments a counter, the ttl is set when the counter is created.
+func (p *InMemImpl) Incr(_ context.Context, key string, ttl time.Duration) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var counter int64
+	value, expiration, ok := p.cache.GetWithExpiration(key)
+	if ok {
+		remaining := cache.NoExpiration
+		if !expiration.IsZero() {
+			remaining = time.Until(expiration)
+		}
+		if remaining > 0 || remaining == cache.NoExpiration {
+			if err := json.Unmarshal(value.([]byte), &counter); err != nil {
+				return 0, err
+			}
+			ttl = remaining
+		}
+	}
+	counter++
+
+	data, err := json.Marshal(counter)
+	if err != nil {
+		return 0, err
+	}
+	p.cache.Set(key, data, ttl)
+
+	return counter, nil
+}
+
+// Close does nothing, the cache lives as long as the process.
+func (p *InMemImpl) Close() error {
+	return nil
 }
 
-// New creates a new cache instance.
-func New() Cache {
+// NewInMem creates a new in-memory cache instance.
+func NewInMem() Cache {
 	return &InMemImpl{
-		cache: cache.New(cache.NoExpiration, cache.NoExpiration),
+		cache: cache.New(cache.NoExpiration, time.Minute),
 	}
 }
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -9,39 +10,86 @@ import (
 
 func TestCache_GetDel(t *testing.T) {
 	// given
-	cache := New()
-	cache.Put("key1", "value1", time.Minute)
+	ctx := context.Background()
+	cache := NewInMem()
+	require.NoError(t, cache.Put(ctx, "key1", "value1", time.Minute))
 
 	// when
-	value1, ok1 := cache.GetDel("key1")
-	value2, ok2 := cache.GetDel("key1")
+	var value1, value2 string
+	ok1, err1 := cache.GetDel(ctx, "key1", &value1)
+	ok2, err2 := cache.GetDel(ctx, "key1", &value2)
 
 	// then
+	require.NoError(t, err1)
 	require.True(t, ok1)
 	require.Equal(t, "value1", value1)
+	require.NoError(t, err2)
 	require.False(t, ok2)
-	require.Nil(t, value2)
+	require.Empty(t, value2)
 }
 
 func TestCache_Get(t *testing.T) {
 	// given
-	cache := New()
-	cache.Put("key1", "value1", time.Minute)
+	ctx := context.Background()
+	cache := NewInMem()
+	require.NoError(t, cache.Put(ctx, "key1", "value1", time.Minute))
 
 	// when
-	value1, ok1 := cache.Get("key1")
-	value2, ok2 := cache.Get("key1")
+	var value1, value2 string
+	ok1, err1 := cache.Get(ctx, "key1", &value1)
+	ok2, err2 := cache.Get(ctx, "key1", &value2)
 
 	// then
+	require.NoError(t, err1)
 	require.True(t, ok1)
 	require.Equal(t, "value1", value1)
+	require.NoError(t, err2)
 	require.True(t, ok2)
 	require.Equal(t, "value1", value2)
 }
 
-func TestNew(t *testing.T) {
+func TestCache_Get_Struct(t *testing.T) {
+	// given
+	type entry struct {
+		ID   int64
+		Name string
+	}
+	ctx := context.Background()
+	cache := NewInMem()
+	require.NoError(t, cache.Put(ctx, "key1", &entry{ID: 1, Name: "name"}, time.Minute))
+
+	// when
+	var value entry
+	ok, err := cache.Get(ctx, "key1", &value)
+
+	// then
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, entry{ID: 1, Name: "name"}, value)
+}
+
+func TestCache_Del(t *testing.T) {
+	// given
+	ctx := context.Background()
+	cache := NewInMem()
+	require.NoError(t, cache.Put(ctx, "key1", "value1", time.Minute))
+	require.NoError(t, cache.Put(ctx, "key2", "value2", time.Minute))
+
+	// when
+	err := cache.Del(ctx, "key1", "key2")
+
+	// then
+	require.NoError(t, err)
+	var value string
+	ok, _ := cache.Get(ctx, "key1", &value)
+	require.False(t, ok)
+	ok, _ = cache.Get(ctx, "key2", &value)
+	require.False(t, ok)
+}
+
+func TestNewInMem(t *testing.T) {
 	// when
-	cache := New()
+	cache := NewInMem()
 
 	// then
 	require.NotNil(t, cache)
@@ -49,14 +97,49 @@ func TestNew(t *testing.T) {
 
 func TestCache_GetDel_Expired(t *testing.T) {
 	// given
-	cache := New()
+	ctx := context.Background()
+	cache := NewInMem()
 
 	// when
-	cache.Put("key1", "value1", time.Nanosecond)
+	require.NoError(t, cache.Put(ctx, "key1", "value1", time.Nanosecond))
 	time.Sleep(10 * time.Nanosecond)
-	value, ok := cache.GetDel("key1")
+	var value string
+	ok, err := cache.GetDel(ctx, "key1", &value)
 
 	// then
+	require.NoError(t, err)
 	require.False(t, ok)
-	require.Nil(t, value)
+	require.Empty(t, value)
+}
+
+func TestCache_Incr(t *testing.T) {
+	// given
+	ctx := context.Background()
+	cache := NewInMem()
+
+	// when
+	counter1, err1 := cache.Incr(ctx, "key1", time.Minute)
+	counter2, err2 := cache.Incr(ctx, "key1", time.Minute)
+
+	// then
+	require.NoError(t, err1)
+	require.Equal(t, int64(1), counter1)
+	require.NoError(t, err2)
+	require.Equal(t, int64(2), counter2)
+}
+
+func TestCache_Incr_Expired(t *testing.T) {
+	// given
+	ctx := context.Background()
+	cache := NewInMem()
+	_, err := cache.Incr(ctx, "key1", time.Nanosecond)
+	require.NoError(t, err)
+
+	// when
+	time.Sleep(10 * time.Nanosecond)
+	counter, err := cache.Incr(ctx, "key1", time.Minute)
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, int64(1), counter)
 }
@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"context"
+	"strings"
+)
+
+// ResultRecorder records cache hits and misses.
+type ResultRecorder interface {
+	CacheResult(keyspace string, hit bool)
+}
+
+// InstrumentedImpl is a cache implementation counting the hits and misses of the wrapped cache
+// by keyspace, the part of the key before the first colon.
+type InstrumentedImpl struct {
+	Cache
+	recorder ResultRecorder
+}
+
+// Get gets a value from the cache.
+func (c *InstrumentedImpl) Get(ctx context.Context, key string, dest any) (bool, error) {
+	ok, err := c.Cache.Get(ctx, key, dest)
+	if err == nil {
+		c.recorder.CacheResult(keyspace(key), ok)
+	}
+	return ok, err
+}
+
+// GetDel pools a value from the cache.
+func (c *InstrumentedImpl) GetDel(ctx context.Context, key string, dest any) (bool, error) {
+	ok, err := c.Cache.GetDel(ctx, key, dest)
+	if err == nil {
+		c.recorder.CacheResult(keyspace(key), ok)
+	}
+	return ok, err
+}
+
+func keyspace(key string) string {
+	name, _, _ := strings.Cut(key, ":")
+	return name
+}
+
+// WithMetrics wraps the cache to count its hits and misses.
+func WithMetrics(c Cache, recorder ResultRecorder) Cache {
+	return &InstrumentedImpl{Cache: c, recorder: recorder}
+}
@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type results map[string]int
+
+func (r results) CacheResult(keyspace string, hit bool) {
+	if hit {
+		r[keyspace+":hit"]++
+	} else {
+		r[keyspace+":miss"]++
+	}
+}
+
+func TestWithMetrics(t *testing.T) {
+	// given
+	ctx := context.Background()
+	recorder := results{}
+	cache := WithMetrics(NewInMem(), recorder)
+	require.NoError(t, cache.Put(ctx, "book:1", "value1", time.Minute))
+
+	// when
+	var value string
+	_, _ = cache.Get(ctx, "book:1", &value)
+	_, _ = cache.Get(ctx, "book:2", &value)
+	_, _ = cache.GetDel(ctx, "otp:1", &value)
+
+	// then
+	require.Equal(t, results{"book:hit": 1, "book:miss": 1, "otp:miss": 1}, recorder)
+}
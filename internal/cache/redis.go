@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisImpl is a cache implementation shared by all replicas.
+type RedisImpl struct {
+	client redis.UniversalClient
+}
+
+// GetDel pools a value from the cache atomically, so a value is returned to a single caller.
+func (r *RedisImpl) GetDel(ctx context.Context, key string, dest any) (bool, error) {
+	data, err := r.client.GetDel(ctx, key).Bytes()
+	return decode(data, err, dest)
+}
+
+// Get gets a value from the cache.
+func (r *RedisImpl) Get(ctx context.Context, key string, dest any) (bool, error) {
+	data, err := r.client.Get(ctx, key).Bytes()
+	return decode(data, err, dest)
+}
+
+// Put sets a value in the cache.
+func (r *RedisImpl) Put(ctx context.Context, key string, value any, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return r.client.Set(ctx, key, data, ttl).Err()
+}
+
+// Del deletes values from the cache.
+func (r *RedisImpl) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return r.client.Del(ctx, keys...).Err()
+}
+
+// Incr increments a counter atomically, the ttl is set when the counter is created.
+// The counter is created by SET NX EX because EXPIRE NX needs Redis 7.
+func (r *RedisImpl) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	var incr *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SetNX(ctx, key, 0, ttl)
+		incr = pipe.Incr(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
+
+// Close closes the connections to redis.
+func (r *RedisImpl) Close() error {
+	return r.client.Close()
+}
+
+// decode treats a missing key as a miss and decodes a found value into dest.
+func decode(data []byte, err error, dest any) (bool, error) {
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal(data, dest)
+}
+
+// NewRedis creates a new redis cache instance from a redis:// or rediss:// URL.
+func NewRedis(url string) (Cache, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRedisWithClient(redis.NewClient(opts)), nil
+}
+
+// NewRedisWithClient creates a new redis cache instance on top of the client.
+func NewRedisWithClient(client redis.UniversalClient) Cache {
+	return &RedisImpl{client: client}
+}
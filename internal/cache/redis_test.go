@@ -0,0 +1,185 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedis(t *testing.T) (Cache, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	cache, err := NewRedis("redis://" + server.Addr())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cache.Close() })
+
+	return cache, server
+}
+
+func TestRedis_GetDel(t *testing.T) {
+	// given
+	ctx := context.Background()
+	cache, _ := newTestRedis(t)
+	require.NoError(t, cache.Put(ctx, "key1", "value1", time.Minute))
+
+	// when
+	var value1, value2 string
+	ok1, err1 := cache.GetDel(ctx, "key1", &value1)
+	ok2, err2 := cache.GetDel(ctx, "key1", &value2)
+
+	// then
+	require.NoError(t, err1)
+	require.True(t, ok1)
+	require.Equal(t, "value1", value1)
+	require.NoError(t, err2)
+	require.False(t, ok2)
+	require.Empty(t, value2)
+}
+
+func TestRedis_GetDel_Concurrent(t *testing.T) {
+	// given
+	ctx := context.Background()
+	cache, _ := newTestRedis(t)
+	require.NoError(t, cache.Put(ctx, "key1", "value1", time.Minute))
+
+	// when
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		found int
+	)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var value string
+			ok, err := cache.GetDel(ctx, "key1", &value)
+			require.NoError(t, err)
+			if ok {
+				mu.Lock()
+				found++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// then
+	require.Equal(t, 1, found)
+}
+
+func TestRedis_Get(t *testing.T) {
+	// given
+	ctx := context.Background()
+	cache, _ := newTestRedis(t)
+	require.NoError(t, cache.Put(ctx, "key1", "value1", time.Minute))
+
+	// when
+	var value1, value2 string
+	ok1, err1 := cache.Get(ctx, "key1", &value1)
+	ok2, err2 := cache.Get(ctx, "key1", &value2)
+
+	// then
+	require.NoError(t, err1)
+	require.True(t, ok1)
+	require.Equal(t, "value1", value1)
+	require.NoError(t, err2)
+	require.True(t, ok2)
+	require.Equal(t, "value1", value2)
+}
+
+func TestRedis_Del(t *testing.T) {
+	// given
+	ctx := context.Background()
+	cache, server := newTestRedis(t)
+	require.NoError(t, cache.Put(ctx, "key1", "value1", time.Minute))
+	require.NoError(t, cache.Put(ctx, "key2", "value2", time.Minute))
+
+	// when
+	err := cache.Del(ctx, "key1", "key2")
+
+	// then
+	require.NoError(t, err)
+	require.False(t, server.Exists("key1"))
+	require.False(t, server.Exists("key2"))
+}
+
+func TestRedis_Get_Expired(t *testing.T) {
+	// given
+	ctx := context.Background()
+	cache, server := newTestRedis(t)
+	require.NoError(t, cache.Put(ctx, "key1", "value1", time.Minute))
+
+	// when
+	server.FastForward(2 * time.Minute)
+	var value string
+	ok, err := cache.Get(ctx, "key1", &value)
+
+	// then
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Empty(t, value)
+}
+
+func TestRedis_Get_Unavailable(t *testing.T) {
+	// given
+	ctx := context.Background()
+	cache, server := newTestRedis(t)
+	server.Close()
+
+	// when
+	var value string
+	ok, err := cache.Get(ctx, "key1", &value)
+
+	// then
+	require.Error(t, err)
+	require.False(t, ok)
+}
+
+func TestNewRedis_InvalidURL(t *testing.T) {
+	// when
+	cache, err := NewRedis("localhost:6379")
+
+	// then
+	require.Error(t, err)
+	require.Nil(t, cache)
+}
+
+func TestRedis_Incr(t *testing.T) {
+	// given
+	ctx := context.Background()
+	cache, server := newTestRedis(t)
+
+	// when
+	counter1, err1 := cache.Incr(ctx, "key1", time.Minute)
+	server.FastForward(30 * time.Second)
+	counter2, err2 := cache.Incr(ctx, "key1", time.Minute)
+
+	// then
+	require.NoError(t, err1)
+	require.Equal(t, int64(1), counter1)
+	require.NoError(t, err2)
+	require.Equal(t, int64(2), counter2)
+	require.Equal(t, 30*time.Second, server.TTL("key1"))
+}
+
+func TestRedis_Incr_Expired(t *testing.T) {
+	// given
+	ctx := context.Background()
+	cache, server := newTestRedis(t)
+	_, err := cache.Incr(ctx, "key1", time.Minute)
+	require.NoError(t, err)
+
+	// when
+	server.FastForward(2 * time.Minute)
+	counter, err := cache.Incr(ctx, "key1", time.Minute)
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, int64(1), counter)
+}
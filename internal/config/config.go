@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log"
 	"log/slog"
+	"net/netip"
+	"strings"
 	"sync"
 	"time"
 
@@ -25,7 +27,14 @@ type Config struct {
 		JSON  bool
 	}
 	JWT struct {
-		Secret   []byte
+		Secret         []byte
+		Duration       time.Duration
+		Algorithm      string
+		KeyID          string
+		PrivateKeyFile string
+		PublicKeyFiles []string
+	}
+	RefreshToken struct {
 		Duration time.Duration
 	}
 	SMTP struct {
@@ -33,10 +42,49 @@ type Config struct {
 		Port     uint16
 		Username string
 		Password string
+		Timeout  time.Duration
+		From     string
+		FromName string
 	}
 	Cache struct {
-		ResetPass time.Duration
-		Activate  time.Duration
+		ResetPass   time.Duration
+		Activate    time.Duration
+		ChangeEmail time.Duration
+		Backend     string
+		RedisURL    string
+		ReadTTL     time.Duration
+	}
+	OTP struct {
+		MaxAttempts int64
+		Lockout     time.Duration
+	}
+	TOTP struct {
+		Issuer        string
+		EncryptionKey string
+		ChallengeTTL  time.Duration
+		RecoveryCodes int
+	}
+	Password struct {
+		MinLength        int
+		MinClasses       int
+		CommonFile       string
+		CommonTop        int
+		BreachedFile     string
+		BreachedMinCount int
+	}
+	Signin struct {
+		Window         time.Duration
+		FreeAttempts   int64
+		IPFreeAttempts int64
+		Delay          time.Duration
+		MaxDelay       time.Duration
+		LockAttempts   int64
+		LockDuration   time.Duration
+	}
+	RateLimit struct {
+		Backend string
+		Auth    RateLimitPolicy
+		API     RateLimitPolicy
 	}
 	Domain      string
 	ServerProps struct {
@@ -45,8 +93,41 @@ type Config struct {
 		WriteTimeout         time.Duration
 		IdleTimeout          time.Duration
 		CancelContextTimeout time.Duration
+		TrustedProxies       []netip.Prefix
+	}
+	MetricsPort string
+	Tracing     struct {
+		Exporter    string
+		SampleRatio float64
+	}
+	Outbox struct {
+		PollInterval time.Duration
+		BatchSize    int
+		MaxAttempts  int
+		Backoff      time.Duration
+		MaxBackoff   time.Duration
+		Lease        time.Duration
+	}
+	Health struct {
+		Timeout       time.Duration
+		CheckSMTP     bool
+		ShutdownDelay time.Duration
 	}
 	SnowflakeNode int64
+	Trash         struct {
+		Retention     time.Duration
+		PurgeInterval time.Duration
+	}
+	Export struct {
+		Currency   string
+		SenderName string
+	}
+}
+
+// RateLimitPolicy allows Limit requests per Period with bursts of up to Limit requests, a zero Limit disables it.
+type RateLimitPolicy struct {
+	Limit  int
+	Period time.Duration
 }
 
 type envs struct {
@@ -59,19 +140,74 @@ type envs struct {
 	DBLogLevel           string        `env:"DB_LOG_LEVEL" envDefault:"warn"`
 	LogLevel             string        `env:"LOG_LEVEL" envDefault:"info"`
 	LogJSON              bool          `env:"LOG_JSON" envDefault:"true"`
-	JWTSecret            string        `env:"JWT_SECRET,required,notEmpty"`
-	JWTDuration          time.Duration `env:"JWT_DURATION" envDefault:"48h"`
+	JWTSecret            string        `env:"JWT_SECRET"`
+	JWTDuration          time.Duration `env:"JWT_DURATION" envDefault:"15m"`
+	JWTAlgorithm         string        `env:"JWT_ALGORITHM" envDefault:"HS256"`
+	JWTKeyID             string        `env:"JWT_KEY_ID"`
+	JWTPrivateKeyFile    string        `env:"JWT_PRIVATE_KEY_FILE"`
+	JWTPublicKeyFiles    []string      `env:"JWT_PUBLIC_KEY_FILES" envSeparator:","`
+	RefreshTokenDuration time.Duration `env:"REFRESH_TOKEN_DURATION" envDefault:"720h"`
 	SMTPHost             string        `env:"SMTP_HOST,required,notEmpty"`
 	SMTPPort             uint16        `env:"SMTP_PORT,required,notEmpty"`
 	SMTPUser             string        `env:"SMTP_USER,required,notEmpty"`
 	SMTPPass             string        `env:"SMTP_PASS,required,notEmpty"`
+	SMTPTimeout          time.Duration `env:"SMTP_TIMEOUT" envDefault:"10s"`
+	SMTPFrom             string        `env:"SMTP_FROM"`
+	SMTPFromName         string        `env:"SMTP_FROM_NAME" envDefault:"Book Catalog"`
 	DOMAIN               string        `env:"DOMAIN,required,notEmpty"`
 	ServerPort           uint16        `env:"SERVER_PORT,required,notEmpty"`
+	MetricsPort          uint16        `env:"METRICS_PORT" envDefault:"9090"`
+	TracingExporter      string        `env:"TRACING_EXPORTER" envDefault:"none"`
+	TracingSampleRatio   float64       `env:"TRACING_SAMPLE_RATIO" envDefault:"1"`
 	ReadTimeout          time.Duration `env:"READ_TIMEOUT" envDefault:"5s"`
 	WriteTimeout         time.Duration `env:"WRITE_TIMEOUT" envDefault:"10s"`
 	IdleTimeout          time.Duration `env:"IDLE_TIMEOUT" envDefault:"15s"`
 	CancelContextTimeout time.Duration `env:"CANCEL_CONTEXT_TIMEOUT" envDefault:"30s"`
+	TrustedProxies       []string      `env:"TRUSTED_PROXIES" envSeparator:","`
 	SnowflakeNode        int64         `env:"SNOWFLAKE_NODE" envDefault:"1"`
+	TrashRetentionDays   uint          `env:"TRASH_RETENTION_DAYS" envDefault:"30"`
+	TrashPurgeInterval   time.Duration `env:"TRASH_PURGE_INTERVAL" envDefault:"1h"`
+	ExportCurrency       string        `env:"EXPORT_CURRENCY" envDefault:"USD"`
+	ExportSenderName     string        `env:"EXPORT_SENDER_NAME" envDefault:"Book Catalog"`
+	CacheBackend         string        `env:"CACHE_BACKEND" envDefault:"memory"`
+	CacheRedisURL        string        `env:"CACHE_REDIS_URL" envDefault:"redis://localhost:6379/0"`
+	CacheReadTTL         time.Duration `env:"CACHE_READ_TTL" envDefault:"5m"`
+	OTPActivationTTL     time.Duration `env:"OTP_ACTIVATION_TTL" envDefault:"48h"`
+	OTPResetPasswordTTL  time.Duration `env:"OTP_RESET_PASSWORD_TTL" envDefault:"1h"`
+	OTPChangeEmailTTL    time.Duration `env:"OTP_CHANGE_EMAIL_TTL" envDefault:"1h"`
+	OTPMaxAttempts       int64         `env:"OTP_MAX_ATTEMPTS" envDefault:"5"`
+	OTPLockout           time.Duration `env:"OTP_LOCKOUT" envDefault:"15m"`
+	TOTPIssuer           string        `env:"TOTP_ISSUER" envDefault:"Book Catalog"`
+	TOTPEncryptionKey    string        `env:"TOTP_ENCRYPTION_KEY"`
+	TOTPChallengeTTL     time.Duration `env:"TOTP_CHALLENGE_TTL" envDefault:"5m"`
+	TOTPRecoveryCodes    int           `env:"TOTP_RECOVERY_CODES" envDefault:"10"`
+	PasswordMinLength    int           `env:"PASSWORD_MIN_LENGTH" envDefault:"8"`
+	PasswordMinClasses   int           `env:"PASSWORD_MIN_CLASSES" envDefault:"3"`
+	PasswordCommonFile   string        `env:"PASSWORD_COMMON_FILE"`
+	PasswordCommonTop    int           `env:"PASSWORD_COMMON_TOP" envDefault:"10000"`
+	PasswordBreachedFile string        `env:"PASSWORD_BREACHED_FILE"`
+	PasswordBreachedMin  int           `env:"PASSWORD_BREACHED_MIN_COUNT" envDefault:"1"`
+	SigninWindow         time.Duration `env:"SIGNIN_FAILURE_WINDOW" envDefault:"15m"`
+	SigninFreeAttempts   int64         `env:"SIGNIN_FREE_ATTEMPTS" envDefault:"3"`
+	SigninIPFreeAttempts int64         `env:"SIGNIN_IP_FREE_ATTEMPTS" envDefault:"20"`
+	SigninDelay          time.Duration `env:"SIGNIN_DELAY" envDefault:"1s"`
+	SigninMaxDelay       time.Duration `env:"SIGNIN_MAX_DELAY" envDefault:"1m"`
+	SigninLockAttempts   int64         `env:"SIGNIN_LOCK_ATTEMPTS" envDefault:"10"`
+	SigninLockDuration   time.Duration `env:"SIGNIN_LOCK_DURATION" envDefault:"30m"`
+	RateLimitBackend     string        `env:"RATE_LIMIT_BACKEND" envDefault:"memory"`
+	RateLimitAuthLimit   int           `env:"RATE_LIMIT_AUTH_LIMIT" envDefault:"10"`
+	RateLimitAuthPeriod  time.Duration `env:"RATE_LIMIT_AUTH_PERIOD" envDefault:"1m"`
+	RateLimitAPILimit    int           `env:"RATE_LIMIT_API_LIMIT" envDefault:"300"`
+	RateLimitAPIPeriod   time.Duration `env:"RATE_LIMIT_API_PERIOD" envDefault:"1m"`
+	OutboxPollInterval   time.Duration `env:"OUTBOX_POLL_INTERVAL" envDefault:"1s"`
+	OutboxBatchSize      int           `env:"OUTBOX_BATCH_SIZE" envDefault:"10"`
+	OutboxMaxAttempts    int           `env:"OUTBOX_MAX_ATTEMPTS" envDefault:"8"`
+	OutboxBackoff        time.Duration `env:"OUTBOX_BACKOFF" envDefault:"10s"`
+	OutboxMaxBackoff     time.Duration `env:"OUTBOX_MAX_BACKOFF" envDefault:"1h"`
+	OutboxLease          time.Duration `env:"OUTBOX_LEASE" envDefault:"1m"`
+	HealthTimeout        time.Duration `env:"HEALTH_TIMEOUT" envDefault:"2s"`
+	HealthCheckSMTP      bool          `env:"HEALTH_CHECK_SMTP" envDefault:"false"`
+	ShutdownDelay        time.Duration `env:"SHUTDOWN_DELAY" envDefault:"0s"`
 }
 
 // MustGet loads the configuration from environment variables.
@@ -92,6 +228,17 @@ func MustGet() *Config {
 		e.server()
 		e.domain()
 		e.snowflake()
+		e.trash()
+		e.export()
+		e.cache()
+		e.otp()
+		e.totp()
+		e.password()
+		e.signin()
+		e.rateLimit()
+		e.tracing()
+		e.health()
+		e.outbox()
 	})
 
 	return &config
@@ -99,10 +246,36 @@ func MustGet() *Config {
 
 func (e *envs) server() {
 	config.ServerProps.Port = fmt.Sprintf(":%d", e.ServerPort)
+	config.MetricsPort = fmt.Sprintf(":%d", e.MetricsPort)
 	config.ServerProps.ReadTimeout = e.ReadTimeout
 	config.ServerProps.WriteTimeout = e.WriteTimeout
 	config.ServerProps.IdleTimeout = e.IdleTimeout
 	config.ServerProps.CancelContextTimeout = e.CancelContextTimeout
+	config.ServerProps.TrustedProxies = make([]netip.Prefix, 0, len(e.TrustedProxies))
+	for _, proxy := range e.TrustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		prefix, err := parsePrefix(proxy)
+		if err != nil {
+			log.Fatalf("TRUSTED_PROXIES: %v", err)
+		}
+		config.ServerProps.TrustedProxies = append(config.ServerProps.TrustedProxies, prefix)
+	}
+}
+
+// parsePrefix parses CIDR or a single IP
+func parsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		return prefix.Masked(), err
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()), nil
 }
 
 func (e *envs) sendMail() {
@@ -110,11 +283,22 @@ func (e *envs) sendMail() {
 	config.SMTP.Port = e.SMTPPort
 	config.SMTP.Username = e.SMTPUser
 	config.SMTP.Password = e.SMTPPass
+	config.SMTP.Timeout = e.SMTPTimeout
+	config.SMTP.From = e.SMTPFrom
+	if config.SMTP.From == "" {
+		config.SMTP.From = e.SMTPUser
+	}
+	config.SMTP.FromName = e.SMTPFromName
 }
 
 func (e *envs) jwt() {
 	config.JWT.Secret = []byte(e.JWTSecret)
 	config.JWT.Duration = e.JWTDuration
+	config.JWT.Algorithm = e.JWTAlgorithm
+	config.JWT.KeyID = e.JWTKeyID
+	config.JWT.PrivateKeyFile = e.JWTPrivateKeyFile
+	config.JWT.PublicKeyFiles = e.JWTPublicKeyFiles
+	config.RefreshToken.Duration = e.RefreshTokenDuration
 }
 
 func (e *envs) logger() {
@@ -150,3 +334,83 @@ func (e *envs) logLevelDB() {
 func (e *envs) snowflake() {
 	config.SnowflakeNode = e.SnowflakeNode
 }
+
+func (e *envs) trash() {
+	config.Trash.Retention = time.Duration(e.TrashRetentionDays) * 24 * time.Hour
+	config.Trash.PurgeInterval = e.TrashPurgeInterval
+}
+
+func (e *envs) export() {
+	config.Export.Currency = e.ExportCurrency
+	config.Export.SenderName = e.ExportSenderName
+}
+
+func (e *envs) cache() {
+	config.Cache.Backend = e.CacheBackend
+	config.Cache.RedisURL = e.CacheRedisURL
+	config.Cache.ReadTTL = e.CacheReadTTL
+}
+
+func (e *envs) otp() {
+	config.Cache.Activate = e.OTPActivationTTL
+	config.Cache.ResetPass = e.OTPResetPasswordTTL
+	config.Cache.ChangeEmail = e.OTPChangeEmailTTL
+	config.OTP.MaxAttempts = e.OTPMaxAttempts
+	config.OTP.Lockout = e.OTPLockout
+}
+
+func (e *envs) totp() {
+	config.TOTP.Issuer = e.TOTPIssuer
+	config.TOTP.EncryptionKey = e.TOTPEncryptionKey
+	config.TOTP.ChallengeTTL = e.TOTPChallengeTTL
+	config.TOTP.RecoveryCodes = e.TOTPRecoveryCodes
+}
+
+func (e *envs) password() {
+	config.Password.MinLength = e.PasswordMinLength
+	config.Password.MinClasses = e.PasswordMinClasses
+	config.Password.CommonFile = e.PasswordCommonFile
+	config.Password.CommonTop = e.PasswordCommonTop
+	config.Password.BreachedFile = e.PasswordBreachedFile
+	config.Password.BreachedMinCount = e.PasswordBreachedMin
+}
+
+func (e *envs) signin() {
+	config.Signin.Window = e.SigninWindow
+	config.Signin.FreeAttempts = e.SigninFreeAttempts
+	config.Signin.IPFreeAttempts = e.SigninIPFreeAttempts
+	config.Signin.Delay = e.SigninDelay
+	config.Signin.MaxDelay = e.SigninMaxDelay
+	config.Signin.LockAttempts = e.SigninLockAttempts
+	config.Signin.LockDuration = e.SigninLockDuration
+}
+
+func (e *envs) rateLimit() {
+	config.RateLimit.Backend = e.RateLimitBackend
+	config.RateLimit.Auth = RateLimitPolicy{Limit: e.RateLimitAuthLimit, Period: e.RateLimitAuthPeriod}
+	config.RateLimit.API = RateLimitPolicy{Limit: e.RateLimitAPILimit, Period: e.RateLimitAPIPeriod}
+}
+
+func (e *envs) tracing() {
+	config.Tracing.Exporter = e.TracingExporter
+	config.Tracing.SampleRatio = e.TracingSampleRatio
+}
+
+func (e *envs) health() {
+	config.Health.Timeout = e.HealthTimeout
+	config.Health.CheckSMTP = e.HealthCheckSMTP
+	config.Health.ShutdownDelay = e.ShutdownDelay
+}
+
+func (e *envs) outbox() {
+	config.Outbox.PollInterval = e.OutboxPollInterval
+	config.Outbox.BatchSize = e.OutboxBatchSize
+	config.Outbox.MaxAttempts = e.OutboxMaxAttempts
+	config.Outbox.Backoff = e.OutboxBackoff
+	config.Outbox.MaxBackoff = e.OutboxMaxBackoff
+	config.Outbox.Lease = e.OutboxLease
+	// a mail is sent only if it can be delivered within the lease, so a shorter lease delivers none
+	if e.OutboxLease <= e.SMTPTimeout {
+		log.Fatalf("OUTBOX_LEASE %s must be longer than SMTP_TIMEOUT %s", e.OutboxLease, e.SMTPTimeout)
+	}
+}
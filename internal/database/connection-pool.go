@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // ConnPool is a connection pool interface.
@@ -10,5 +11,7 @@ import (
 //go:generate mockgen -destination=../../test/mock/database/mock-conn_pool.go -package=mock . ConnPool
 type ConnPool interface {
 	Begin(ctx context.Context) (pgx.Tx, error)
+	Ping(ctx context.Context) error
+	Stat() *pgxpool.Stat
 	Close()
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/vlaship/book-catalog-go/internal/apperr"
+	"strings"
 )
 
 // GetErrorByCode returns error by pgErr.Code
@@ -25,6 +26,20 @@ func GetErrorByCode(err error) error {
 	return err
 }
 
+// IsDataError reports whether the error is caused by the written data, i.e. an integrity constraint
+// violation (class 23) or a data exception (class 22), rather than by the database being unavailable
+func IsDataError(err error) bool {
+	if errors.Is(err, apperr.ErrAlreadyExists) || errors.Is(err, apperr.ErrNoFoundForeignKey) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return strings.HasPrefix(pgErr.Code, "22") || strings.HasPrefix(pgErr.Code, "23")
+	}
+
+	return false
+}
+
 // CheckAffectedRows checks affected rows
 func CheckAffectedRows(tag pgconn.CommandTag) error {
 	if tag.RowsAffected() == 0 {
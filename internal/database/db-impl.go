@@ -6,9 +6,11 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/vlaship/book-catalog-go/internal/config"
 	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/tracing"
 	"time"
 
 	zerologadapter "github.com/jackc/pgx-zerolog"
+	"github.com/jackc/pgx/v5/multitracer"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/tracelog"
 )
@@ -47,10 +49,14 @@ func New(cfg *config.Config, log logger.Logger) (ConnPool, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid db log level: [%s]", cfg.LogLevelDB)
 	}
-	dbCfg.ConnConfig.Tracer = &tracelog.TraceLog{
-		Logger:   zerologadapter.NewLogger(log.New("pgx").Logger()),
-		LogLevel: logLevel,
-	}
+	// log queries and run every query in a span
+	dbCfg.ConnConfig.Tracer = multitracer.New(
+		&tracelog.TraceLog{
+			Logger:   zerologadapter.NewLogger(log.New("pgx").Logger()),
+			LogLevel: logLevel,
+		},
+		tracing.PgxTracer{},
+	)
 
 	ctx := context.Background()
 	log.Trc().Msg("connect to database")
@@ -74,6 +80,16 @@ func (cp *ConnPoolImpl) Begin(ctx context.Context) (pgx.Tx, error) {
 	return cp.pool.Begin(ctx)
 }
 
+// Ping checks a connection of the pool is alive
+func (cp *ConnPoolImpl) Ping(ctx context.Context) error {
+	return cp.pool.Ping(ctx)
+}
+
+// Stat returns the statistics of the connection pool
+func (cp *ConnPoolImpl) Stat() *pgxpool.Stat {
+	return cp.pool.Stat()
+}
+
 // Close closes the connection pool
 func (cp *ConnPoolImpl) Close() {
 	cp.pool.Close()
@@ -1,11 +1,13 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"fmt"
 	"github.com/vlaship/book-catalog-go/internal/config"
 	"github.com/vlaship/book-catalog-go/internal/logger"
+	"io/fs"
 
 	_ "github.com/jackc/pgx/v5/stdlib" //nolint:revive // required for goose
 	"github.com/pressly/goose/v3"
@@ -42,3 +44,49 @@ func GooseMigrateDatabase(cfg *config.Config, log logger.Logger) error {
 	log.Trc().Msg("run migrations")
 	return goose.Up(db, "migrations")
 }
+
+// MigrationChecker checks that the database is at the latest embedded migration
+type MigrationChecker struct {
+	provider *goose.Provider
+}
+
+// NewMigrationChecker creates a new MigrationChecker instance
+func NewMigrationChecker(cfg *config.Config) (*MigrationChecker, error) {
+	db, err := sql.Open("pgx/v5", cfg.ConnDB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	migrations, err := fs.Sub(embedMigrations, "migrations")
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, migrations)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &MigrationChecker{provider: provider}, nil
+}
+
+// Check fails when migrations are pending
+func (c *MigrationChecker) Check(ctx context.Context) error {
+	current, target, err := c.provider.GetVersions(ctx)
+	if err != nil {
+		return err
+	}
+	if current < target {
+		return fmt.Errorf("database is at version %d, latest migration is %d", current, target)
+	}
+
+	return nil
+}
+
+// Close closes the database connection of the checker
+func (c *MigrationChecker) Close() error {
+	return c.provider.Close()
+}
@@ -0,0 +1,11 @@
+-- +goose Up
+
+-- create indexes for keyset pagination of books
+CREATE INDEX IF NOT EXISTS books_author_id_idx ON catalog.books (author_id, book_id) WHERE deleted = FALSE;
+CREATE INDEX IF NOT EXISTS books_title_idx ON catalog.books (book_title, book_id) WHERE deleted = FALSE;
+CREATE INDEX IF NOT EXISTS books_price_idx ON catalog.books (book_price, book_id) WHERE deleted = FALSE;
+
+-- +goose Down
+DROP INDEX IF EXISTS catalog.books_price_idx;
+DROP INDEX IF EXISTS catalog.books_title_idx;
+DROP INDEX IF EXISTS catalog.books_author_id_idx;
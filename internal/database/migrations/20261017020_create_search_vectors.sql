@@ -0,0 +1,21 @@
+-- +goose Up
+
+-- add full-text search vectors
+ALTER TABLE catalog.books
+    ADD COLUMN IF NOT EXISTS book_tsv TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', book_title), 'A') ||
+        setweight(to_tsvector('english', book_desc), 'B')
+        ) STORED;
+CREATE INDEX IF NOT EXISTS books_tsv_idx ON catalog.books USING GIN (book_tsv);
+
+ALTER TABLE catalog.authors
+    ADD COLUMN IF NOT EXISTS author_tsv TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', author_name), 'A')
+        ) STORED;
+CREATE INDEX IF NOT EXISTS authors_tsv_idx ON catalog.authors USING GIN (author_tsv);
+
+-- +goose Down
+DROP INDEX IF EXISTS catalog.authors_tsv_idx;
+ALTER TABLE catalog.authors DROP COLUMN IF EXISTS author_tsv;
+DROP INDEX IF EXISTS catalog.books_tsv_idx;
+ALTER TABLE catalog.books DROP COLUMN IF EXISTS book_tsv;
@@ -0,0 +1,19 @@
+-- +goose Up
+
+-- create refresh tokens table
+CREATE TABLE IF NOT EXISTS catalog.refresh_tokens
+(
+    token_id   BIGINT PRIMARY KEY                                           NOT NULL,
+    token_hash TEXT UNIQUE                                                  NOT NULL,
+    family_id  BIGINT                                                       NOT NULL,
+    user_id    BIGINT REFERENCES catalog.users (user_id) ON DELETE CASCADE NOT NULL,
+    expires_at TIMESTAMPTZ                                                  NOT NULL,
+    used_at    TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()                                    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS refresh_tokens_family_id_idx ON catalog.refresh_tokens (family_id);
+CREATE INDEX IF NOT EXISTS refresh_tokens_user_id_idx ON catalog.refresh_tokens (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS catalog.refresh_tokens;
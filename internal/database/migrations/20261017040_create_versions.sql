@@ -0,0 +1,30 @@
+-- +goose Up
+
+-- add row versions for optimistic concurrency, maintained on every update
+ALTER TABLE catalog.books ADD COLUMN IF NOT EXISTS version BIGINT DEFAULT 1 NOT NULL;
+ALTER TABLE catalog.authors ADD COLUMN IF NOT EXISTS version BIGINT DEFAULT 1 NOT NULL;
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION catalog.bump_version() RETURNS TRIGGER AS
+$$
+BEGIN
+    NEW.version := OLD.version + 1;
+    NEW.updated_at := now();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER books_bump_version
+    BEFORE UPDATE ON catalog.books
+    FOR EACH ROW EXECUTE FUNCTION catalog.bump_version();
+CREATE TRIGGER authors_bump_version
+    BEFORE UPDATE ON catalog.authors
+    FOR EACH ROW EXECUTE FUNCTION catalog.bump_version();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS authors_bump_version ON catalog.authors;
+DROP TRIGGER IF EXISTS books_bump_version ON catalog.books;
+DROP FUNCTION IF EXISTS catalog.bump_version();
+ALTER TABLE catalog.authors DROP COLUMN IF EXISTS version;
+ALTER TABLE catalog.books DROP COLUMN IF EXISTS version;
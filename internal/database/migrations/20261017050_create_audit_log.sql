@@ -0,0 +1,19 @@
+-- +goose Up
+
+-- create audit log of catalog mutations, user_id has no foreign key so history outlives users
+CREATE TABLE IF NOT EXISTS catalog.audit_log
+(
+    audit_id    BIGINT PRIMARY KEY        NOT NULL,
+    action      TEXT                      NOT NULL,
+    entity_type TEXT                      NOT NULL,
+    entity_id   BIGINT                    NOT NULL,
+    user_id     BIGINT,
+    request_id  TEXT,
+    before      JSONB,
+    after       JSONB,
+    created_at  TIMESTAMPTZ DEFAULT NOW() NOT NULL
+);
+CREATE INDEX IF NOT EXISTS audit_log_entity_idx ON catalog.audit_log (entity_type, entity_id, audit_id DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS catalog.audit_log;
@@ -0,0 +1,15 @@
+-- +goose Up
+
+-- track when rows were soft deleted for trash listing and retention
+ALTER TABLE catalog.books ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE catalog.authors ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+UPDATE catalog.books SET deleted_at = updated_at WHERE deleted = TRUE AND deleted_at IS NULL;
+UPDATE catalog.authors SET deleted_at = updated_at WHERE deleted = TRUE AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS books_deleted_at_idx ON catalog.books (deleted_at) WHERE deleted = TRUE;
+CREATE INDEX IF NOT EXISTS authors_deleted_at_idx ON catalog.authors (deleted_at) WHERE deleted = TRUE;
+
+-- +goose Down
+DROP INDEX IF EXISTS catalog.authors_deleted_at_idx;
+DROP INDEX IF EXISTS catalog.books_deleted_at_idx;
+ALTER TABLE catalog.authors DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE catalog.books DROP COLUMN IF EXISTS deleted_at;
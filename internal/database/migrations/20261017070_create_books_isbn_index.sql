@@ -0,0 +1,29 @@
+-- +goose Up
+-- store ISBN as ISBN-13 without hyphens and spaces
+UPDATE catalog.books SET book_isbn = upper(regexp_replace(book_isbn, '[\s-]', '', 'g'))
+WHERE book_isbn ~ '[\s-]' OR book_isbn ~ 'x';
+-- convert ISBN-10 with a valid check digit only, a malformed ISBN is kept as is to be fixed by hand
+UPDATE catalog.books
+SET book_isbn = '978' || left(book_isbn, 9) || ((10 - (38 + (
+    SELECT sum(substr(book_isbn, i, 1)::INT * CASE WHEN i % 2 = 1 THEN 3 ELSE 1 END)
+    FROM generate_series(1, 9) i
+)) % 10) % 10)::TEXT
+WHERE book_isbn ~ '^[0-9]{9}[0-9X]$' AND (
+    SELECT sum(CASE WHEN substr(book_isbn, i, 1) = 'X' THEN 10 ELSE substr(book_isbn, i, 1)::INT END * (11 - i))
+    FROM generate_series(1, 10) i
+) % 11 = 0;
+-- move later duplicates to trash and record their deletion, they can be restored once their ISBN is fixed,
+-- audit IDs are snowflake IDs of node 0 made of the migration time and the row number
+WITH trashed AS (
+    UPDATE catalog.books b SET deleted = TRUE, deleted_at = now()
+    WHERE b.deleted = FALSE AND EXISTS (SELECT 1 FROM catalog.books o WHERE o.book_isbn = b.book_isbn AND o.deleted = FALSE AND (o.created_at, o.book_id) < (b.created_at, b.book_id))
+    RETURNING b.book_id, b.book_title, b.book_desc, b.book_isbn, b.author_id, b.book_price
+)
+INSERT INTO catalog.audit_log (audit_id, action, entity_type, entity_id, before)
+SELECT ((floor(extract(EPOCH FROM clock_timestamp()) * 1000)::BIGINT - 1288834974657) << 22) + row_number() OVER (ORDER BY book_id),
+       'delete', 'book', book_id,
+       jsonb_build_object('title', book_title, 'description', book_desc, 'isbn', book_isbn, 'author_id', author_id, 'price', book_price::TEXT)
+FROM trashed;
+CREATE UNIQUE INDEX IF NOT EXISTS books_isbn_uidx ON catalog.books (book_isbn) WHERE deleted = FALSE;
+-- +goose Down
+DROP INDEX IF EXISTS catalog.books_isbn_uidx;
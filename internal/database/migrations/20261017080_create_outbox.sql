@@ -0,0 +1,21 @@
+-- +goose Up
+
+-- create outbox of messages delivered by the background worker, rows are queued in the transaction
+-- of the change that triggers them, the payload of a delivered message is cleared
+CREATE TABLE IF NOT EXISTS catalog.outbox
+(
+    outbox_id       BIGINT PRIMARY KEY                  NOT NULL,
+    kind            TEXT                                NOT NULL,
+    recipient       TEXT                                NOT NULL,
+    payload         JSONB,
+    status          TEXT        DEFAULT 'pending'       NOT NULL,
+    attempts        INT         DEFAULT 0               NOT NULL,
+    last_error      TEXT,
+    next_attempt_at TIMESTAMPTZ DEFAULT NOW()           NOT NULL,
+    created_at      TIMESTAMPTZ DEFAULT NOW()           NOT NULL,
+    sent_at         TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON catalog.outbox (next_attempt_at) WHERE status = 'pending';
+
+-- +goose Down
+DROP TABLE IF EXISTS catalog.outbox;
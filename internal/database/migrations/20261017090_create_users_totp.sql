@@ -0,0 +1,14 @@
+-- +goose Up
+
+-- two-factor authentication of users, the secret is encrypted by the application and the recovery codes are hashed,
+-- the last step keeps a code from being used twice
+ALTER TABLE catalog.users ADD COLUMN IF NOT EXISTS totp_secret BYTEA;
+ALTER TABLE catalog.users ADD COLUMN IF NOT EXISTS totp_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE catalog.users ADD COLUMN IF NOT EXISTS totp_recovery_codes TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE catalog.users ADD COLUMN IF NOT EXISTS totp_last_step BIGINT NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE catalog.users DROP COLUMN IF EXISTS totp_last_step;
+ALTER TABLE catalog.users DROP COLUMN IF EXISTS totp_recovery_codes;
+ALTER TABLE catalog.users DROP COLUMN IF EXISTS totp_enabled;
+ALTER TABLE catalog.users DROP COLUMN IF EXISTS totp_secret;
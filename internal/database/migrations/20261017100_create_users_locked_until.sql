@@ -0,0 +1,7 @@
+-- +goose Up
+
+-- users are temporarily locked after too many failed signins
+ALTER TABLE catalog.users ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE catalog.users DROP COLUMN IF EXISTS locked_until;
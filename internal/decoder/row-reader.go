@@ -0,0 +1,222 @@
+package decoder
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/vlaship/book-catalog-go/internal/apperr"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const (
+	textCSV           = "text/csv"
+	applicationNDJSON = "application/x-ndjson"
+	applicationJSONL  = "application/jsonl"
+)
+
+// RowReader reads rows of a streamed request body one by one
+type RowReader interface {
+	// Next decodes the next row into dst and returns its line number,
+	// it returns io.EOF after the last row, a row error does not stop reading
+	Next(dst any) (int, error)
+}
+
+// RowError is an error of a single row, reading can continue after it
+type RowError struct {
+	Line int
+	Err  error
+}
+
+// Error implement error interface
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap implement error interface
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// NewRowReader creates a row reader of CSV or NDJSON body by Content-Type header,
+// the body is read lazily and limited by maxBytes
+func NewRowReader(w http.ResponseWriter, r *http.Request, maxBytes int64) (RowReader, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get(headerContentType))
+	if err != nil {
+		return nil, apperr.ErrUnsupportedMediaType.WithFunc(apperr.WithDetail(headerContentType + " header is missing or invalid"))
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxBytes)
+
+	switch mediaType {
+	case textCSV:
+		return NewCSVReader(body)
+	case applicationNDJSON, applicationJSONL:
+		return NewNDJSONReader(body), nil
+	default:
+		return nil, apperr.ErrUnsupportedMediaType.WithFunc(apperr.WithDetail(
+			fmt.Sprintf("%s header is not one of %s, %s, %s", headerContentType, textCSV, applicationNDJSON, applicationJSONL)))
+	}
+}
+
+// CSVReader reads CSV rows with a header, columns are matched to json tags of dst by header names
+type CSVReader struct {
+	r      *csv.Reader
+	header []string
+}
+
+// NewCSVReader creates a CSV reader and reads the header row
+func NewCSVReader(r io.Reader) (*CSVReader, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, apperr.ErrDecodingRequest.WithFunc(apperr.WithDetail("CSV header is missing"))
+		}
+		return nil, readError(err)
+	}
+
+	cols := make([]string, len(header))
+	for i, h := range header {
+		cols[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+	}
+
+	return &CSVReader{r: cr, header: cols}, nil
+}
+
+// Next implements RowReader
+func (c *CSVReader) Next(dst any) (int, error) {
+	record, err := c.r.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return 0, io.EOF
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return parseErr.StartLine, &RowError{Line: parseErr.StartLine, Err: parseErr.Err}
+		}
+		return 0, readError(err)
+	}
+	line, _ := c.r.FieldPos(0)
+
+	row := make(map[string]string, len(c.header))
+	for i, col := range c.header {
+		if v := strings.TrimSpace(record[i]); v != "" {
+			row[col] = v
+		}
+	}
+
+	data, err := json.Marshal(row)
+	if err != nil {
+		return line, &RowError{Line: line, Err: err}
+	}
+	if err = json.Unmarshal(data, dst); err != nil {
+		return line, &RowError{Line: line, Err: rowDecodeError(err)}
+	}
+
+	return line, nil
+}
+
+// NDJSONReader reads one JSON object per line, blank lines are skipped
+type NDJSONReader struct {
+	s    *bufio.Scanner
+	r    *stickyReader
+	line int
+	// unterminated is set when the last scanned line has no line break
+	unterminated bool
+}
+
+// NewNDJSONReader creates an NDJSON reader, a line may not be longer than one megabyte
+func NewNDJSONReader(r io.Reader) *NDJSONReader {
+	sr := &stickyReader{r: r}
+	s := bufio.NewScanner(sr)
+	s.Buffer(make([]byte, 0, 64*1024), megabyte)
+
+	n := &NDJSONReader{s: s, r: sr}
+	s.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		n.unterminated = atEOF && bytes.IndexByte(data, '\n') < 0
+		return bufio.ScanLines(data, atEOF)
+	})
+
+	return n
+}
+
+// Next implements RowReader
+func (n *NDJSONReader) Next(dst any) (int, error) {
+	for n.s.Scan() {
+		n.line++
+
+		// the scanner returns the cut last line when reading fails
+		if n.unterminated && n.r.err != nil {
+			return n.line, readError(n.r.err)
+		}
+
+		data := bytes.TrimSpace(n.s.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(data, dst); err != nil {
+			return n.line, &RowError{Line: n.line, Err: rowDecodeError(err)}
+		}
+
+		return n.line, nil
+	}
+
+	if err := n.s.Err(); err != nil {
+		return n.line + 1, readError(err)
+	}
+
+	return n.line, io.EOF
+}
+
+// stickyReader keeps the first error of reading other than io.EOF
+type stickyReader struct {
+	r   io.Reader
+	err error
+}
+
+// Read implements io.Reader
+func (s *stickyReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) && s.err == nil {
+		s.err = err
+	}
+	return n, err
+}
+
+// readError converts an error of reading the body, it stops reading
+func readError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return apperr.ErrDecodingRequest.WithFunc(apperr.WithDetail(
+			fmt.Sprintf("Request body must not be larger than %d bytes", maxBytesErr.Limit)))
+	case errors.Is(err, bufio.ErrTooLong):
+		return apperr.ErrDecodingRequest.WithFunc(apperr.WithDetail("Request body contains a too long line"))
+	default:
+		return apperr.ErrDecodingRequest.WithFunc(apperr.WithDetail(err.Error()))
+	}
+}
+
+// rowDecodeError makes the error of decoding a row readable
+func rowDecodeError(err error) error {
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &syntaxError):
+		return fmt.Errorf("badly-formed JSON (at position %d)", syntaxError.Offset)
+	case errors.As(err, &typeError):
+		return fmt.Errorf("invalid value for the %q field", typeError.Field)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,135 @@
+package decoder
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vlaship/book-catalog-go/internal/apperr"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type row struct {
+	Title string      `json:"title"`
+	Price json.Number `json:"price"`
+}
+
+func readAll(t *testing.T, rr RowReader) ([]row, map[int]error) {
+	t.Helper()
+
+	var rows []row
+	errs := map[int]error{}
+	for {
+		var dst row
+		line, err := rr.Next(&dst)
+		if errors.Is(err, io.EOF) {
+			return rows, errs
+		}
+		var rowErr *RowError
+		if err != nil {
+			require.ErrorAs(t, err, &rowErr)
+			errs[line] = err
+			continue
+		}
+		rows = append(rows, dst)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	// given
+	body := "\ufeffTitle, price,unknown\nGo,15.99,x\n\"Rust, 2nd\",\" 20 \",y\nbroken,1\n"
+
+	// when
+	rr, err := NewCSVReader(strings.NewReader(body))
+	require.NoError(t, err)
+	rows, errs := readAll(t, rr)
+
+	// then
+	assert.Equal(t, []row{{Title: "Go", Price: "15.99"}, {Title: "Rust, 2nd", Price: "20"}}, rows)
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs, 4)
+}
+
+func TestCSVReaderFailMissingHeader(t *testing.T) {
+	// given
+	body := ""
+
+	// when
+	_, err := NewCSVReader(strings.NewReader(body))
+
+	// then
+	assert.Truef(t, errors.Is(err, apperr.ErrDecodingRequest), "Expected ErrDecodingRequest, got %v", err)
+}
+
+func TestNDJSONReader(t *testing.T) {
+	// given
+	body := `{"title":"Go","price":15.99}` + "\n\n" + `{"title":` + "\n" + `{"title":"Rust","price":"20"}`
+
+	// when
+	rows, errs := readAll(t, NewNDJSONReader(strings.NewReader(body)))
+
+	// then
+	assert.Equal(t, []row{{Title: "Go", Price: "15.99"}, {Title: "Rust", Price: "20"}}, rows)
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs, 3)
+}
+
+func TestNewRowReaderByContentType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        RowReader
+	}{
+		{"text/csv; charset=utf-8", &CSVReader{}},
+		{"application/x-ndjson", &NDJSONReader{}},
+		{"application/jsonl", &NDJSONReader{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			// given
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("title\n"))
+			r.Header.Set(headerContentType, tt.contentType)
+
+			// when
+			rr, err := NewRowReader(httptest.NewRecorder(), r, megabyte)
+
+			// then
+			require.NoError(t, err)
+			assert.IsType(t, tt.want, rr)
+		})
+	}
+}
+
+func TestNewRowReaderFailUnsupportedContentType(t *testing.T) {
+	// given
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(""))
+	r.Header.Set(headerContentType, applicationJSON)
+
+	// when
+	_, err := NewRowReader(httptest.NewRecorder(), r, megabyte)
+
+	// then
+	assert.Truef(t, errors.Is(err, apperr.ErrUnsupportedMediaType), "Expected ErrUnsupportedMediaType, got %v", err)
+}
+
+func TestNDJSONReaderFailBodyTooLarge(t *testing.T) {
+	// given
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Repeat(`{"title":"Go"}`+"\n", 10)))
+	r.Header.Set(headerContentType, applicationNDJSON)
+	rr, err := NewRowReader(httptest.NewRecorder(), r, 20)
+	require.NoError(t, err)
+
+	// when
+	var dst row
+	_, err = rr.Next(&dst)
+	require.NoError(t, err)
+	_, err = rr.Next(&dst)
+
+	// then
+	assert.Truef(t, errors.Is(err, apperr.ErrDecodingRequest), "Expected ErrDecodingRequest, got %v", err)
+	assert.Contains(t, err.Error(), "must not be larger than 20 bytes")
+}
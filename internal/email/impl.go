@@ -1,32 +1,34 @@
 package email
 
 import (
-	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"github.com/vlaship/book-catalog-go/internal/apperr"
 	"github.com/vlaship/book-catalog-go/internal/config"
-	"html/template"
+	"net"
+	"net/mail"
 	"net/smtp"
-)
-
-const (
-	mime = "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	"time"
 )
 
 // SenderImpl is a send mail implementation.
 type SenderImpl struct {
-	auth smtp.Auth
-	user string
-	host string
-	port uint16
+	auth    smtp.Auth
+	from    mail.Address
+	host    string
+	port    uint16
+	timeout time.Duration
 }
 
 // New sender
 func New(cfg *config.Config) Sender {
 	return &SenderImpl{
-		user: cfg.SMTP.Username,
-		host: cfg.SMTP.Host,
-		port: cfg.SMTP.Port,
+		from:    mail.Address{Name: cfg.SMTP.FromName, Address: cfg.SMTP.From},
+		host:    cfg.SMTP.Host,
+		port:    cfg.SMTP.Port,
+		timeout: cfg.SMTP.Timeout,
 		auth: smtp.PlainAuth(
 			"",
 			cfg.SMTP.Username,
@@ -36,33 +38,106 @@ func New(cfg *config.Config) Sender {
 	}
 }
 
-func (s *SenderImpl) Send(to []string, subj string, tmpl *template.Template, placeHolders any) error {
-	body, err := s.prepareBody(subj, tmpl, placeHolders)
+// Send sends the mail, the whole SMTP session is bounded by ctx and the configured timeout
+func (s *SenderImpl) Send(ctx context.Context, msg *Message) error {
+	body, err := build(s.from, msg, time.Now())
 	if err != nil {
-		return err
+		return apperr.ErrSendMail.WithFunc(apperr.WithDetail(err.Error()))
 	}
 
-	if err := smtp.SendMail(
-		fmt.Sprintf("%s:%d", s.host, s.port),
-		s.auth,
-		s.user,
-		to,
-		body,
-	); err != nil {
+	if err = s.send(ctx, msg.To, body); err != nil {
 		return apperr.ErrSendMail.WithFunc(apperr.WithDetail(err.Error()))
 	}
 	return nil
 }
 
-func (s *SenderImpl) prepareBody(subj string, tmpl *template.Template, placeHolders any) ([]byte, error) {
-	var body bytes.Buffer
-	body.Write([]byte(subj))
-	body.Write([]byte(mime))
+// Ping checks the SMTP server is reachable and greets, it does not authenticate
+func (s *SenderImpl) Ping(ctx context.Context) error {
+	c, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = c.Close() }()
+
+	if err = c.Hello("localhost"); err != nil {
+		return err
+	}
+	return c.Quit()
+}
 
-	err := tmpl.Execute(&body, placeHolders)
+// send does what smtp.SendMail does over a connection with a deadline
+func (s *SenderImpl) send(ctx context.Context, to []string, body []byte) error {
+	c, err := s.dial(ctx)
 	if err != nil {
-		return nil, apperr.ErrExecuteTemplate.WithFunc(apperr.WithDetail(err.Error()))
+		return err
+	}
+	defer func() { _ = c.Close() }()
+
+	if err = c.Hello("localhost"); err != nil {
+		return err
+	}
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err = c.StartTLS(&tls.Config{ServerName: s.host, MinVersion: tls.VersionTLS12}); err != nil {
+			return err
+		}
 	}
+	if s.auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err = c.Auth(s.auth); err != nil {
+			return err
+		}
+	}
+	if err = c.Mail(s.from.Address); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err = c.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(body); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// dial connects to the SMTP server, the connection deadline is the earlier of the ctx deadline and the timeout
+func (s *SenderImpl) dial(ctx context.Context) (*smtp.Client, error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", s.addr())
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
 
-	return body.Bytes(), nil
+func (s *SenderImpl) addr() string {
+	return fmt.Sprintf("%s:%d", s.host, s.port)
 }
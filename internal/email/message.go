@@ -0,0 +1,97 @@
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Message is a mail with a plain text and an HTML alternative of the body
+type Message struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// build builds the RFC 5322 message, the subject is RFC 2047 encoded when it is not ASCII,
+// and the body is multipart/alternative with quoted-printable parts, the preferred HTML part last
+func build(from mail.Address, msg *Message, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+
+	to := make([]string, len(msg.To))
+	for i, addr := range msg.To {
+		to[i] = (&mail.Address{Address: addr}).String()
+	}
+
+	messageID, err := newMessageID(from.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	body := multipart.NewWriter(&buf)
+	header := []struct{ key, value string }{
+		{"From", from.String()},
+		{"To", strings.Join(to, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", msg.Subject)},
+		{"Date", now.Format(time.RFC1123Z)},
+		{"Message-ID", messageID},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": body.Boundary()})},
+	}
+	for _, h := range header {
+		fmt.Fprintf(&buf, "%s: %s\r\n", h.key, h.value)
+	}
+	buf.WriteString("\r\n")
+
+	if err = writePart(body, "text/plain", msg.Text); err != nil {
+		return nil, err
+	}
+	if err = writePart(body, "text/html", msg.HTML); err != nil {
+		return nil, err
+	}
+	if err = body.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writePart(body *multipart.Writer, contentType, content string) error {
+	part, err := body.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {mime.FormatMediaType(contentType, map[string]string{"charset": "utf-8"})},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+
+	w := quotedprintable.NewWriter(part)
+	if _, err = w.Write([]byte(content)); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// newMessageID returns a unique Message-ID in the domain of the sender
+func newMessageID(from string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	domain := "localhost"
+	if at := strings.LastIndexByte(from, '@'); at >= 0 && at < len(from)-1 {
+		domain = from[at+1:]
+	}
+
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(b), domain), nil
+}
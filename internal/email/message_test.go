@@ -0,0 +1,87 @@
+package email
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild(t *testing.T) {
+	// given
+	from := mail.Address{Name: "Book Catalog", Address: "noreply@example.com"}
+	msg := Message{
+		To:      []string{"john@example.com"},
+		Subject: "Active su cuenta ¡ahora!",
+		Text:    "Hola, José",
+		HTML:    "<p>Hola, José</p>",
+	}
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	// when
+	raw, err := build(from, &msg, now)
+
+	// then
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	require.NoError(t, err)
+	require.Equal(t, `"Book Catalog" <noreply@example.com>`, parsed.Header.Get("From"))
+	require.Equal(t, "<john@example.com>", parsed.Header.Get("To"))
+	require.Equal(t, "1.0", parsed.Header.Get("MIME-Version"))
+	require.True(t, strings.HasSuffix(parsed.Header.Get("Message-ID"), "@example.com>"))
+
+	date, err := parsed.Header.Date()
+	require.NoError(t, err)
+	require.True(t, now.Equal(date))
+
+	require.NotEqual(t, msg.Subject, parsed.Header.Get("Subject"), "non ASCII subject must be encoded")
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	require.Equal(t, msg.Subject, subject)
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	for _, expected := range []struct{ contentType, body string }{
+		{"text/plain", msg.Text},
+		{"text/html", msg.HTML},
+	} {
+		part, err := reader.NextPart()
+		require.NoError(t, err)
+
+		contentType, _, err := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		require.Equal(t, expected.contentType, contentType)
+
+		// the reader decodes quoted-printable parts
+		body, err := io.ReadAll(part)
+		require.NoError(t, err)
+		require.Equal(t, expected.body, string(body))
+	}
+
+	_, err = reader.NextPart()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestBuild_ASCIISubject(t *testing.T) {
+	// given
+	msg := Message{To: []string{"john@example.com"}, Subject: "Reset Password"}
+
+	// when
+	raw, err := build(mail.Address{Address: "noreply@example.com"}, &msg, time.Now())
+
+	// then
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	require.NoError(t, err)
+	require.Equal(t, "Reset Password", parsed.Header.Get("Subject"))
+}
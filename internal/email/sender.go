@@ -1,10 +1,13 @@
 package email
 
-import "html/template"
+import (
+	"context"
+)
 
 // Sender interface
 //
 //go:generate mockgen -destination=../../test/mock/email/mock-sender.go -package=mock . Sender
 type Sender interface {
-	Send(to []string, subj string, template *template.Template, placeHolders any) error
+	Send(ctx context.Context, msg *Message) error
+	Ping(ctx context.Context) error
 }
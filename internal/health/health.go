@@ -0,0 +1,142 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vlaship/book-catalog-go/internal/logger"
+)
+
+// Status values of a probe and of its checks.
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Check is a named dependency check of the readiness probe.
+type Check struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// Result is the outcome of a check.
+type Result struct {
+	Status  string `json:"status"`
+	Latency string `json:"latency"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Report is the body of a probe response.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// Checker serves the liveness and readiness probes.
+// Liveness only tells the process serves requests, readiness runs every check concurrently
+// and fails when any of them fails or once shutdown has started.
+type Checker struct {
+	checks       []Check
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+	log          logger.Logger
+}
+
+// New creates a new Checker instance, every check is bounded by the timeout.
+func New(timeout time.Duration, log logger.Logger, checks ...Check) *Checker {
+	return &Checker{
+		checks:  checks,
+		timeout: timeout,
+		log:     log.New("health"),
+	}
+}
+
+// Shutdown makes the readiness probe fail, so no new traffic is routed while the server drains.
+func (c *Checker) Shutdown() {
+	c.shuttingDown.Store(true)
+}
+
+// Live handles the liveness probe.
+func (c *Checker) Live() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		write(w, Report{Status: StatusUp})
+	}
+}
+
+// Ready handles the readiness probe.
+func (c *Checker) Ready() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := c.Run(r.Context())
+		if report.Status != StatusUp {
+			c.log.Wrn().Ctx(r.Context()).Values("checks", report.Checks).Msg("not ready")
+		}
+		write(w, report)
+	}
+}
+
+// Run runs the checks and reports the readiness.
+func (c *Checker) Run(ctx context.Context) Report {
+	report := Report{
+		Status: StatusUp,
+		Checks: make(map[string]Result, len(c.checks)+1),
+	}
+	if c.shuttingDown.Load() {
+		report.Status = StatusDown
+		report.Checks["shutdown"] = Result{Status: StatusDown, Latency: time.Duration(0).String(), Error: "shutting down"}
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := c.run(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[check.Name] = res
+			if res.Status != StatusUp {
+				report.Status = StatusDown
+			}
+		}()
+	}
+	wg.Wait()
+
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Check(ctx)
+	res := Result{
+		Status:  StatusUp,
+		Latency: time.Since(start).String(),
+	}
+	if err != nil {
+		res.Status = StatusDown
+		res.Error = err.Error()
+	}
+
+	return res
+}
+
+func write(w http.ResponseWriter, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status != StatusUp {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	_ = json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,96 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+)
+
+func up(context.Context) error { return nil }
+
+func down(context.Context) error { return errors.New("connection refused") }
+
+func slow(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		checks   []Check
+		expected string
+	}{
+		{"no checks", nil, StatusUp},
+		{"all up", []Check{{"database", up}, {"smtp", up}}, StatusUp},
+		{"one down", []Check{{"database", up}, {"smtp", down}}, StatusDown},
+		{"timeout", []Check{{"database", slow}}, StatusDown},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checker := New(10*time.Millisecond, logger.Nop(), test.checks...)
+
+			report := checker.Run(context.Background())
+
+			require.Equal(t, test.expected, report.Status)
+			require.Len(t, report.Checks, len(test.checks))
+		})
+	}
+}
+
+func TestRun_ReportsEachCheck(t *testing.T) {
+	// given
+	checker := New(time.Second, logger.Nop(), Check{"database", up}, Check{"smtp", down})
+
+	// when
+	report := checker.Run(context.Background())
+
+	// then
+	require.Equal(t, StatusUp, report.Checks["database"].Status)
+	require.Empty(t, report.Checks["database"].Error)
+	require.NotEmpty(t, report.Checks["database"].Latency)
+	require.Equal(t, StatusDown, report.Checks["smtp"].Status)
+	require.Equal(t, "connection refused", report.Checks["smtp"].Error)
+}
+
+func TestReady(t *testing.T) {
+	// given
+	checker := New(time.Second, logger.Nop(), Check{"database", up})
+	rec := httptest.NewRecorder()
+
+	// when
+	checker.Ready()(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	// then
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var report Report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	require.Equal(t, StatusUp, report.Status)
+	require.Equal(t, StatusUp, report.Checks["database"].Status)
+}
+
+func TestReady_Shutdown(t *testing.T) {
+	// given
+	checker := New(time.Second, logger.Nop(), Check{"database", up})
+	checker.Shutdown()
+	ready := httptest.NewRecorder()
+	live := httptest.NewRecorder()
+
+	// when
+	checker.Ready()(ready, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	checker.Live()(live, httptest.NewRequest(http.MethodGet, "/livez", nil))
+
+	// then
+	require.Equal(t, http.StatusServiceUnavailable, ready.Code)
+	require.Equal(t, http.StatusOK, live.Code)
+}
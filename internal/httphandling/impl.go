@@ -64,11 +64,18 @@ func (h *HTTPErrorHandlerImpl) newFromError(err error) response.ProblemDetail {
 }
 
 func (h *HTTPErrorHandlerImpl) newFromAppError(err apperr.AppError) response.ProblemDetail {
-	return response.ProblemDetail{
+	p := response.ProblemDetail{
 		Title:  err.Title,
 		Detail: err.Detail,
 		Code:   err.Code,
 	}
+	if err.Violations != nil {
+		for _, v := range *err.Violations {
+			p.Violations = append(p.Violations, response.Violation{Rule: v.Rule, Detail: v.Detail})
+		}
+	}
+
+	return p
 }
 
 func (h *HTTPErrorHandlerImpl) getProblemDetails(err error) *response.ProblemDetail {
@@ -94,13 +101,25 @@ func getStatus(err error) int {
 		return http.StatusNotFound
 	case errors.Is(err, apperr.ErrForbidden),
 		errors.Is(err, apperr.ErrUserNotActivated),
-		errors.Is(err, apperr.ErrInvalidOTP):
+		errors.Is(err, apperr.ErrUserSuspended),
+		errors.Is(err, apperr.ErrUserLocked),
+		errors.Is(err, apperr.ErrUserTemporarilyLocked),
+		errors.Is(err, apperr.ErrInvalidOTP),
+		errors.Is(err, apperr.ErrInvalidPassword):
 		return http.StatusForbidden
 	case errors.Is(err, apperr.ErrBadRequest),
-		errors.Is(err, apperr.ErrAlreadyExists):
+		errors.Is(err, apperr.ErrAlreadyExists),
+		errors.Is(err, apperr.ErrWeakPassword):
 		return http.StatusBadRequest
 	case errors.Is(err, apperr.ErrUnsupportedMediaType):
 		return http.StatusUnsupportedMediaType
+	case errors.Is(err, apperr.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, apperr.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, apperr.ErrTooManyAttempts),
+		errors.Is(err, apperr.ErrRateLimited):
+		return http.StatusTooManyRequests
 	default:
 		return http.StatusInternalServerError
 	}
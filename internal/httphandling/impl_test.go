@@ -17,12 +17,21 @@ func TestGetStatus(t *testing.T) {
 		{apperr.ErrNotFound, http.StatusNotFound},
 		{apperr.ErrBadRequest, http.StatusBadRequest},
 		{apperr.ErrAlreadyExists, http.StatusBadRequest},
+		{apperr.ErrWeakPassword, http.StatusBadRequest},
 		{apperr.ErrUnsupportedMediaType, http.StatusUnsupportedMediaType},
 		{apperr.ErrUnauthorized, http.StatusUnauthorized},
 		{apperr.ErrForbidden, http.StatusForbidden},
 		{apperr.ErrInvalidToken, http.StatusUnauthorized},
 		{apperr.ErrUserNotActivated, http.StatusForbidden},
+		{apperr.ErrUserSuspended, http.StatusForbidden},
+		{apperr.ErrUserLocked, http.StatusForbidden},
+		{apperr.ErrUserTemporarilyLocked, http.StatusForbidden},
 		{apperr.ErrInvalidOTP, http.StatusForbidden},
+		{apperr.ErrInvalidPassword, http.StatusForbidden},
+		{apperr.ErrPreconditionFailed, http.StatusPreconditionFailed},
+		{apperr.ErrConflict, http.StatusConflict},
+		{apperr.ErrTooManyAttempts, http.StatusTooManyRequests},
+		{apperr.ErrRateLimited, http.StatusTooManyRequests},
 		{apperr.ErrExecuteTemplate, http.StatusInternalServerError},
 		{errors.New(""), http.StatusInternalServerError},
 	}
@@ -34,3 +43,22 @@ func TestGetStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestGetProblemDetails_Violations(t *testing.T) {
+	// given
+	h := &HTTPErrorHandlerImpl{}
+	err := apperr.ErrWeakPassword.WithFunc(apperr.WithViolations([]apperr.Violation{
+		{Rule: "min_length", Detail: "too short"},
+		{Rule: "common", Detail: "too common"},
+	}))
+
+	// when
+	p := h.getProblemDetails(err)
+
+	// then
+	assert.Equal(t, http.StatusBadRequest, p.Status)
+	assert.Equal(t, apperr.ErrWeakPassword.Code, p.Code)
+	assert.Len(t, p.Violations, 2)
+	assert.Equal(t, "min_length", p.Violations[0].Rule)
+	assert.Equal(t, "too common", p.Violations[1].Detail)
+}
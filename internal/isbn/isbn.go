@@ -0,0 +1,91 @@
+package isbn
+
+import "strings"
+
+const (
+	length10 = 10
+	length13 = 13
+	// prefix13 is the EAN prefix of ISBN-10 converted to ISBN-13
+	prefix13 = "978"
+	// prefix13Alt is the other EAN prefix of the Bookland, it has no ISBN-10
+	prefix13Alt = "979"
+)
+
+// Normalize converts ISBN-10 or ISBN-13 to ISBN-13 without hyphens and spaces,
+// false is returned when the ISBN is malformed, its check digit is wrong
+// or ISBN-13 does not start with 978 or 979
+func Normalize(s string) (string, bool) {
+	s = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(s))
+
+	switch len(s) {
+	case length10:
+		if !valid10(s) {
+			return "", false
+		}
+		s = prefix13 + s[:length10-1]
+		return s + string(checkDigit13(s)), true
+	case length13:
+		if !digits(s) || !bookland(s) || checkDigit13(s[:length13-1]) != s[length13-1] {
+			return "", false
+		}
+		return s, true
+	default:
+		return "", false
+	}
+}
+
+// Valid checks ISBN-10 or ISBN-13 with its check digit
+func Valid(s string) bool {
+	_, ok := Normalize(s)
+	return ok
+}
+
+// valid10 checks ISBN-10, its check digit can be X for 10
+func valid10(s string) bool {
+	if !digits(s[:length10-1]) {
+		return false
+	}
+
+	sum := 0
+	for i := 0; i < length10-1; i++ {
+		sum += int(s[i]-'0') * (length10 - i)
+	}
+	switch c := s[length10-1]; {
+	case c == 'X':
+		sum += 10
+	case c >= '0' && c <= '9':
+		sum += int(c - '0')
+	default:
+		return false
+	}
+
+	return sum%11 == 0
+}
+
+// checkDigit13 returns the check digit of the first 12 digits of ISBN-13
+func checkDigit13(s string) byte {
+	sum := 0
+	for i := 0; i < length13-1; i++ {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(s[i]-'0') * weight
+	}
+
+	return byte('0' + (10-sum%10)%10)
+}
+
+// bookland checks that ISBN-13 starts with one of the EAN prefixes of books
+func bookland(s string) bool {
+	return strings.HasPrefix(s, prefix13) || strings.HasPrefix(s, prefix13Alt)
+}
+
+func digits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+package isbn
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		isbn string
+		want string
+	}{
+		{"isbn-13 with hyphens", "978-0-13-419044-0", "9780134190440"},
+		{"isbn-13 with spaces", "978 1492077213", "9781492077213"},
+		{"isbn-10", "0134190440", "9780134190440"},
+		{"isbn-10 with check digit X", "0-8044-2957-X", "9780804429573"},
+		{"isbn-10 with lower case x", "080442957x", "9780804429573"},
+		{"isbn-13 with prefix 979", "979-10-90636-07-1", "9791090636071"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			got, ok := Normalize(tt.isbn)
+
+			// then
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNormalizeFail(t *testing.T) {
+	tests := []struct {
+		name string
+		isbn string
+	}{
+		{"empty", ""},
+		{"wrong isbn-13 check digit", "9780134190441"},
+		{"wrong isbn-10 check digit", "0134190441"},
+		{"letters", "97801341904AB"},
+		{"X inside isbn-10", "01341X0440"},
+		{"X as isbn-13 check digit", "978013419044X"},
+		{"wrong length", "978013419044"},
+		{"ean-13 that is not isbn", "4006381333931"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			got, ok := Normalize(tt.isbn)
+
+			// then
+			assert.False(t, ok)
+			assert.Empty(t, got)
+		})
+	}
+}
@@ -10,9 +10,14 @@ import (
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
 )
 
-const requestID = "requestID"
+const (
+	requestID = "requestID"
+	traceID   = "traceID"
+	spanID    = "spanID"
+)
 
 // Impl is a struct that represents a logger
 type Impl struct {
@@ -110,6 +115,9 @@ func (e *ServiceEvent) Ctx(ctx context.Context) Event {
 	if id != nil {
 		e.Event = e.Event.Str(requestID, id.(string))
 	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		e.Event = e.Event.Str(traceID, sc.TraceID().String()).Str(spanID, sc.SpanID().String())
+	}
 	return e
 }
 
@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "book_catalog"
+
+// Event is a business event counted by metrics.
+type Event string
+
+// Event values
+const (
+	EventSignup       Event = "signup"
+	EventActivation   Event = "activation"
+	EventSigninFailed Event = "signin_failed"
+	EventUserLocked   Event = "user_locked"
+	EventMailSent     Event = "mail_sent"
+	EventMailFailed   Event = "mail_failed"
+	EventMailDead     Event = "mail_dead"
+)
+
+// Recorder records application metrics.
+//
+//go:generate mockgen -destination=../../test/mock/metrics/mock-recorder.go -package=mock . Recorder
+type Recorder interface {
+	ObserveRequest(method, route string, status int, duration time.Duration)
+	Event(event Event)
+	CacheResult(keyspace string, hit bool)
+}
+
+// PrometheusImpl is a Recorder implementation exposing the metrics to prometheus.
+type PrometheusImpl struct {
+	registry *prometheus.Registry
+	requests *prometheus.HistogramVec
+	events   *prometheus.CounterVec
+	cache    *prometheus.CounterVec
+}
+
+// New creates a new prometheus recorder with the go runtime and process collectors registered.
+func New() *PrometheusImpl {
+	m := &PrometheusImpl{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of HTTP requests by route pattern and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		events: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "events_total",
+			Help:      "Number of business events.",
+		}, []string{"event"}),
+		cache: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "cache",
+			Name:      "requests_total",
+			Help:      "Number of cache lookups by keyspace and result.",
+		}, []string{"keyspace", "result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.events,
+		m.cache,
+	)
+
+	return m
+}
+
+// Handler serves the metrics in the prometheus exposition format.
+func (m *PrometheusImpl) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Register registers additional collectors.
+func (m *PrometheusImpl) Register(cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := m.registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ObserveRequest observes the duration of an HTTP request.
+func (m *PrometheusImpl) ObserveRequest(method, route string, status int, duration time.Duration) {
+	m.requests.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// Event counts a business event.
+func (m *PrometheusImpl) Event(event Event) {
+	m.events.WithLabelValues(string(event)).Inc()
+}
+
+// CacheResult counts a cache hit or miss.
+func (m *PrometheusImpl) CacheResult(keyspace string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.cache.WithLabelValues(keyspace, result).Inc()
+}
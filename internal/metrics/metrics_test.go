@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheus_Event(t *testing.T) {
+	// given
+	m := New()
+
+	// when
+	m.Event(EventSignup)
+	m.Event(EventSignup)
+	m.Event(EventMailFailed)
+
+	// then
+	require.InDelta(t, 2, testutil.ToFloat64(m.events.WithLabelValues(string(EventSignup))), 0)
+	require.InDelta(t, 1, testutil.ToFloat64(m.events.WithLabelValues(string(EventMailFailed))), 0)
+}
+
+func TestPrometheus_CacheResult(t *testing.T) {
+	// given
+	m := New()
+
+	// when
+	m.CacheResult("book", true)
+	m.CacheResult("book", false)
+	m.CacheResult("book", false)
+
+	// then
+	require.InDelta(t, 1, testutil.ToFloat64(m.cache.WithLabelValues("book", "hit")), 0)
+	require.InDelta(t, 2, testutil.ToFloat64(m.cache.WithLabelValues("book", "miss")), 0)
+}
+
+func TestPrometheus_Handler(t *testing.T) {
+	// given
+	m := New()
+	m.ObserveRequest(http.MethodGet, "/api/v1/book/{id}", http.StatusOK, 20*time.Millisecond)
+
+	// when
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// then
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	require.Contains(t, body,
+		`book_catalog_http_request_duration_seconds_count{method="GET",route="/api/v1/book/{id}",status="200"} 1`)
+	require.Contains(t, body, "go_goroutines")
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolStater provides the statistics of a database connection pool.
+type PoolStater interface {
+	Stat() *pgxpool.Stat
+}
+
+// poolCollector collects the statistics of a database connection pool on every scrape.
+type poolCollector struct {
+	pool PoolStater
+
+	acquired        *prometheus.Desc
+	idle            *prometheus.Desc
+	total           *prometheus.Desc
+	max             *prometheus.Desc
+	acquires        *prometheus.Desc
+	acquireDuration *prometheus.Desc
+	waits           *prometheus.Desc
+	waitDuration    *prometheus.Desc
+}
+
+// NewPoolCollector creates a collector of the database connection pool statistics.
+func NewPoolCollector(pool PoolStater) prometheus.Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+
+	return &poolCollector{
+		pool:            pool,
+		acquired:        desc("acquired_connections", "Number of connections currently in use."),
+		idle:            desc("idle_connections", "Number of idle connections."),
+		total:           desc("total_connections", "Number of open connections."),
+		max:             desc("max_connections", "Maximum number of connections."),
+		acquires:        desc("acquires_total", "Number of successful connection acquires."),
+		acquireDuration: desc("acquire_duration_seconds_total", "Total duration of successful connection acquires."),
+		waits:           desc("waits_total", "Number of acquires that waited for a connection."),
+		waitDuration:    desc("wait_duration_seconds_total", "Total duration of acquires waiting for a connection."),
+	}
+}
+
+// Describe sends the descriptors of the pool metrics.
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquired
+	ch <- c.idle
+	ch <- c.total
+	ch <- c.max
+	ch <- c.acquires
+	ch <- c.acquireDuration
+	ch <- c.waits
+	ch <- c.waitDuration
+}
+
+// Collect sends the current pool statistics.
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquired, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.max, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, s.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.waits, prometheus.CounterValue, float64(s.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, s.EmptyAcquireWaitTime().Seconds())
+}
@@ -0,0 +1,181 @@
+package password
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1" //nolint:gosec // the breached passwords are published as SHA-1 hashes
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// prefixLen is the length of the hash prefix of a range lookup
+	prefixLen = 5
+	// maxLineLen bounds the lines of the breached passwords file, a hash, a colon and a count
+	maxLineLen = 64
+)
+
+// Breached reports how many times a password has appeared in data breaches
+//
+//go:generate mockgen -destination=../../test/mock/password/mock-breached.go -package=mock . Breached
+type Breached interface {
+	Count(ctx context.Context, password string) (int, error)
+}
+
+// RangeFile looks up breached passwords in a local file the k-anonymity way, the password is
+// hashed and only the hashes sharing the prefix of its hash are read.
+// The file has a HASH:COUNT line per SHA-1 hash ordered by hash, as the Pwned Passwords downloads.
+type RangeFile struct {
+	path string
+}
+
+// NewRangeFile creates a new range lookup of the file
+func NewRangeFile(path string) (*RangeFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached passwords file: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("breached passwords file [%s] is a directory", path)
+	}
+
+	return &RangeFile{path: path}, nil
+}
+
+// Count returns the breach count of the password, zero when it has not been breached
+func (f *RangeFile) Count(_ context.Context, password string) (int, error) {
+	sum := sha1.Sum([]byte(password)) //nolint:gosec // see import
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	suffixes, err := f.Range(hash[:prefixLen])
+	if err != nil {
+		return 0, err
+	}
+
+	return suffixes[hash[prefixLen:]], nil
+}
+
+// Range returns the breach counts by hash suffix of the hashes with the prefix
+func (f *RangeFile) Range(prefix string) (map[string]int, error) {
+	prefix = strings.ToUpper(prefix)
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := f.search(file, info.Size(), prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	suffixes := make(map[string]int)
+	scanner := bufio.NewScanner(io.NewSectionReader(file, start, info.Size()-start))
+	for scanner.Scan() {
+		hash, count, ok := parseLine(scanner.Text())
+		if !ok || !strings.HasPrefix(hash, prefix) {
+			break
+		}
+		suffixes[hash[len(prefix):]] = count
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return suffixes, nil
+}
+
+// search returns the offset of the first line with a hash not less than the prefix
+func (f *RangeFile) search(file *os.File, size int64, prefix string) (int64, error) {
+	lo, hi := int64(0), size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+
+		start, err := lineStart(file, mid)
+		if err != nil {
+			return 0, err
+		}
+		if start >= size {
+			hi = mid
+			continue
+		}
+
+		line, err := readLine(file, start)
+		if err != nil {
+			return 0, err
+		}
+		if hash, _, _ := parseLine(line); hash >= prefix {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+
+	return lineStart(file, lo)
+}
+
+// lineStart returns the offset of the first line starting at or after the offset
+func lineStart(file *os.File, offset int64) (int64, error) {
+	if offset == 0 {
+		return 0, nil
+	}
+
+	buf := make([]byte, maxLineLen)
+	n, err := file.ReadAt(buf, offset-1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, err
+	}
+	if i := bytes.IndexByte(buf[:n], '\n'); i >= 0 {
+		return offset + int64(i), nil
+	}
+	if n < len(buf) {
+		// the last line
+		return offset - 1 + int64(n), nil
+	}
+
+	return 0, fmt.Errorf("breached passwords file has a line longer than %d bytes", maxLineLen)
+}
+
+// readLine reads the line starting at the offset
+func readLine(file *os.File, offset int64) (string, error) {
+	buf := make([]byte, maxLineLen)
+	n, err := file.ReadAt(buf, offset)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	line, _, _ := bytes.Cut(buf[:n], []byte{'\n'})
+	return string(line), nil
+}
+
+// parseLine parses a HASH:COUNT line, the count defaults to one
+func parseLine(line string) (string, int, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return "", 0, false
+	}
+
+	hash, count, found := strings.Cut(line, ":")
+	hash = strings.ToUpper(hash)
+	if !found {
+		return hash, 1, true
+	}
+
+	n, err := strconv.Atoi(count)
+	if err != nil {
+		return hash, 1, true
+	}
+	return hash, n, true
+}
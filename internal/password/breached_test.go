@@ -0,0 +1,86 @@
+package password
+
+import (
+	"context"
+	"crypto/sha1" //nolint:gosec // the breached passwords are published as SHA-1 hashes
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeRangeFile writes the breached passwords file of the passwords and of filler hashes,
+// the count of a password is its position in the list
+func writeRangeFile(t *testing.T, passwords ...string) string {
+	t.Helper()
+
+	lines := make([]string, 0, 1000+len(passwords))
+	for i := range 1000 {
+		sum := sha1.Sum([]byte(fmt.Sprintf("filler-%d", i))) //nolint:gosec // see import
+		lines = append(lines, fmt.Sprintf("%s:%d", strings.ToUpper(hex.EncodeToString(sum[:])), i+1))
+	}
+	for i, password := range passwords {
+		sum := sha1.Sum([]byte(password)) //nolint:gosec // see import
+		lines = append(lines, fmt.Sprintf("%s:%d", strings.ToUpper(hex.EncodeToString(sum[:])), i+1))
+	}
+	sort.Strings(lines)
+
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0o600))
+
+	return path
+}
+
+func TestRangeFile_Count(t *testing.T) {
+	// given
+	passwords := []string{"123456", "password", "Tr0ub4dor&3"}
+	file, err := NewRangeFile(writeRangeFile(t, passwords...))
+	require.NoError(t, err)
+
+	// then
+	for i, password := range passwords {
+		count, err := file.Count(context.Background(), password)
+		require.NoError(t, err)
+		assert.Equal(t, i+1, count, password)
+	}
+
+	count, err := file.Count(context.Background(), "Correct-Horse-42")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestRangeFile_Range(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	content := "00000AAA:1\n0000AAAA:2\n0000ABBB:3\n0000ACCC\n0001AAAA:5\nFFFFFAAA:6"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	file, err := NewRangeFile(path)
+	require.NoError(t, err)
+
+	tests := []struct {
+		prefix   string
+		expected map[string]int
+	}{
+		{"00000", map[string]int{"AAA": 1}},
+		{"0000a", map[string]int{"AAA": 2, "BBB": 3, "CCC": 1}},
+		{"0001A", map[string]int{"AAA": 5}},
+		{"FFFFF", map[string]int{"AAA": 6}},
+		{"00002", map[string]int{}},
+		{"FFFFE", map[string]int{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.prefix, func(t *testing.T) {
+			suffixes, err := file.Range(test.prefix)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, suffixes)
+		})
+	}
+}
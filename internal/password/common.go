@@ -0,0 +1,44 @@
+package password
+
+import (
+	"bufio"
+	_ "embed" // common passwords
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// commonPasswords is the default list of common passwords, most common first
+//
+//go:embed common.txt
+var commonPasswords string
+
+// loadCommon loads the top common passwords of the file or of the default list, a zero top loads all of them
+func loadCommon(path string, top int) (map[string]struct{}, error) {
+	var r io.Reader = strings.NewReader(commonPasswords)
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open common passwords file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	common := make(map[string]struct{})
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if top > 0 && len(common) >= top {
+			break
+		}
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			common[strings.ToLower(line)] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read common passwords: %w", err)
+	}
+
+	return common, nil
+}
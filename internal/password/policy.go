@@ -0,0 +1,159 @@
+package password
+
+import (
+	"context"
+	"fmt"
+	"github.com/vlaship/book-catalog-go/internal/config"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Rules of the password policy
+const (
+	RuleMinLength        = "min_length"
+	RuleCharacterClasses = "character_classes"
+	RuleUsername         = "username"
+	RuleCommon           = "common"
+	RuleBreached         = "breached"
+)
+
+// minUsernamePart is the shortest part of the username that passwords must not contain
+const minUsernamePart = 3
+
+// Violation is a failed rule of the password policy
+type Violation struct {
+	Rule   string
+	Detail string
+}
+
+// Policy checks passwords against the password policy
+//
+//go:generate mockgen -destination=../../test/mock/password/mock-policy.go -package=mock . Policy
+type Policy interface {
+	Check(ctx context.Context, password, username string) ([]Violation, error)
+}
+
+// PolicyImpl checks the length, the character classes, the username, the common passwords
+// and, when a breached passwords file is configured, the breached passwords
+type PolicyImpl struct {
+	minLength        int
+	minClasses       int
+	common           map[string]struct{}
+	breached         Breached
+	breachedMinCount int
+}
+
+// NewPolicy creates a new password policy of the configuration
+func NewPolicy(cfg *config.Config) (Policy, error) {
+	common, err := loadCommon(cfg.Password.CommonFile, cfg.Password.CommonTop)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &PolicyImpl{
+		minLength:        cfg.Password.MinLength,
+		minClasses:       cfg.Password.MinClasses,
+		common:           common,
+		breachedMinCount: max(cfg.Password.BreachedMinCount, 1),
+	}
+	if cfg.Password.BreachedFile != "" {
+		if p.breached, err = NewRangeFile(cfg.Password.BreachedFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// Check returns the failed rules, the breached passwords are looked up only when the other rules pass
+func (p *PolicyImpl) Check(ctx context.Context, password, username string) ([]Violation, error) {
+	var violations []Violation
+
+	if utf8.RuneCountInString(password) < p.minLength {
+		violations = append(violations, Violation{
+			Rule:   RuleMinLength,
+			Detail: fmt.Sprintf("password must be at least %d characters long", p.minLength),
+		})
+	}
+	if classes(password) < p.minClasses {
+		violations = append(violations, Violation{
+			Rule: RuleCharacterClasses,
+			Detail: fmt.Sprintf(
+				"password must contain at least %d of lowercase letters, uppercase letters, digits and symbols",
+				p.minClasses,
+			),
+		})
+	}
+	if containsUsername(password, username) {
+		violations = append(violations, Violation{
+			Rule:   RuleUsername,
+			Detail: "password must not contain the username",
+		})
+	}
+	if _, ok := p.common[strings.ToLower(password)]; ok {
+		violations = append(violations, Violation{
+			Rule:   RuleCommon,
+			Detail: "password is too common",
+		})
+	}
+	if len(violations) > 0 || p.breached == nil {
+		return violations, nil
+	}
+
+	count, err := p.breached.Count(ctx, password)
+	if err != nil {
+		return nil, err
+	}
+	if count >= p.breachedMinCount {
+		violations = append(violations, Violation{
+			Rule:   RuleBreached,
+			Detail: "password has appeared in a data breach",
+		})
+	}
+
+	return violations, nil
+}
+
+// classes counts the character classes of the password, letters without case count as lowercase
+func classes(password string) int {
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLetter(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	count := 0
+	for _, ok := range []bool{lower, upper, digit, symbol} {
+		if ok {
+			count++
+		}
+	}
+	return count
+}
+
+// containsUsername reports whether the password contains the username or the local part of the email
+func containsUsername(password, username string) bool {
+	password = strings.ToLower(password)
+	username = strings.ToLower(username)
+
+	parts := []string{username}
+	if local, _, ok := strings.Cut(username, "@"); ok {
+		parts = append(parts, local)
+	}
+
+	for _, part := range parts {
+		if utf8.RuneCountInString(part) >= minUsernamePart && strings.Contains(password, part) {
+			return true
+		}
+	}
+	return false
+}
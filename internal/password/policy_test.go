@@ -0,0 +1,105 @@
+package password
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vlaship/book-catalog-go/internal/config"
+)
+
+func newTestConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.Password.MinLength = 8
+	cfg.Password.MinClasses = 3
+	return cfg
+}
+
+func rules(violations []Violation) []string {
+	out := make([]string, 0, len(violations))
+	for _, v := range violations {
+		out = append(out, v.Rule)
+	}
+	return out
+}
+
+func TestPolicy_Check(t *testing.T) {
+	policy, err := NewPolicy(newTestConfig())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		password string
+		username string
+		expected []string
+	}{
+		{"strong", "Correct-Horse-42", "john@example.com", []string{}},
+		{"short", "Ab1!", "john@example.com", []string{RuleMinLength}},
+		{"one class", "correcthorsebattery", "john@example.com", []string{RuleCharacterClasses}},
+		{"two classes", "correcthorse42", "john@example.com", []string{RuleCharacterClasses}},
+		{"unicode", "Contraseña-segura", "john@example.com", []string{}},
+		{"email", "x-John@Example.com-1", "john@example.com", []string{RuleUsername}},
+		{"local part", "Johnny-Walker-7", "john@example.com", []string{RuleUsername}},
+		{"short local part", "Joe-Is-Here-7", "jo@example.com", []string{}},
+		{"common", "Password123", "john@example.com", []string{RuleCommon}},
+		{"common case", "P@SSW0RD", "john@example.com", []string{RuleCommon}},
+		{"all", "1234", "1234@example.com", []string{RuleMinLength, RuleCharacterClasses, RuleUsername, RuleCommon}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			violations, err := policy.Check(context.Background(), test.password, test.username)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, rules(violations))
+		})
+	}
+}
+
+func TestPolicy_CommonTop(t *testing.T) {
+	// given
+	cfg := newTestConfig()
+	cfg.Password.CommonTop = 1
+	cfg.Password.MinClasses = 1
+
+	// when
+	policy, err := NewPolicy(cfg)
+	require.NoError(t, err)
+
+	// then
+	violations, err := policy.Check(context.Background(), "123456", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{RuleMinLength, RuleCommon}, rules(violations))
+
+	violations, err = policy.Check(context.Background(), "password", "")
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+}
+
+func TestPolicy_Breached(t *testing.T) {
+	// given
+	cfg := newTestConfig()
+	cfg.Password.BreachedFile = writeRangeFile(t, "Correct-Horse-42", "Tr0ub4dor&3")
+
+	policy, err := NewPolicy(cfg)
+	require.NoError(t, err)
+
+	// when
+	violations, err := policy.Check(context.Background(), "Tr0ub4dor&3", "john@example.com")
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{RuleBreached}, rules(violations))
+
+	violations, err = policy.Check(context.Background(), "Battery-Staple-9", "john@example.com")
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+}
+
+func TestPolicy_MissingBreachedFile(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Password.BreachedFile = t.TempDir() + "/missing.txt"
+
+	_, err := NewPolicy(cfg)
+	assert.Error(t, err)
+}
@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/vlaship/book-catalog-go/internal/config"
+)
+
+// sweepInterval is how often idle buckets are dropped.
+const sweepInterval = time.Minute
+
+// bucket is the state of a token bucket.
+type bucket struct {
+	tokens float64
+	last   time.Time
+	period time.Duration
+}
+
+// take refills the bucket up to now and takes a token if there is one.
+func (b *bucket) take(now time.Time, policy config.RateLimitPolicy) Result {
+	capacity := float64(policy.Limit)
+	rate := capacity / float64(policy.Period) // tokens per nanosecond
+
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+float64(elapsed)*rate)
+	}
+	b.last = now
+
+	res := Result{Limit: policy.Limit}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration(math.Ceil((1 - b.tokens) / rate))
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = time.Duration(math.Ceil((capacity - b.tokens) / rate))
+
+	return res
+}
+
+// InMemImpl is a limiter implementation local to the process.
+type InMemImpl struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+	swept   time.Time
+}
+
+// Allow takes a token from the bucket of the key.
+func (l *InMemImpl) Allow(_ context.Context, key string, policy config.RateLimitPolicy) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(policy.Limit), last: now, period: policy.Period}
+		l.buckets[key] = b
+	}
+
+	return b.take(now, policy), nil
+}
+
+// Close does nothing, the buckets live as long as the process.
+func (l *InMemImpl) Close() error {
+	return nil
+}
+
+// sweep drops the buckets that have been idle long enough to be full again,
+// a missing bucket starts full, so dropping them does not change the limits.
+func (l *InMemImpl) sweep(now time.Time) {
+	if now.Sub(l.swept) < sweepInterval {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= b.period {
+			delete(l.buckets, key)
+		}
+	}
+	l.swept = now
+}
+
+// NewInMem creates a new in-memory limiter instance.
+func NewInMem() Limiter {
+	return newInMem(time.Now)
+}
+
+func newInMem(now func() time.Time) *InMemImpl {
+	return &InMemImpl{
+		buckets: make(map[string]*bucket),
+		now:     now,
+		swept:   now(),
+	}
+}
@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vlaship/book-catalog-go/internal/config"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func TestInMem_Allow(t *testing.T) {
+	// given
+	ctx := context.Background()
+	limiter := NewInMem()
+	policy := config.RateLimitPolicy{Limit: 2, Period: time.Minute}
+
+	// when
+	res1, err1 := limiter.Allow(ctx, "key1", policy)
+	res2, err2 := limiter.Allow(ctx, "key1", policy)
+	res3, err3 := limiter.Allow(ctx, "key1", policy)
+
+	// then
+	require.NoError(t, err1)
+	require.True(t, res1.Allowed)
+	require.Equal(t, 2, res1.Limit)
+	require.Equal(t, 1, res1.Remaining)
+	require.NoError(t, err2)
+	require.True(t, res2.Allowed)
+	require.Equal(t, 0, res2.Remaining)
+	require.NoError(t, err3)
+	require.False(t, res3.Allowed)
+	require.Equal(t, 0, res3.Remaining)
+	require.Positive(t, res3.RetryAfter)
+	require.LessOrEqual(t, res3.RetryAfter, 30*time.Second)
+}
+
+func TestInMem_Allow_Keys(t *testing.T) {
+	// given
+	ctx := context.Background()
+	limiter := NewInMem()
+	policy := config.RateLimitPolicy{Limit: 1, Period: time.Minute}
+
+	// when
+	res1, _ := limiter.Allow(ctx, "key1", policy)
+	res2, _ := limiter.Allow(ctx, "key2", policy)
+
+	// then
+	require.True(t, res1.Allowed)
+	require.True(t, res2.Allowed)
+}
+
+func TestInMem_Allow_Refill(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := &clock{now: time.Unix(0, 0)}
+	limiter := newInMem(c.Now)
+	policy := config.RateLimitPolicy{Limit: 2, Period: time.Minute}
+	_, _ = limiter.Allow(ctx, "key1", policy)
+	_, _ = limiter.Allow(ctx, "key1", policy)
+
+	// when
+	res1, _ := limiter.Allow(ctx, "key1", policy)
+	c.now = c.now.Add(30 * time.Second)
+	res2, _ := limiter.Allow(ctx, "key1", policy)
+	res3, _ := limiter.Allow(ctx, "key1", policy)
+
+	// then
+	require.False(t, res1.Allowed)
+	require.Equal(t, 30*time.Second, res1.RetryAfter)
+	require.True(t, res2.Allowed)
+	require.False(t, res3.Allowed)
+	require.Equal(t, time.Minute, res3.Reset)
+}
+
+func TestInMem_Allow_Sweep(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := &clock{now: time.Unix(0, 0)}
+	limiter := newInMem(c.Now)
+	policy := config.RateLimitPolicy{Limit: 1, Period: time.Second}
+	_, _ = limiter.Allow(ctx, "key1", policy)
+
+	// when
+	c.now = c.now.Add(sweepInterval)
+	_, _ = limiter.Allow(ctx, "key2", policy)
+
+	// then
+	require.NotContains(t, limiter.buckets, "key1")
+	require.Contains(t, limiter.buckets, "key2")
+}
@@ -0,0 +1,46 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vlaship/book-catalog-go/internal/config"
+)
+
+// Backend values of RATE_LIMIT_BACKEND.
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+// Result is the outcome of taking a token from a bucket.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+	Reset      time.Duration
+}
+
+// Limiter is a token bucket rate limiter.
+// A bucket holds up to policy.Limit tokens and refills policy.Limit tokens per policy.Period,
+// every request takes one token and is allowed while the bucket is not empty.
+//
+//go:generate mockgen -destination=../../test/mock/ratelimit/mock-limiter.go -package=mock . Limiter
+type Limiter interface {
+	Allow(ctx context.Context, key string, policy config.RateLimitPolicy) (Result, error)
+	Close() error
+}
+
+// New creates a limiter of the configured backend.
+func New(cfg *config.Config) (Limiter, error) {
+	switch cfg.RateLimit.Backend {
+	case "", BackendMemory:
+		return NewInMem(), nil
+	case BackendRedis:
+		return NewRedis(cfg.Cache.RedisURL)
+	default:
+		return nil, fmt.Errorf("unsupported rate limit backend: %s", cfg.RateLimit.Backend)
+	}
+}
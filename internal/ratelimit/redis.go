@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/vlaship/book-catalog-go/internal/config"
+)
+
+// takeScript refills the bucket stored in a hash up to now and takes a token if there is one,
+// it returns whether the token was taken and the tokens left, the bucket expires once it is full again.
+var takeScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local rate = capacity / period
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'last')
+local tokens = tonumber(state[1]) or capacity
+local last = tonumber(state[2]) or now
+
+local stamp = ARGV[3]
+if now > last then
+	tokens = math.min(capacity, tokens + (now - last) * rate)
+elseif state[2] then
+	stamp = state[2]
+end
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'last', stamp)
+redis.call('PEXPIRE', KEYS[1], math.ceil(period))
+
+return {allowed, tostring(tokens)}
+`)
+
+// RedisImpl is a limiter implementation shared by all replicas.
+type RedisImpl struct {
+	client redis.UniversalClient
+	now    func() time.Time
+}
+
+// Allow takes a token from the bucket of the key.
+func (l *RedisImpl) Allow(ctx context.Context, key string, policy config.RateLimitPolicy) (Result, error) {
+	period := policy.Period.Milliseconds()
+	out, err := takeScript.Run(ctx, l.client, []string{key}, policy.Limit, period, l.now().UnixMilli()).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	allowed, _ := out[0].(int64)
+	tokens, err := strconv.ParseFloat(out[1].(string), 64)
+	if err != nil {
+		return Result{}, err
+	}
+
+	rate := float64(policy.Limit) / float64(policy.Period)
+	res := Result{
+		Allowed:   allowed == 1,
+		Limit:     policy.Limit,
+		Remaining: int(tokens),
+		Reset:     time.Duration(math.Ceil((float64(policy.Limit) - tokens) / rate)),
+	}
+	if !res.Allowed {
+		res.RetryAfter = time.Duration(math.Ceil((1 - tokens) / rate))
+	}
+
+	return res, nil
+}
+
+// Close closes the connections to redis.
+func (l *RedisImpl) Close() error {
+	return l.client.Close()
+}
+
+// NewRedis creates a new redis limiter instance from a redis:// or rediss:// URL.
+func NewRedis(url string) (Limiter, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRedisWithClient(redis.NewClient(opts)), nil
+}
+
+// NewRedisWithClient creates a new redis limiter instance on top of the client.
+func NewRedisWithClient(client redis.UniversalClient) Limiter {
+	return &RedisImpl{client: client, now: time.Now}
+}
@@ -0,0 +1,113 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"github.com/vlaship/book-catalog-go/internal/config"
+)
+
+func newTestRedis(t *testing.T, c *clock) (*RedisImpl, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	limiter := &RedisImpl{
+		client: redis.NewClient(&redis.Options{Addr: server.Addr()}),
+		now:    c.Now,
+	}
+	t.Cleanup(func() { _ = limiter.Close() })
+
+	return limiter, server
+}
+
+func TestRedis_Allow(t *testing.T) {
+	// given
+	ctx := context.Background()
+	limiter, _ := newTestRedis(t, &clock{now: time.Unix(1_700_000_000, 0)})
+	policy := config.RateLimitPolicy{Limit: 2, Period: time.Minute}
+
+	// when
+	res1, err1 := limiter.Allow(ctx, "key1", policy)
+	res2, err2 := limiter.Allow(ctx, "key1", policy)
+	res3, err3 := limiter.Allow(ctx, "key1", policy)
+
+	// then
+	require.NoError(t, err1)
+	require.True(t, res1.Allowed)
+	require.Equal(t, 2, res1.Limit)
+	require.Equal(t, 1, res1.Remaining)
+	require.NoError(t, err2)
+	require.True(t, res2.Allowed)
+	require.Equal(t, 0, res2.Remaining)
+	require.NoError(t, err3)
+	require.False(t, res3.Allowed)
+	require.Equal(t, 30*time.Second, res3.RetryAfter)
+	require.Equal(t, time.Minute, res3.Reset)
+}
+
+func TestRedis_Allow_Refill(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := &clock{now: time.Unix(1_700_000_000, 0)}
+	limiter, server := newTestRedis(t, c)
+	policy := config.RateLimitPolicy{Limit: 2, Period: time.Minute}
+	_, _ = limiter.Allow(ctx, "key1", policy)
+	_, _ = limiter.Allow(ctx, "key1", policy)
+
+	// when
+	c.now = c.now.Add(30 * time.Second)
+	res1, err1 := limiter.Allow(ctx, "key1", policy)
+	res2, err2 := limiter.Allow(ctx, "key1", policy)
+
+	// then
+	require.NoError(t, err1)
+	require.True(t, res1.Allowed)
+	require.NoError(t, err2)
+	require.False(t, res2.Allowed)
+	require.Equal(t, time.Minute, server.TTL("key1"))
+}
+
+func TestRedis_Allow_ClockBehind(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := &clock{now: time.Unix(1_700_000_000, 0)}
+	limiter, _ := newTestRedis(t, c)
+	policy := config.RateLimitPolicy{Limit: 1, Period: time.Minute}
+	_, _ = limiter.Allow(ctx, "key1", policy)
+
+	// when
+	c.now = c.now.Add(-time.Minute)
+	res1, _ := limiter.Allow(ctx, "key1", policy)
+	c.now = c.now.Add(time.Minute)
+	res2, _ := limiter.Allow(ctx, "key1", policy)
+
+	// then
+	require.False(t, res1.Allowed)
+	require.False(t, res2.Allowed)
+}
+
+func TestRedis_Allow_Unavailable(t *testing.T) {
+	// given
+	ctx := context.Background()
+	limiter, server := newTestRedis(t, &clock{now: time.Now()})
+	server.Close()
+
+	// when
+	_, err := limiter.Allow(ctx, "key1", config.RateLimitPolicy{Limit: 1, Period: time.Minute})
+
+	// then
+	require.Error(t, err)
+}
+
+func TestNewRedis_InvalidURL(t *testing.T) {
+	// when
+	limiter, err := NewRedis("localhost:6379")
+
+	// then
+	require.Error(t, err)
+	require.Nil(t, limiter)
+}
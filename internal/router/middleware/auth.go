@@ -86,16 +86,11 @@ func (m *AuthMiddleware) validateUser(w http.ResponseWriter, r *http.Request, us
 		return true
 	}
 
-	err := user.GetAppError()
-	switch {
-
-	case errors.Is(err, apperr.ErrForbidden):
-		m.handler.AppErrorResponse(w, r, apperr.ErrForbidden)
-
-	case errors.Is(err, apperr.ErrUserNotActivated):
-		m.handler.AppErrorResponse(w, r, apperr.ErrUserNotActivated)
-
-	default:
+	// the status errors wrap ErrForbidden, so they are answered as is to keep their distinct codes
+	var appErr apperr.AppError
+	if errors.As(user.GetAppError(), &appErr) {
+		m.handler.AppErrorResponse(w, r, appErr)
+	} else {
 		m.unauthorized(w, r)
 	}
 
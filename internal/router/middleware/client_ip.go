@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// RealIP sets the remote address of the request to the client IP reported by a trusted proxy.
+// The headers are ignored on requests that do not come from a trusted proxy, as anyone can send them.
+// The X-Forwarded-For hops are read from the right, the client is the first hop that is not a trusted proxy.
+func RealIP(trusted []netip.Prefix) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if isTrusted(trusted, remoteIP(r)) {
+				if ip := forwardedIP(trusted, r); ip != "" {
+					r.RemoteAddr = ip
+				}
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// forwardedIP returns the client IP of the headers set by a trusted proxy
+func forwardedIP(trusted []netip.Prefix, r *http.Request) string {
+	for _, header := range []string{"True-Client-IP", "X-Real-IP"} {
+		if addr, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get(header))); err == nil {
+			return addr.Unmap().String()
+		}
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			return ""
+		}
+		if ip := addr.Unmap().String(); !isTrusted(trusted, ip) {
+			return ip
+		}
+	}
+
+	return ""
+}
+
+// isTrusted reports whether the IP belongs to a trusted proxy
+func isTrusted(trusted []netip.Prefix, ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP stores the IP of the client set by the RealIP middleware in the request context.
+func ClientIP() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), types.ClientIPContextKey, remoteIP(r))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// remoteIP strips the port from the remote address.
+func remoteIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
@@ -11,7 +11,8 @@ func CorsHandler() func(next http.Handler) http.Handler {
 	return cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "sentry-trace", "baggage"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "If-Match", "sentry-trace", "baggage"},
+		ExposedHeaders:   []string{"ETag"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	})
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"github.com/vlaship/book-catalog-go/internal/health"
+	"net/http"
+)
+
+// HealthMiddleware is a middleware that answers the liveness and readiness probes.
+type HealthMiddleware struct {
+	checker *health.Checker
+}
+
+// NewHealthMiddleware creates a new HealthMiddleware instance.
+func NewHealthMiddleware(checker *health.Checker) *HealthMiddleware {
+	return &HealthMiddleware{checker: checker}
+}
+
+// Probes answers GET and HEAD of the liveness and readiness paths ahead of the other middlewares,
+// so probes are neither throttled, rate limited nor logged as requests.
+func (m *HealthMiddleware) Probes(livePath, readyPath string) func(next http.Handler) http.Handler {
+	live := m.checker.Live()
+	ready := m.checker.Ready()
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				switch r.URL.Path {
+				case livePath:
+					live(w, r)
+					return
+				case readyPath:
+					ready(w, r)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/vlaship/book-catalog-go/internal/metrics"
+	"net/http"
+	"time"
+)
+
+// unmatchedRoute labels requests that matched no route, so unknown paths do not create new series.
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware is a middleware that observes the duration of requests.
+type MetricsMiddleware struct {
+	recorder metrics.Recorder
+}
+
+// NewMetricsMiddleware creates a new MetricsMiddleware instance.
+func NewMetricsMiddleware(recorder metrics.Recorder) *MetricsMiddleware {
+	return &MetricsMiddleware{recorder: recorder}
+}
+
+// Observe observes the duration of requests by method, chi route pattern and status.
+func (m *MetricsMiddleware) Observe() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			next.ServeHTTP(ww, r)
+
+			route := unmatchedRoute
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			m.recorder.ObserveRequest(r.Method, route, status, time.Since(start))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/vlaship/book-catalog-go/internal/app/common"
+	"github.com/vlaship/book-catalog-go/internal/apperr"
+	"github.com/vlaship/book-catalog-go/internal/config"
+	"github.com/vlaship/book-catalog-go/internal/httphandling"
+	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/ratelimit"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimitMiddleware is a middleware that limits the request rate of clients.
+type RateLimitMiddleware struct {
+	limiter ratelimit.Limiter
+	handler httphandling.HTTPErrorHandler
+	log     logger.Logger
+}
+
+// NewRateLimitMiddleware creates a new RateLimitMiddleware instance.
+func NewRateLimitMiddleware(
+	limiter ratelimit.Limiter,
+	handler httphandling.HTTPErrorHandler,
+	log logger.Logger,
+) *RateLimitMiddleware {
+	return &RateLimitMiddleware{
+		limiter: limiter,
+		handler: handler,
+		log:     log.New("RateLimitMiddleware"),
+	}
+}
+
+// Limit limits requests of the route group by the policy, clients are keyed on the user ID
+// when AuthMiddleware has run before and on the real IP otherwise.
+// Responses carry the RateLimit-* headers, and rejected requests get Retry-After and 429.
+// The limiter failing lets requests through.
+func (m *RateLimitMiddleware) Limit(group string, policy config.RateLimitPolicy) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if policy.Limit <= 0 || policy.Period <= 0 {
+			return next
+		}
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			res, err := m.limiter.Allow(ctx, fmt.Sprintf("ratelimit:%s:%s", group, clientKey(r)), policy)
+			if err != nil {
+				m.log.Wrn().Err(err).Ctx(ctx).Msg("failed to check rate limit")
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, seconds(policy.Period)))
+			h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("RateLimit-Reset", strconv.FormatInt(seconds(res.Reset), 10))
+
+			if !res.Allowed {
+				h.Set("Retry-After", strconv.FormatInt(seconds(res.RetryAfter), 10))
+				m.handler.AppErrorResponse(w, r, apperr.ErrRateLimited)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// clientKey identifies the client by the user ID or by the IP set by the RealIP middleware.
+func clientKey(r *http.Request) string {
+	if user := common.GetUser(r.Context()); user != nil {
+		return fmt.Sprintf("user:%d", user.ID)
+	}
+
+	return "ip:" + remoteIP(r)
+}
+
+// seconds rounds the duration up to whole seconds.
+func seconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
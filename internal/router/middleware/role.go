@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"github.com/vlaship/book-catalog-go/internal/app/model"
+	"github.com/vlaship/book-catalog-go/internal/app/types"
+	"github.com/vlaship/book-catalog-go/internal/apperr"
+	"github.com/vlaship/book-catalog-go/internal/httphandling"
+	"net/http"
+)
+
+// RoleMiddleware is a middleware that enforces user roles.
+type RoleMiddleware struct {
+	handler httphandling.HTTPErrorHandler
+}
+
+// NewRoleMiddleware creates a new RoleMiddleware instance.
+func NewRoleMiddleware(handler httphandling.HTTPErrorHandler) *RoleMiddleware {
+	return &RoleMiddleware{handler: handler}
+}
+
+// RequireRole allows only users whose role includes the given role.
+// It must run after AuthMiddleware, the role is taken from the stored user
+// rather than the token claim so that a downgrade takes effect immediately.
+func (m *RoleMiddleware) RequireRole(role model.UserRole) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			user, ok := r.Context().Value(types.UserContextKey).(*model.User)
+			if !ok {
+				m.handler.AppErrorResponse(w, r, apperr.ErrUnauthorized)
+				return
+			}
+
+			if !user.GetRole().Includes(role) {
+				m.handler.AppErrorResponse(w, r, apperr.ErrForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
@@ -2,14 +2,19 @@ package router
 
 import (
 	"compress/gzip"
+	"encoding/json"
 	"net/http"
 	"time"
 
 	_ "github.com/vlaship/book-catalog-go/api/docs" // swagger docs
 	"github.com/vlaship/book-catalog-go/internal/app/controller"
 	"github.com/vlaship/book-catalog-go/internal/authentication"
+	"github.com/vlaship/book-catalog-go/internal/config"
+	"github.com/vlaship/book-catalog-go/internal/health"
 	"github.com/vlaship/book-catalog-go/internal/httphandling"
 	"github.com/vlaship/book-catalog-go/internal/logger"
+	"github.com/vlaship/book-catalog-go/internal/metrics"
+	"github.com/vlaship/book-catalog-go/internal/ratelimit"
 	mw "github.com/vlaship/book-catalog-go/internal/router/middleware"
 
 	"github.com/go-chi/chi/v5"
@@ -27,11 +32,15 @@ import (
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
 func Setup(
+	cfg *config.Config,
 	controllers *controller.Controllers,
 	log logger.Logger,
 	userReader mw.UserReader,
 	authenticator authentication.Authenticator,
 	handler httphandling.HTTPErrorHandler,
+	limiter ratelimit.Limiter,
+	recorder metrics.Recorder,
+	checker *health.Checker,
 ) *chi.Mux {
 	log.Trc().Msg("setup router")
 	basePath := "/api"
@@ -43,7 +52,11 @@ func Setup(
 	l := log.New("router")
 	r.Use(middleware.GetHead)
 	r.Use(middleware.Heartbeat("/health"))
-	r.Use(middleware.RealIP)
+	r.Use(mw.NewHealthMiddleware(checker).Probes("/livez", "/readyz"))
+	r.Use(mw.RealIP(cfg.ServerProps.TrustedProxies))
+	r.Use(mw.ClientIP())
+	r.Use(mw.NewTracingMiddleware().Trace())
+	r.Use(mw.NewMetricsMiddleware(recorder).Observe())
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.RequestID)
 	r.Use(middleware.StripSlashes)
@@ -52,9 +65,6 @@ func Setup(
 	// Compression is crucial for reducing the size of responses, improving page load times, and enhancing overall user experience.
 	r.Use(middleware.NewCompressor(gzip.DefaultCompression).Handler)
 
-	// Add timeout middleware
-	r.Use(middleware.Timeout(30 * time.Second))
-
 	// Add custom logger middleware
 	r.Use(httplog.RequestLogger(l.Logger()))
 
@@ -65,22 +75,52 @@ func Setup(
 	// Add rate limiter
 	r.Use(middleware.ThrottleBacklog(100, 50, time.Second*10))
 
-	r.Use(mw.NewContentTypeMiddleware(handler).AllowContentType("application/json"))
+	// per client rate limits, the auth endpoints are limited per IP and the others per user
+	rateLimit := mw.NewRateLimitMiddleware(limiter, handler, log)
+
+	// endpoints decode JSON, the import is registered outside of it as it accepts CSV and NDJSON only
+	jsonOnly := mw.NewContentTypeMiddleware(handler).AllowContentType("application/json")
+
+	// Add timeout middleware, streaming endpoints run without it
+	timeout := middleware.Timeout(30 * time.Second)
 
 	r.Route(basePath, func(baseRouter chi.Router) {
 		baseRouter.Group(func(authRouter chi.Router) {
 			// auth validation
 			authRouter.Use(mw.NewAuthMiddleware(authenticator, userReader, handler, log).Validation())
+			authRouter.Use(rateLimit.Limit("api", cfg.RateLimit.API))
 
 			// endpoints
-			controllers.AuthorController.RegisterRoutes(authRouter)
-			controllers.BookController.RegisterRoutes(authRouter)
-			controllers.UserController.RegisterRoutes(authRouter)
+			authRouter.Group(func(timedRouter chi.Router) {
+				timedRouter.Use(jsonOnly)
+				timedRouter.Use(timeout)
+
+				controllers.AuthorController.RegisterRoutes(timedRouter)
+				controllers.BookController.RegisterRoutes(timedRouter)
+				controllers.UserController.RegisterRoutes(timedRouter)
+				controllers.SearchController.RegisterRoutes(timedRouter)
+				controllers.AuditController.RegisterRoutes(timedRouter)
+				controllers.TrashController.RegisterRoutes(timedRouter)
+				controllers.AdminUserController.RegisterRoutes(timedRouter)
+			})
+
+			// streaming endpoints
+			controllers.ImportController.RegisterRoutes(authRouter)
+			controllers.ExportController.RegisterRoutes(authRouter.With(jsonOnly))
 		})
 		// register auth
-		controllers.AuthController.RegisterRoutes(baseRouter)
+		baseRouter.Group(func(timedRouter chi.Router) {
+			timedRouter.Use(jsonOnly)
+			timedRouter.Use(rateLimit.Limit("auth", cfg.RateLimit.Auth))
+			timedRouter.Use(timeout)
+
+			controllers.AuthController.RegisterRoutes(timedRouter)
+		})
 	})
 
+	// publish token verification keys
+	r.Get("/.well-known/jwks.json", jwks(authenticator))
+
 	// register swagger
 	r.Get("/swagger-ui*", sw.WrapHandler)
 
@@ -95,6 +135,21 @@ func Setup(
 	return r
 }
 
+// jwks serves the public keys other services use to verify access tokens.
+func jwks(authenticator authentication.Authenticator) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		resp, err := json.Marshal(authenticator.JWKS())
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		_, _ = w.Write(resp)
+	}
+}
+
 func printRoutes(routes chi.Routes, log logger.Logger) {
 	log.Inf().Msg("routes")
 	_ = chi.Walk(
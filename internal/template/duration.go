@@ -0,0 +1,39 @@
+package template
+
+import (
+	"fmt"
+	"time"
+)
+
+// durationUnits are the singular and plural hour and minute units of a locale
+type durationUnits struct {
+	hour, hours, minute, minutes string
+}
+
+var localeDurationUnits = map[string]durationUnits{
+	"en": {"hour", "hours", "minute", "minutes"},
+	"es": {"hora", "horas", "minuto", "minutos"},
+}
+
+// durationFunc returns the template function that spells a duration in whole hours, or in minutes
+// when it is not a whole number of hours, units of unknown locales fall back to the default locale
+func durationFunc(locale string) func(d time.Duration) string {
+	units, ok := localeDurationUnits[locale]
+	if !ok {
+		units = localeDurationUnits[DefaultLocale]
+	}
+
+	return func(d time.Duration) string {
+		if d >= time.Hour && d%time.Hour == 0 {
+			return plural(int64(d/time.Hour), units.hour, units.hours)
+		}
+		return plural(max(int64(d.Round(time.Minute)/time.Minute), 1), units.minute, units.minutes)
+	}
+}
+
+func plural(n int64, one, many string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, one)
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
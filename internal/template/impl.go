@@ -2,46 +2,124 @@ package template
 
 import (
 	"embed"
-	"html/template"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	texttemplate "text/template"
+
+	"golang.org/x/text/language"
 )
 
 const (
-	activationFilename = "templates/activation_email.html"
-	resetFilename      = "templates/reset_password_email.html"
+	// DefaultLocale is the locale of users without a preferred language
+	DefaultLocale = "en"
+
+	templatesDir = "templates"
+	activation   = "activation"
+	reset        = "reset_password"
+	changeEmail  = "change_email"
+	userLocked   = "user_locked"
 )
 
-//go:embed templates/*.html
+//go:embed templates
 var templateFS embed.FS
 
-// TemplatesImpl is a template
+// TemplatesImpl is a template, it holds the mails of every locale found in the templates directory
 type TemplatesImpl struct {
-	activation *template.Template
-	reset      *template.Template
+	matcher language.Matcher
+	locales []string
+	mails   map[string]map[string]*Mail
 }
 
 // NewTemplatesImpl creates new template
 func NewTemplatesImpl() (Templates, error) {
-	act, err := template.ParseFS(templateFS, activationFilename)
+	dirs, err := fs.ReadDir(templateFS, templatesDir)
 	if err != nil {
 		return nil, err
 	}
-	rst, err := template.ParseFS(templateFS, resetFilename)
-	if err != nil {
-		return nil, err
+
+	// the default locale goes first, the matcher falls back to it
+	locales := []string{DefaultLocale}
+	for _, dir := range dirs {
+		if dir.IsDir() && dir.Name() != DefaultLocale {
+			locales = append(locales, dir.Name())
+		}
+	}
+
+	tags := make([]language.Tag, len(locales))
+	mails := make(map[string]map[string]*Mail, len(locales))
+	for i, locale := range locales {
+		if tags[i], err = language.Parse(locale); err != nil {
+			return nil, fmt.Errorf("invalid template locale [%s]: %w", locale, err)
+		}
+
+		mails[locale] = make(map[string]*Mail, 4)
+		for _, name := range []string{activation, reset, changeEmail, userLocked} {
+			if mails[locale][name], err = parseMail(locale, name); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return &TemplatesImpl{
-		activation: act,
-		reset:      rst,
+		matcher: language.NewMatcher(tags),
+		locales: locales,
+		mails:   mails,
 	}, nil
 }
 
+func parseMail(locale, name string) (*Mail, error) {
+	base := fmt.Sprintf("%s/%s/%s", templatesDir, locale, name)
+
+	duration := durationFunc(locale)
+	file := name + ".html"
+	html, err := htmltemplate.New(file).Funcs(htmltemplate.FuncMap{"duration": duration}).ParseFS(templateFS, base+".html")
+	if err != nil {
+		return nil, err
+	}
+	file = name + ".txt"
+	text, err := texttemplate.New(file).Funcs(texttemplate.FuncMap{"duration": duration}).ParseFS(templateFS, base+".txt")
+	if err != nil {
+		return nil, err
+	}
+	if text.Lookup(subjectTemplate) == nil {
+		return nil, fmt.Errorf("template [%s.txt] does not define %q", base, subjectTemplate)
+	}
+
+	return &Mail{Locale: locale, html: html, text: text}, nil
+}
+
 // Activation returns activation template
-func (p *TemplatesImpl) Activation() *template.Template {
-	return p.activation
+func (p *TemplatesImpl) Activation(lang string) *Mail {
+	return p.mails[p.locale(lang)][activation]
 }
 
 // ResetPassword returns reset password template
-func (p *TemplatesImpl) ResetPassword() *template.Template {
-	return p.reset
+func (p *TemplatesImpl) ResetPassword(lang string) *Mail {
+	return p.mails[p.locale(lang)][reset]
+}
+
+// ChangeEmail returns change email template
+func (p *TemplatesImpl) ChangeEmail(lang string) *Mail {
+	return p.mails[p.locale(lang)][changeEmail]
+}
+
+// UserLocked returns user locked template
+func (p *TemplatesImpl) UserLocked(lang string) *Mail {
+	return p.mails[p.locale(lang)][userLocked]
+}
+
+// locale returns the supported locale that best matches lang
+func (p *TemplatesImpl) locale(lang string) string {
+	tags, _, err := language.ParseAcceptLanguage(lang)
+	if err != nil || len(tags) == 0 {
+		return DefaultLocale
+	}
+
+	_, idx, confidence := p.matcher.Match(tags...)
+	if confidence == language.No {
+		return DefaultLocale
+	}
+
+	return p.locales[idx]
 }
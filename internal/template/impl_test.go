@@ -0,0 +1,86 @@
+package template
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplates_Locale(t *testing.T) {
+	templates, err := NewTemplatesImpl()
+	require.NoError(t, err)
+
+	tests := []struct {
+		lang     string
+		expected string
+	}{
+		{"", DefaultLocale},
+		{"en", "en"},
+		{"en-GB", "en"},
+		{"es", "es"},
+		{"es-MX", "es"},
+		{"fr-CH, es;q=0.9, en;q=0.8", "es"},
+		{"ja", DefaultLocale},
+		{"not a language", DefaultLocale},
+	}
+
+	for _, test := range tests {
+		t.Run(test.lang, func(t *testing.T) {
+			require.Equal(t, test.expected, templates.Activation(test.lang).Locale)
+			require.Equal(t, test.expected, templates.ResetPassword(test.lang).Locale)
+			require.Equal(t, test.expected, templates.ChangeEmail(test.lang).Locale)
+			require.Equal(t, test.expected, templates.UserLocked(test.lang).Locale)
+		})
+	}
+}
+
+func TestMail_Render(t *testing.T) {
+	// given
+	templates, err := NewTemplatesImpl()
+	require.NoError(t, err)
+	data := struct {
+		URL      string
+		OTP      string
+		Username string
+		Expires  time.Duration
+	}{
+		URL:      "localhost:3000/auth/activate",
+		OTP:      "123",
+		Username: "john@example.com",
+		Expires:  48 * time.Hour,
+	}
+
+	// when
+	content, err := templates.Activation("es").Render(data)
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, "Active su cuenta", content.Subject)
+	require.Contains(t, content.Text, "https://localhost:3000/auth/activate?otp=123&username=john@example.com")
+	require.NotContains(t, content.Text, "subject")
+	require.Contains(t, content.HTML, "otp=123&username=john%40example.com")
+	require.Contains(t, content.Text, "caducará en 48 horas")
+}
+
+func TestDurationFunc(t *testing.T) {
+	tests := []struct {
+		locale   string
+		d        time.Duration
+		expected string
+	}{
+		{"en", time.Hour, "1 hour"},
+		{"en", 48 * time.Hour, "48 hours"},
+		{"en", 90 * time.Minute, "90 minutes"},
+		{"en", time.Minute, "1 minute"},
+		{"es", time.Hour, "1 hora"},
+		{"es", 30 * time.Minute, "30 minutos"},
+		{"fr", 2 * time.Hour, "2 hours"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.locale+"/"+test.expected, func(t *testing.T) {
+			require.Equal(t, test.expected, durationFunc(test.locale)(test.d))
+		})
+	}
+}
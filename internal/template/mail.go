@@ -0,0 +1,49 @@
+package template
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/vlaship/book-catalog-go/internal/apperr"
+)
+
+// subjectTemplate is the name of the template defined by text templates for the subject line
+const subjectTemplate = "subject"
+
+// Mail is a pair of HTML and plain text templates of a mail in a locale,
+// the text template also defines the subject
+type Mail struct {
+	Locale string
+	html   *htmltemplate.Template
+	text   *texttemplate.Template
+}
+
+// Content is a rendered mail
+type Content struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Render renders the subject and both bodies of the mail
+func (m *Mail) Render(data any) (*Content, error) {
+	var subject, text, html bytes.Buffer
+
+	if err := m.text.ExecuteTemplate(&subject, subjectTemplate, data); err != nil {
+		return nil, apperr.ErrExecuteTemplate.WithFunc(apperr.WithDetail(err.Error()))
+	}
+	if err := m.text.Execute(&text, data); err != nil {
+		return nil, apperr.ErrExecuteTemplate.WithFunc(apperr.WithDetail(err.Error()))
+	}
+	if err := m.html.Execute(&html, data); err != nil {
+		return nil, apperr.ErrExecuteTemplate.WithFunc(apperr.WithDetail(err.Error()))
+	}
+
+	return &Content{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
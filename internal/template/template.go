@@ -1,13 +1,12 @@
 package template
 
-import (
-	"html/template"
-)
-
-// Templates is an interface for template
+// Templates is an interface for template, mails are picked by the preferred language of the user,
+// lang is a language tag or an Accept-Language value, unknown languages fall back to the default locale
 //
 //go:generate mockgen -destination=../../test/mock/template/mock-template.go -package=mock . Template
 type Templates interface {
-	Activation() *template.Template
-	ResetPassword() *template.Template
+	Activation(lang string) *Mail
+	ResetPassword(lang string) *Mail
+	ChangeEmail(lang string) *Mail
+	UserLocked(lang string) *Mail
 }
@@ -0,0 +1,87 @@
+package totp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/vlaship/book-catalog-go/internal/config"
+)
+
+const keySize = 32
+
+// Cipher encrypts the secrets stored in the database
+//
+//go:generate mockgen -destination=../../test/mock/totp/mock-cipher.go -package=mock . Cipher
+type Cipher interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// CipherImpl is an AES-256-GCM cipher, the nonce is prepended to the ciphertext
+type CipherImpl struct {
+	aead cipher.AEAD
+}
+
+// NewCipher creates a new cipher with the configured key,
+// the key is derived from the JWT secret when it is not configured
+func NewCipher(cfg *config.Config) (Cipher, error) {
+	key, err := encryptionKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CipherImpl{aead: aead}, nil
+}
+
+func encryptionKey(cfg *config.Config) ([]byte, error) {
+	if cfg.TOTP.EncryptionKey == "" {
+		if len(cfg.JWT.Secret) == 0 {
+			return nil, errors.New("TOTP_ENCRYPTION_KEY is required when JWT_SECRET is not set")
+		}
+		sum := sha256.Sum256(append([]byte("totp:"), cfg.JWT.Secret...))
+		return sum[:], nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(cfg.TOTP.EncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TOTP_ENCRYPTION_KEY: %w", err)
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("invalid TOTP_ENCRYPTION_KEY: want %d bytes, got %d", keySize, len(key))
+	}
+
+	return key, nil
+}
+
+// Encrypt encrypts plaintext
+func (c *CipherImpl) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decrypt decrypts ciphertext produced by Encrypt
+func (c *CipherImpl) Decrypt(ciphertext []byte) ([]byte, error) {
+	size := c.aead.NonceSize()
+	if len(ciphertext) < size {
+		return nil, errors.New("ciphertext is too short")
+	}
+
+	return c.aead.Open(nil, ciphertext[:size], ciphertext[size:], nil)
+}
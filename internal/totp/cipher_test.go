@@ -0,0 +1,75 @@
+package totp
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vlaship/book-catalog-go/internal/config"
+)
+
+func newConfig(key, jwtSecret string) *config.Config {
+	cfg := &config.Config{}
+	cfg.TOTP.EncryptionKey = key
+	cfg.JWT.Secret = []byte(jwtSecret)
+	return cfg
+}
+
+func TestCipher(t *testing.T) {
+	// given
+	key := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", keySize)))
+	c, err := NewCipher(newConfig(key, ""))
+	require.NoError(t, err)
+
+	// when
+	sealed, err := c.Encrypt([]byte("secret"))
+	require.NoError(t, err)
+	opened, err := c.Decrypt(sealed)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(opened))
+
+	again, err := c.Encrypt([]byte("secret"))
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again, "nonce must be random")
+
+	sealed[len(sealed)-1] ^= 1
+	_, err = c.Decrypt(sealed)
+	require.Error(t, err, "tampered ciphertext")
+	_, err = c.Decrypt([]byte("short"))
+	require.Error(t, err)
+}
+
+func TestCipher_DerivedKey(t *testing.T) {
+	// given
+	c, err := NewCipher(newConfig("", "jwt-secret"))
+	require.NoError(t, err)
+	other, err := NewCipher(newConfig("", "other-secret"))
+	require.NoError(t, err)
+
+	// when
+	sealed, err := c.Encrypt([]byte("secret"))
+	require.NoError(t, err)
+
+	// then
+	_, err = other.Decrypt(sealed)
+	require.Error(t, err)
+}
+
+func TestNewCipher_InvalidKey(t *testing.T) {
+	tests := map[string]*config.Config{
+		"no key":     newConfig("", ""),
+		"not base64": newConfig("not base64!", ""),
+		"short key":  newConfig(base64.StdEncoding.EncodeToString([]byte("short")), ""),
+	}
+
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewCipher(cfg)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the SHA1 secret of the RFC 6238 test vectors
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// RFC 6238 appendix B, truncated to 6 digits
+	tests := []struct {
+		unix     int64
+		expected string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			code, err := Code(rfcSecret, Step(time.Unix(test.unix, 0)))
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, code)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	// given
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+	code, err := Code(secret, Step(now))
+	require.NoError(t, err)
+
+	// when
+	step, ok := Validate(secret, code, now.Add(Period), 1)
+
+	// then
+	assert.True(t, ok)
+	assert.Equal(t, Step(now), step)
+
+	_, ok = Validate(secret, code, now.Add(2*Period), 1)
+	assert.False(t, ok, "code out of the skew")
+	_, ok = Validate(secret, "12345", now, 1)
+	assert.False(t, ok, "short code")
+	_, ok = Validate("not base32!", code, now, 1)
+	assert.False(t, ok, "invalid secret")
+}
+
+func TestURI(t *testing.T) {
+	// when
+	uri := URI("Book Catalog", "john@example.com", "SECRET")
+
+	// then
+	u, err := url.Parse(uri)
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/Book Catalog:john@example.com", u.Path)
+	assert.Equal(t, "SECRET", u.Query().Get("secret"))
+	assert.Equal(t, "Book Catalog", u.Query().Get("issuer"))
+	assert.Equal(t, "6", u.Query().Get("digits"))
+	assert.Equal(t, "30", u.Query().Get("period"))
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	// when
+	codes, err := GenerateRecoveryCodes(10)
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		assert.Regexp(t, `^[a-z2-7]{8}-[a-z2-7]{8}$`, code)
+		assert.False(t, seen[code], "duplicate code")
+		seen[code] = true
+	}
+	assert.Equal(t, HashRecoveryCode(codes[0]), HashRecoveryCode(" "+codes[0][:8]+codes[0][9:]+" "))
+	assert.NotEqual(t, HashRecoveryCode(codes[0]), HashRecoveryCode(codes[1]))
+}
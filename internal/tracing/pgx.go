@@ -0,0 +1,50 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PgxTracer is a pgx query tracer that runs every query in a client span.
+type PgxTracer struct{}
+
+// TraceQueryStart starts the span of the query.
+func (PgxTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx, _ = Start(ctx, queryName(data.SQL),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBQueryText(data.SQL),
+		),
+	)
+	return ctx
+}
+
+// TraceQueryEnd ends the span of the query, errors other than no rows mark it failed.
+func (PgxTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	if data.Err != nil && !errors.Is(data.Err, pgx.ErrNoRows) {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+		return
+	}
+	span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+}
+
+// queryName names the span by the SQL command, the first word of the query.
+func queryName(sql string) string {
+	words := strings.Fields(sql)
+	if len(words) == 0 {
+		return "db.query"
+	}
+	return "db." + strings.ToLower(words[0])
+}
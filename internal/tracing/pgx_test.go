@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	return recorder
+}
+
+func TestQueryName(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected string
+	}{
+		{"SELECT id FROM catalog.books", "db.select"},
+		{"\n\tINSERT INTO catalog.books", "db.insert"},
+		{"with x as (select 1) select * from x", "db.with"},
+		{"", "db.query"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			require.Equal(t, test.expected, queryName(test.sql))
+		})
+	}
+}
+
+func TestPgxTracer(t *testing.T) {
+	// given
+	recorder := newTestRecorder(t)
+	tracer := PgxTracer{}
+
+	// when
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "UPDATE catalog.books SET title = $1"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("UPDATE 1")})
+
+	// then
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, "db.update", spans[0].Name())
+	require.Equal(t, codes.Unset, spans[0].Status().Code)
+}
+
+func TestPgxTracer_Error(t *testing.T) {
+	// given
+	recorder := newTestRecorder(t)
+	tracer := PgxTracer{}
+
+	// when
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("connection refused")})
+
+	// then
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, codes.Error, spans[0].Status().Code)
+}
+
+func TestPgxTracer_NoRows(t *testing.T) {
+	// given
+	recorder := newTestRecorder(t)
+	tracer := PgxTracer{}
+
+	// when
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: pgx.ErrNoRows})
+
+	// then
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, codes.Unset, spans[0].Status().Code)
+}
@@ -0,0 +1,97 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/vlaship/book-catalog-go/internal/config"
+)
+
+// Exporter values of TRACING_EXPORTER.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+const (
+	serviceName = "book-catalog"
+	tracerName  = "github.com/vlaship/book-catalog-go"
+)
+
+// Provider is a tracer provider that flushes the spans on shutdown.
+type Provider interface {
+	trace.TracerProvider
+	Shutdown(ctx context.Context) error
+}
+
+// nopProvider is a provider that records nothing.
+type nopProvider struct {
+	noop.TracerProvider
+}
+
+// Shutdown does nothing.
+func (nopProvider) Shutdown(context.Context) error {
+	return nil
+}
+
+// New creates the tracer provider of the configured exporter and installs it globally
+// together with the W3C trace context propagator.
+// The OTLP exporter is configured by the standard OTEL_EXPORTER_OTLP_* variables.
+func New(ctx context.Context, cfg *config.Config) (Provider, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Tracing.Exporter {
+	case "", ExporterNone:
+		provider := nopProvider{}
+		otel.SetTracerProvider(provider)
+		return provider, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unsupported tracing exporter: %s", cfg.Tracing.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(serviceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.Tracing.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider, nil
+}
+
+// Start starts a span as a child of the span in ctx.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, opts...)
+}
@@ -3,6 +3,7 @@ package validation
 import (
 	"github.com/go-playground/validator/v10"
 	"github.com/shopspring/decimal"
+	"github.com/vlaship/book-catalog-go/internal/isbn"
 	"reflect"
 )
 
@@ -16,6 +17,11 @@ func (v *ValidatorImpl) Struct(a any) error {
 	return v.valid.Struct(a)
 }
 
+// StructExcept validates a struct except the given fields
+func (v *ValidatorImpl) StructExcept(s any, fields ...string) error {
+	return v.valid.StructExcept(s, fields...)
+}
+
 func New() Validator {
 	v := &ValidatorImpl{
 		valid: validator.New(),
@@ -23,6 +29,7 @@ func New() Validator {
 	_ = v.decimalMin()
 	_ = v.decimalMax()
 	_ = v.decimalPositive()
+	_ = v.isbn()
 	return v
 }
 
@@ -93,3 +100,14 @@ func (v *ValidatorImpl) decimalPositive() error {
 		return value.GreaterThan(decimal.Zero)
 	})
 }
+
+// isbn replaces the built-in rule, ISBN-10 and ISBN-13 are accepted with hyphens and spaces
+func (v *ValidatorImpl) isbn() error {
+	return v.valid.RegisterValidation("isbn", func(fl validator.FieldLevel) bool {
+		data, ok := fl.Field().Interface().(string)
+		if !ok {
+			return false
+		}
+		return isbn.Valid(data)
+	})
+}
@@ -61,3 +61,37 @@ func TestDecimalPositiveValidationSuccess(t *testing.T) {
 	}{Decimal: decimal.NewFromFloat(1.0)})
 	assert.NoError(t, err)
 }
+
+func TestStructExceptSkipsFields(t *testing.T) {
+	validator := New()
+	err := validator.StructExcept(struct {
+		ID   int64  `validate:"required"`
+		Name string `validate:"required"`
+	}{Name: "name"}, "ID")
+	assert.NoError(t, err)
+}
+
+func TestStructExceptValidatesOtherFields(t *testing.T) {
+	validator := New()
+	err := validator.StructExcept(struct {
+		ID   int64  `validate:"required"`
+		Name string `validate:"required"`
+	}{}, "ID")
+	assert.Error(t, err)
+}
+
+func TestISBNValidation(t *testing.T) {
+	validator := New()
+	err := validator.Struct(struct {
+		ISBN string `validate:"isbn"`
+	}{ISBN: "978-0-13-419044-1"})
+	assert.Error(t, err)
+}
+
+func TestISBNValidationSuccess(t *testing.T) {
+	validator := New()
+	err := validator.Struct(struct {
+		ISBN string `validate:"isbn"`
+	}{ISBN: "978-0-13-419044-0"})
+	assert.NoError(t, err)
+}
@@ -5,4 +5,5 @@ package validation
 //go:generate mockgen -destination=../../test/mock/validation/mock-validator.go -package=mock . Validator
 type Validator interface {
 	Struct(any) error
+	StructExcept(s any, fields ...string) error
 }